@@ -58,9 +58,8 @@ func buildReportCommand() *cobra.Command {
 			findings := jnode.NewArrayNode()
 			for _, assessment := range assessments {
 				if assessment.Failed {
-					exit.Code = 2
 					a := assessment
-					exit.AddFunc(func() {
+					exit.Fail(2, func() {
 						log.Errorf("{warning:%s} has {danger:%d %s findings}",
 							a.Title, a.FailedCount, a.FailedSeverity)
 					})
@@ -19,6 +19,7 @@ import (
 	cfnpythonlint "github.com/soluble-ai/soluble-cli/pkg/tools/cfn-python-lint"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/cfnnag"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/checkov"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/opa"
 	"github.com/spf13/cobra"
 )
 
@@ -38,6 +39,9 @@ Use the sub-commands to explicitly choose a scanner to use.`
 		tools.CreateCommand(&checkov.Tool{
 			Framework: "cloudformation",
 		}),
+		tools.CreateCommand(&opa.Tool{
+			Framework: "cloudformation",
+		}),
 	)
 	return c
 }
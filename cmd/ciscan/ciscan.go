@@ -0,0 +1,36 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ciscan
+
+import (
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/ciscan"
+	"github.com/spf13/cobra"
+)
+
+func Command() *cobra.Command {
+	c := tools.CreateCommand(&ciscan.Tool{})
+	c.Long = `Scan CI pipelines for security problems
+
+Checks GitHub Actions workflows, GitLab CI, CircleCI and Buildkite
+pipelines, and Jenkinsfiles for:
+
+  - third-party actions, orbs, and plugins that aren't pinned to a version
+  - pull_request_target workflows that check out the pull request
+  - secrets that are printed or passed to unpinned third-party actions
+  - workflows that grant write-all permissions
+  - scripts that are downloaded and piped to a shell`
+	return c
+}
@@ -16,12 +16,16 @@ package config
 
 import (
 	"fmt"
+	"path/filepath"
 	"sort"
 
 	"github.com/soluble-ai/go-jnode"
 	"github.com/soluble-ai/soluble-cli/pkg/config"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/options"
+	"github.com/soluble-ai/soluble-cli/pkg/repotree"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/spf13/cobra"
 )
 
@@ -36,7 +40,8 @@ func Command() *cobra.Command {
 		setProfileCmd(),
 		listProfilesCmd(),
 		updateProfileCmd(),
-		migrateCmd())
+		migrateCmd(),
+		checkRepoCmd())
 	return c
 }
 
@@ -217,3 +222,99 @@ func migrateCmd() *cobra.Command {
 		},
 	}
 }
+
+func checkRepoCmd() *cobra.Command {
+	opts := &options.PrintOpts{
+		Path:    []string{"problems"},
+		Columns: []string{"line", "key", "message"},
+	}
+	var (
+		dir     string
+		explain string
+	)
+	c := &cobra.Command{
+		Use:   "check-repo",
+		Short: "Check the tool configuration file of a repository",
+		Long: `Check the tool configuration file (.lacework/config.yml) of a repository
+for unknown keys, values of the wrong type, invalid severities, and ignore
+patterns that can't be used.
+
+For example, rules can be skipped, ignored in some paths, or given a
+different severity for each tool with:
+
+tools:
+  checkov:
+    skip: [CKV_AWS_18]
+    ignore-rules:
+      - paths: ["test/**"]
+        rules: [CKV_AWS_19]
+    severities:
+      CKV_AWS_21: high
+
+With --explain, also show the patterns in the config file that match a file.`,
+		Example: `# check the config file of the repository in the current directory
+soluble config check-repo
+
+# show why a file is or isn't scanned
+soluble config check-repo --explain test/fixtures/main.tf`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root, err := repotree.FindRepoRoot(dir)
+			if err != nil {
+				return err
+			}
+			if root == "" {
+				root, err = filepath.Abs(dir)
+				if err != nil {
+					return err
+				}
+			}
+			path, deprecated := tools.FindConfigFile(root)
+			if deprecated {
+				log.Warnf("{info:%s} is {warning:deprecated}.  Use {info:%s} instead.",
+					path, filepath.Join(root, ".lacework", "config.yml"))
+			}
+			if !util.FileExists(path) {
+				log.Infof("The repository {info:%s} has no config file", root)
+				return nil
+			}
+			problems, err := tools.CheckConfigFile(path)
+			if err != nil {
+				return err
+			}
+			opts.PrintResult(problems.ToJNode(path))
+			if explain != "" {
+				file, err := filepath.Abs(explain)
+				if err != nil {
+					return err
+				}
+				file, err = filepath.Rel(root, file)
+				if err != nil {
+					return err
+				}
+				e, err := tools.ExplainIgnored(path, file)
+				if err != nil {
+					return err
+				}
+				opts.Path = []string{"matches"}
+				opts.Columns = []string{"line", "key", "pattern", "effect", "rules"}
+				opts.PrintResult(e.ToJNode())
+				if e.Ignored {
+					log.Infof("{primary:%s} is ignored", e.Path)
+				} else {
+					log.Infof("{primary:%s} is not ignored", e.Path)
+				}
+			}
+			if len(problems) > 0 {
+				return fmt.Errorf("%s has %d problems", path, len(problems))
+			}
+			log.Infof("{info:%s} is valid", path)
+			return nil
+		},
+	}
+	opts.Register(c)
+	flags := c.Flags()
+	flags.StringVarP(&dir, "directory", "d", ".", "Check the config file of the repository containing `dir`")
+	flags.StringVar(&explain, "explain", "", "Show the patterns in the config file that match `file`")
+	return c
+}
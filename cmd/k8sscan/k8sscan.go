@@ -17,6 +17,7 @@ package k8sscan
 import (
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/checkov"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/opa"
 	"github.com/spf13/cobra"
 )
 
@@ -27,8 +28,14 @@ func Command() *cobra.Command {
 	c.Use = "kubernetes-scan"
 	c.Short = "Scan kubernetes manifests"
 	c.Aliases = []string{"k8s-scan"}
-	c.AddCommand(tools.CreateCommand(&checkov.Tool{
-		Framework: "kubernetes",
-	}))
+	c.AddCommand(
+		tools.CreateCommand(&checkov.Tool{
+			Framework: "kubernetes",
+		}),
+		tools.CreateCommand(&opa.Tool{
+			Framework: "kubernetes",
+		}),
+		tools.CreateCommand(&checkov.Kustomize{}),
+	)
 	return c
 }
@@ -1,7 +1,11 @@
 package policy
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"runtime"
+	"time"
 
 	"github.com/soluble-ai/soluble-cli/pkg/api"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
@@ -21,6 +25,9 @@ func Command() *cobra.Command {
 		vetCommand(),
 		uploadCommand(),
 		testCommand(),
+		newCommand(),
+		downloadCommand(),
+		diffCommand(),
 	)
 	return c
 }
@@ -110,27 +117,215 @@ func uploadCommand() *cobra.Command {
 }
 
 func testCommand() *cobra.Command {
-	var dir string
+	var (
+		dir         string
+		concurrency int
+	)
+	opts := options.PrintOpts{
+		Path:                []string{"results"},
+		Columns:             policy.TestResultColumns,
+		DefaultOutputFormat: "none",
+		JUnitColumns:        policy.TestResultJUnitColumns,
+	}
 	c := &cobra.Command{
 		Use:   "test",
 		Short: "Test custom policy",
+		Example: `# Print the test results in JUnit format for CI
+... policy test -d . --format junit > policy-tests.xml`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if concurrency < 1 {
+				return fmt.Errorf("--concurrency must be at least 1")
+			}
 			m, ruleType, rule, target, err := policy.DetectPolicy(dir)
 			if err != nil {
 				return err
 			}
+			m.Concurrency = concurrency
+			start := time.Now()
 			switch {
 			case rule != nil && target != "":
-				return m.TestRuleTarget(rule, target)
+				err = m.TestRuleTarget(rule, target)
 			case rule != nil:
-				return m.TestRule(rule)
+				err = m.TestRule(rule)
 			case ruleType != nil:
-				return m.TestRuleType(ruleType)
+				err = m.TestRuleType(ruleType)
 			default:
-				return m.TestRules()
+				err = m.TestRules()
+			}
+			log.Infof("Ran {primary:%d} tests with {primary:%d} failures in {secondary:%s}",
+				len(m.TestResults), m.TestResults.Failures(), time.Since(start).Truncate(time.Millisecond))
+			opts.PrintResult(m.TestResults.ToJNode(m.Dir))
+			return err
+		},
+	}
+	opts.Register(c)
+	flags := c.Flags()
+	flags.StringVarP(&dir, "directory", "d", "", "Run tests in `dir`")
+	flags.IntVar(&concurrency, "concurrency", runtime.NumCPU(), "Test up to `N` rule targets at the same time")
+	return c
+}
+
+func newCommand() *cobra.Command {
+	var (
+		dir      string
+		typeName string
+		target   string
+		name     string
+	)
+	c := &cobra.Command{
+		Use:   "new",
+		Short: "Create a new custom policy rule from a template",
+		Long: `Create a new custom policy rule from a template.
+
+The rule is created in policies/<type>/<name> under the directory, with a
+metadata.yaml file, a rule for the target, and sample pass and fail tests.`,
+		Example: `# Create a checkov rule for terraform
+... policy new --type checkov --target terraform --name team_tag`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ruleType := policy.GetRuleType(typeName)
+			if ruleType == nil {
+				return fmt.Errorf("unsupported rule type %s", typeName)
+			}
+			if target == "" {
+				targets := policy.GetTemplateTargets(ruleType)
+				if len(targets) != 1 {
+					return fmt.Errorf("--target is required for %s rules", typeName)
+				}
+				target = string(targets[0])
+			}
+			if filepath.Base(dir) == "policies" {
+				dir = filepath.Dir(dir)
+			}
+			m := policy.NewManager(dir)
+			rule, err := m.CreateRule(ruleType, policy.Target(target), name)
+			if err != nil {
+				return err
+			}
+			log.Infof("Created {primary:%s} in {info:%s}", rule.ID, filepath.Join(rule.Path, target))
+			log.Infof("Test it with {primary:policy test -d %s}", filepath.Join(rule.Path, target))
+			return nil
+		},
+	}
+	flags := c.Flags()
+	flags.StringVarP(&dir, "directory", "d", ".", "Create the rule in `dir`, which contains the policies directory")
+	flags.StringVar(&typeName, "type", "", "The rule `type`, one of checkov, opa, semgrep or tfsec")
+	flags.StringVar(&target, "target", "", "The `target` of the rule, e.g. terraform or kubernetes")
+	flags.StringVar(&name, "name", "", "The `name` of the rule, e.g. team_tag")
+	_ = c.MarkFlagRequired("type")
+	_ = c.MarkFlagRequired("name")
+	return c
+}
+
+func downloadPreparedRules(client *options.ClientOpts) (string, []*policy.PreparedRule, error) {
+	if err := client.RequireAPIToken(); err != nil {
+		return "", nil, err
+	}
+	dir, err := os.MkdirTemp("", "policies*")
+	if err != nil {
+		return "", nil, err
+	}
+	if err := policy.DownloadPreparedRules(client.GetAPIClient(), dir); err != nil {
+		os.RemoveAll(dir)
+		return "", nil, err
+	}
+	rules, err := policy.FindPreparedRules(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", nil, err
+	}
+	return dir, rules, nil
+}
+
+func downloadCommand() *cobra.Command {
+	var (
+		dir    string
+		client options.ClientOpts
+		force  bool
+	)
+	c := &cobra.Command{
+		Use:   "download",
+		Short: "Download custom policies",
+		Long: `Download the organization's custom policies into the policies directory.
+
+Rule files that have local changes are not overwritten unless --force is used.
+The server does not keep the tests of rules, and only keeps the title of
+the rule's metadata.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tmp, rules, err := downloadPreparedRules(&client)
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(tmp)
+			m := policy.NewManager(dir)
+			if err := m.UnprepareRules(rules, force); err != nil {
+				return err
+			}
+			log.Infof("Downloaded {primary:%d} custom rules", len(rules))
+			return nil
+		},
+	}
+	client.Register(c)
+	flags := c.Flags()
+	flags.StringVarP(&dir, "directory", "d", "", "Write custom policies to `dir`")
+	flags.BoolVar(&force, "force", false, "Overwrite rules that have local changes")
+	_ = c.MarkFlagRequired("directory")
+	return c
+}
+
+func diffCommand() *cobra.Command {
+	var (
+		dir      string
+		showDiff bool
+	)
+	opts := options.PrintClientOpts{
+		PrintOpts: options.PrintOpts{
+			Path:    []string{"diffs"},
+			Columns: []string{"ruleId", "ruleType", "target", "status"},
+		},
+	}
+	c := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare custom policies with the server",
+		Long: `Compare custom policies with the organization's custom policies on the server.
+
+A rule is "added" if it would be added by policy upload, "removed" if it's
+only on the server, or "changed".`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m := policy.NewManager(dir)
+			if err := m.LoadAllRules(); err != nil {
+				return err
+			}
+			tmp, rules, err := downloadPreparedRules(&opts.ClientOpts)
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(tmp)
+			diffs, err := m.DiffRules(rules)
+			if err != nil {
+				return err
+			}
+			if len(diffs) == 0 {
+				log.Infof("The custom policies in {primary:%s} are the same as the server's", dir)
+				return nil
 			}
+			opts.PrintResult(diffs.ToJNode())
+			if showDiff {
+				w := opts.GetOutputWriter()
+				for _, d := range diffs {
+					fmt.Fprintln(w)
+					fmt.Fprint(w, d.Diff)
+				}
+			}
+			return nil
 		},
 	}
-	c.Flags().StringVarP(&dir, "directory", "d", "", "Run tests in `dir`")
+	opts.Register(c)
+	flags := c.Flags()
+	flags.StringVarP(&dir, "directory", "d", "", "Compare the custom policies in `dir`")
+	flags.BoolVar(&showDiff, "show-diff", false, "Print the differences of each rule")
+	_ = c.MarkFlagRequired("directory")
 	return c
 }
@@ -24,6 +24,7 @@ import (
 	"github.com/soluble-ai/soluble-cli/cmd/build"
 	"github.com/soluble-ai/soluble-cli/cmd/cdkscan"
 	"github.com/soluble-ai/soluble-cli/cmd/cfnscan"
+	"github.com/soluble-ai/soluble-cli/cmd/ciscan"
 	"github.com/soluble-ai/soluble-cli/cmd/cloudscan"
 	"github.com/soluble-ai/soluble-cli/cmd/codescan"
 	configcmd "github.com/soluble-ai/soluble-cli/cmd/config"
@@ -41,6 +42,7 @@ import (
 	"github.com/soluble-ai/soluble-cli/cmd/repoinventory"
 	"github.com/soluble-ai/soluble-cli/cmd/secretsscan"
 	"github.com/soluble-ai/soluble-cli/cmd/tfscan"
+	"github.com/soluble-ai/soluble-cli/cmd/uploadcmd"
 	"github.com/soluble-ai/soluble-cli/cmd/version"
 	"github.com/soluble-ai/soluble-cli/pkg/config"
 	"github.com/soluble-ai/soluble-cli/pkg/exit"
@@ -51,6 +53,7 @@ import (
 	"github.com/soluble-ai/soluble-cli/pkg/tools/autoscan"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/checkov"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/cloudmap"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/sbom"
 	v "github.com/soluble-ai/soluble-cli/pkg/version"
 	"github.com/spf13/cobra"
 )
@@ -128,6 +131,7 @@ func addBuiltinCommands(rootCmd *cobra.Command) {
 		query.Command(),
 		downloadcmd.Command(),
 		postcmd.Command(),
+		uploadcmd.Command(),
 		imagescan.Command(),
 		inventorycmd.Command(),
 		logincmd.Command(),
@@ -137,6 +141,8 @@ func addBuiltinCommands(rootCmd *cobra.Command) {
 		helmscan.Command(),
 		tfscan.Command(),
 		secretsscan.Command(),
+		ciscan.Command(),
+		tools.CreateCommand(&sbom.Tool{}),
 		cfnscan.Command(),
 		tools.CreateCommand(&autoscan.Tool{}),
 		checkovCommand,
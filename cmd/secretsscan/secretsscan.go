@@ -24,5 +24,14 @@ func Command() *cobra.Command {
 	c := tools.CreateCommand(&secrets.Tool{})
 	c.Use = "secrets-scan"
 	c.Short = "Scan for secrets in code"
+	c.Long = `Scan for secrets in code
+
+Scans with detect-secrets.  Use the native sub-command to scan with the
+built-in detectors when docker isn't available.
+
+Use --history to scan every version of the files in the git history, to
+find secrets that were committed and later removed.  Each secret is
+reported once, with the first commit that added it.`
+	c.AddCommand(tools.CreateCommand(&secrets.NativeTool{}))
 	return c
 }
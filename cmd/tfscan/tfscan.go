@@ -17,6 +17,7 @@ package tfscan
 import (
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/checkov"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/opa"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/terrascan"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/tfscore"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/tfsec"
@@ -42,6 +43,9 @@ Scans terraform code with checkov.  Use a sub-command to explicitly choose a sca
 		tools.CreateCommand(&checkov.Tool{
 			Framework: "terraform",
 		}),
+		tools.CreateCommand(&opa.Tool{
+			Framework: "terraform",
+		}),
 		scan,
 	)
 	return c
@@ -0,0 +1,175 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uploadcmd
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/options"
+	"github.com/soluble-ai/soluble-cli/pkg/print"
+	"github.com/soluble-ai/soluble-cli/pkg/spool"
+	"github.com/spf13/cobra"
+)
+
+func pendingCommand() *cobra.Command {
+	opts := options.PrintOpts{
+		Path: []string{"data"},
+		Columns: []string{
+			"id", "module", "createTime", "attempts", "lastError",
+		},
+		WideColumns: []string{
+			"organization",
+		},
+	}
+	c := &cobra.Command{
+		Use:   "pending",
+		Short: "List the uploads waiting to be sent",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			uploads, err := spool.NewSpool().List()
+			if err != nil {
+				return err
+			}
+			n := jnode.NewObjectNode()
+			a := n.PutArray("data")
+			for _, u := range uploads {
+				m, err := print.ToResult(u)
+				if err != nil {
+					return err
+				}
+				a.Append(m)
+			}
+			opts.PrintResult(n)
+			return nil
+		},
+	}
+	opts.Register(c)
+	return c
+}
+
+func findUploads(s *spool.Spool, ids []string) ([]*spool.Upload, error) {
+	uploads, err := s.List()
+	if err != nil || len(ids) == 0 {
+		return uploads, err
+	}
+	byID := map[string]*spool.Upload{}
+	for _, u := range uploads {
+		byID[u.ID] = u
+	}
+	var result []*spool.Upload
+	for _, id := range ids {
+		u := byID[id]
+		if u == nil {
+			return nil, fmt.Errorf("no pending upload %s", id)
+		}
+		result = append(result, u)
+	}
+	return result, nil
+}
+
+func replayCommand() *cobra.Command {
+	var ids []string
+	opts := options.PrintClientOpts{
+		PrintOpts: options.PrintOpts{
+			Path:    []string{"data"},
+			Columns: []string{"id", "module", "appUrl"},
+		},
+	}
+	c := &cobra.Command{
+		Use:   "replay",
+		Short: "Send the pending uploads",
+		Long: `Send the uploads that were saved because the server could not be reached.
+Uploads that are sent successfully are removed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.RequireAPIToken(); err != nil {
+				return err
+			}
+			uploads, err := findUploads(spool.NewSpool(), ids)
+			if err != nil {
+				return err
+			}
+			if len(uploads) == 0 {
+				log.Infof("There are no pending uploads")
+				return nil
+			}
+			client := opts.GetAPIClient()
+			n := jnode.NewObjectNode()
+			a := n.PutArray("data")
+			var errs error
+			for _, u := range uploads {
+				log.Infof("Sending upload {primary:%s} of {info:%s}", u.ID, u.Module)
+				r, err := u.Replay(client)
+				if err != nil {
+					errs = multierror.Append(errs, fmt.Errorf("upload %s failed: %w", u.ID, err))
+					continue
+				}
+				a.AppendObject().Put("id", u.ID).Put("module", u.Module).
+					Put("appUrl", r.Path("assessment").Path("appUrl").AsText())
+			}
+			opts.PrintResult(n)
+			return errs
+		},
+	}
+	opts.Register(c)
+	c.Flags().StringSliceVar(&ids, "id", nil, "Send only the upload with this `id`, can be repeated")
+	return c
+}
+
+func purgeCommand() *cobra.Command {
+	var ids []string
+	c := &cobra.Command{
+		Use:   "purge",
+		Short: "Remove the pending uploads without sending them",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s := spool.NewSpool()
+			if len(ids) == 0 {
+				count, err := s.Purge()
+				log.Infof("Removed {primary:%d} pending uploads", count)
+				return err
+			}
+			uploads, err := findUploads(s, ids)
+			if err != nil {
+				return err
+			}
+			for _, u := range uploads {
+				if err := u.Remove(); err != nil {
+					return err
+				}
+			}
+			log.Infof("Removed {primary:%d} pending uploads", len(uploads))
+			return nil
+		},
+	}
+	c.Flags().StringSliceVar(&ids, "id", nil, "Remove only the upload with this `id`, can be repeated")
+	return c
+}
+
+func Command() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "upload",
+		Short: "Manage uploads that could not be sent",
+		Long: `When results can't be uploaded because the server can't be reached, the
+upload is saved so that it can be sent later with "upload replay".`,
+	}
+	c.AddCommand(pendingCommand())
+	c.AddCommand(replayCommand())
+	c.AddCommand(purgeCommand())
+	return c
+}
@@ -16,11 +16,15 @@ package api
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -59,6 +63,37 @@ type Client struct {
 	Config
 }
 
+// An httpError with the status code of the response
+type statusError struct {
+	httpError
+	statusCode int
+}
+
+// Returns the HTTP status code of an error response, or 0 if the error
+// isn't from an error response
+func GetStatusCode(err error) int {
+	var se statusError
+	if errors.As(err, &se) {
+		return se.statusCode
+	}
+	return 0
+}
+
+// Returns true if a request that failed with err might succeed if
+// it's sent again later, i.e. if the server couldn't be reached or
+// had a temporary problem
+func IsTemporaryError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if code := GetStatusCode(err); code != 0 {
+		return code >= 500 || code == http.StatusRequestTimeout || code == http.StatusTooManyRequests
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
 func (h httpError) Error() string {
 	return string(h)
 }
@@ -109,7 +144,10 @@ func NewClient(config *Config) *Client {
 			if r.StatusCode() == 401 || r.StatusCode() == 404 {
 				log.Infof("Are you not logged in?  Use {primary:soluble login} to login, or {primary:soluble auth profile} to verify")
 			}
-			return httpError(fmt.Sprintf("%s returned %d", r.Request.URL, r.StatusCode()))
+			return statusError{
+				httpError:  httpError(fmt.Sprintf("%s returned %d", r.Request.URL, r.StatusCode())),
+				statusCode: r.StatusCode(),
+			}
 		}
 		log.Debugf("%v", r.Result())
 		log.Infof("{info:%s} {primary:%s} returned {success:%d} in {secondary:%s}\n", r.Request.Method,
@@ -195,6 +233,11 @@ func (c *Client) Patch(path string, body *jnode.Node, options ...Option) (*jnode
 	return result, nil
 }
 
+// Download the response of a GET of path to the file dest
+func (c *Client) Download(path, dest string, options ...Option) error {
+	return c.execute(c.R().SetOutput(dest), resty.MethodGet, path, options)
+}
+
 func (c *Client) GetClient() *resty.Client {
 	return c.Client
 }
@@ -16,7 +16,12 @@ package api
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"syscall"
 	"testing"
 
 	"github.com/jarcoal/httpmock"
@@ -61,3 +66,30 @@ func TestError(t *testing.T) {
 		t.Error(e)
 	}
 }
+
+func TestStatusError(t *testing.T) {
+	c := NewClient(&Config{
+		APIServer: "https://api.soluble.cloud",
+	})
+	httpmock.ActivateNonDefault(c.Client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.soluble.cloud/api/v1/unavailable",
+		httpmock.NewStringResponder(http.StatusServiceUnavailable, "down"))
+	httpmock.RegisterResponder("GET", "https://api.soluble.cloud/api/v1/forbidden",
+		httpmock.NewStringResponder(http.StatusForbidden, "no"))
+	_, err := c.Get("/api/v1/unavailable")
+	if !errors.Is(err, HTTPError) || GetStatusCode(err) != 503 || !IsTemporaryError(err) {
+		t.Error(err)
+	}
+	_, err = c.Get("/api/v1/forbidden")
+	if GetStatusCode(err) != 403 || IsTemporaryError(err) {
+		t.Error(err)
+	}
+	refused := &url.Error{Op: "Get", URL: "https://api.soluble.cloud",
+		Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}
+	if !IsTemporaryError(refused) || !IsTemporaryError(fmt.Errorf("upload failed - %w", io.ErrUnexpectedEOF)) {
+		t.Error("network errors should be temporary")
+	}
+	if IsTemporaryError(nil) || IsTemporaryError(fmt.Errorf("organization is required")) {
+		t.Error("unexpected temporary error")
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/go-resty/resty/v2"
@@ -62,18 +63,35 @@ type Finding struct {
 	RepoPath           string            `json:"repoPath,omitempty"`
 	PartialFingerprint string            `json:"partialFingerprint,omitempty"`
 	Tool               map[string]string `json:"tool,omitempty"`
+
+	// Suppressed findings are reported but don't count towards failure thresholds
+	Suppressed        bool   `json:"suppressed,omitempty"`
+	SuppressedBy      string `json:"suppressedBy,omitempty"`
+	SuppressionReason string `json:"suppressionReason,omitempty"`
 }
 
 type Findings []*Finding
 
+const (
+	SuppressedByBaseline = "baseline"
+	SuppressedByComment  = "comment"
+	SuppressedByConfig   = "config"
+)
+
 var SeverityNames = util.NewStringSetWithValues([]string{
 	"info", "low", "medium", "high", "critical",
 })
 
+// The attributes that tools use to identify the check that generated a
+// finding, in order of preference
+var ruleIDAttributes = []string{
+	"check_id", "rule_id", "Rule_Id", "id", "VulnerabilityID",
+}
+
 func (a *Assessment) EvaluateFailures(thresholds map[string]int) {
 	counts := map[string]int{}
 	for _, f := range a.Findings {
-		if !f.Pass {
+		if !f.Pass && !f.Suppressed {
 			counts[strings.ToLower(f.Severity)] += 1
 		}
 	}
@@ -136,6 +154,15 @@ func (f *Finding) SetAttribute(name, value string) *Finding {
 	return f
 }
 
+// Mark the finding as suppressed, where by is one of the
+// SuppressedBy constants
+func (f *Finding) Suppress(by, reason string) *Finding {
+	f.Suppressed = true
+	f.SuppressedBy = by
+	f.SuppressionReason = reason
+	return f
+}
+
 func (f *Finding) GetTitle() string {
 	if f.Title != "" {
 		return f.Title
@@ -143,6 +170,35 @@ func (f *Finding) GetTitle() string {
 	return util.TruncateRight(f.Description, 57)
 }
 
+// Returns the identifier of the check or rule that generated the finding,
+// or "" if the tool doesn't provide one
+func (f *Finding) GetRuleID() string {
+	for _, name := range ruleIDAttributes {
+		if id := f.Tool[name]; id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// Returns the last line of the code the finding is about, from the
+// end_line attribute of tools that report a range of lines
+func (f *Finding) GetEndLine() int {
+	if end, err := strconv.Atoi(f.Tool["end_line"]); err == nil && end > f.Line {
+		return end
+	}
+	return f.Line
+}
+
+// Returns the severity of the finding, falling back to the severity
+// that the tool reported if the finding hasn't been assessed
+func (f *Finding) GetSeverity() string {
+	if f.Severity != "" {
+		return f.Severity
+	}
+	return f.Tool["severity"]
+}
+
 func FindCIEnvAssessments(client *api.Client) (Assessments, error) {
 	n, err := client.Get("/api/v1/org/{org}/assessments",
 		api.OptionFunc(func(r *resty.Request) {
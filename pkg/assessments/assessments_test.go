@@ -33,6 +33,7 @@ func TestAssessmentHasFailures(t *testing.T) {
 		{[]*Finding{{Severity: "high", Pass: true}}, []string{"high=1"}, false, "", 0},
 		{[]*Finding{{Severity: "high", Pass: false}}, []string{"low=1"}, true, "high", 1},
 		{[]*Finding{{Severity: "high", Pass: false}}, []string{"medium=1"}, true, "high", 1},
+		{[]*Finding{{Severity: "high", Pass: false, Suppressed: true}}, []string{"medium=1"}, false, "", 0},
 	}
 	for _, tc := range testCases {
 		assessment := &Assessment{
@@ -0,0 +1,137 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assessments
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const baselineVersion = 1
+
+// A Baseline is a set of failed findings that were recorded on an
+// earlier run.  Findings in the baseline are suppressed so that only
+// new findings are reported as failures.
+type Baseline struct {
+	Version  int                `json:"version"`
+	Findings []*BaselineFinding `json:"findings"`
+
+	keys map[BaselineFinding]bool
+}
+
+// Findings are identified by the tool, the check, and the location
+// in the repo.  The partial fingerprint identifies the location in a
+// way that's stable as lines are added or removed around it.
+type BaselineFinding struct {
+	Tool               string `json:"tool"`
+	RuleID             string `json:"ruleId,omitempty"`
+	RepoPath           string `json:"repoPath,omitempty"`
+	PartialFingerprint string `json:"partialFingerprint,omitempty"`
+}
+
+func NewBaseline() *Baseline {
+	return &Baseline{
+		Version: baselineVersion,
+		keys:    map[BaselineFinding]bool{},
+	}
+}
+
+func ReadBaseline(path string) (*Baseline, error) {
+	d, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b := NewBaseline()
+	if err := json.Unmarshal(d, b); err != nil {
+		return nil, fmt.Errorf("invalid baseline %s - %w", path, err)
+	}
+	if b.Version > baselineVersion {
+		return nil, fmt.Errorf("baseline %s has unsupported version %d", path, b.Version)
+	}
+	for _, bf := range b.Findings {
+		b.keys[*bf] = true
+	}
+	return b, nil
+}
+
+func getBaselineFinding(tool string, f *Finding) BaselineFinding {
+	path := f.RepoPath
+	if path == "" {
+		path = f.FilePath
+	}
+	return BaselineFinding{
+		Tool:               tool,
+		RuleID:             f.GetRuleID(),
+		RepoPath:           filepath.ToSlash(path),
+		PartialFingerprint: f.PartialFingerprint,
+	}
+}
+
+// Add the failed findings to the baseline.  Findings that are
+// suppressed by a comment or the config file are left out, but the
+// findings suppressed by an earlier baseline are kept.
+func (b *Baseline) Add(tool string, findings Findings) {
+	for _, f := range findings {
+		if f.Pass || (f.Suppressed && f.SuppressedBy != SuppressedByBaseline) {
+			continue
+		}
+		bf := getBaselineFinding(tool, f)
+		if !b.keys[bf] {
+			b.keys[bf] = true
+			b.Findings = append(b.Findings, &bf)
+		}
+	}
+}
+
+func (b *Baseline) Contains(tool string, f *Finding) bool {
+	return b.keys[getBaselineFinding(tool, f)]
+}
+
+func (b *Baseline) Write(path string) error {
+	sort.Slice(b.Findings, func(i, j int) bool {
+		x, y := b.Findings[i], b.Findings[j]
+		switch {
+		case x.Tool != y.Tool:
+			return x.Tool < y.Tool
+		case x.RepoPath != y.RepoPath:
+			return x.RepoPath < y.RepoPath
+		case x.RuleID != y.RuleID:
+			return x.RuleID < y.RuleID
+		default:
+			return x.PartialFingerprint < y.PartialFingerprint
+		}
+	})
+	d, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(d, '\n'), 0600)
+}
+
+// Suppress the failed findings that are in the baseline, returning
+// the number of findings suppressed
+func (findings Findings) ApplyBaseline(tool string, b *Baseline) int {
+	count := 0
+	for _, f := range findings {
+		if !f.Pass && !f.Suppressed && b.Contains(tool, f) {
+			f.Suppress(SuppressedByBaseline, "in baseline")
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,55 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assessments
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseline(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "baseline.json")
+	b := NewBaseline()
+	b.Add("checkov", Findings{
+		{RepoPath: "tf/main.tf", PartialFingerprint: "abc:1", Tool: map[string]string{"check_id": "CKV_AWS_20"}},
+		{RepoPath: "tf/main.tf", PartialFingerprint: "abc:1", Tool: map[string]string{"check_id": "CKV_AWS_20"}},
+		{RepoPath: "tf/main.tf", PartialFingerprint: "def:1", Pass: true, Tool: map[string]string{"check_id": "CKV_AWS_19"}},
+		{RepoPath: "tf/main.tf", PartialFingerprint: "ghi:1", Suppressed: true, SuppressedBy: SuppressedByComment,
+			Tool: map[string]string{"check_id": "CKV_AWS_21"}},
+	})
+	assert.Len(b.Findings, 1)
+	assert.NoError(b.Write(path))
+	b, err := ReadBaseline(path)
+	assert.NoError(err)
+	findings := Findings{
+		{RepoPath: "tf/main.tf", PartialFingerprint: "abc:1", Tool: map[string]string{"check_id": "CKV_AWS_20"}},
+		{RepoPath: "tf/main.tf", PartialFingerprint: "xyz:1", Tool: map[string]string{"check_id": "CKV_AWS_20"}},
+		{RepoPath: "tf/other.tf", PartialFingerprint: "abc:1", Tool: map[string]string{"check_id": "CKV_AWS_20"}},
+	}
+	assert.Equal(0, findings.ApplyBaseline("tfsec", b))
+	assert.Equal(1, findings.ApplyBaseline("checkov", b))
+	assert.True(findings[0].Suppressed)
+	assert.Equal(SuppressedByBaseline, findings[0].SuppressedBy)
+	assert.False(findings[1].Suppressed)
+	assert.False(findings[2].Suppressed)
+	assert.NoError(os.WriteFile(path, []byte(`{"version": 99}`), 0600))
+	_, err = ReadBaseline(path)
+	assert.Error(err)
+}
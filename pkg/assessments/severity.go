@@ -0,0 +1,135 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assessments
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed severities.yaml
+var defaultSeverities []byte
+
+// A SeverityCatalog maps tool names and check ids to severities.  It's
+// used to evaluate findings that haven't been assessed by the server.
+type SeverityCatalog map[string]map[string]string
+
+// The severities that tools natively report, mapped to our severity names
+var nativeSeverities = map[string]string{
+	"critical":      "critical",
+	"high":          "high",
+	"error":         "high",
+	"medium":        "medium",
+	"moderate":      "medium",
+	"warning":       "medium",
+	"low":           "low",
+	"info":          "info",
+	"informational": "info",
+	"style":         "info",
+	"note":          "info",
+}
+
+// Returns the severity catalog that's bundled with the CLI
+func GetDefaultSeverityCatalog() SeverityCatalog {
+	c, err := parseSeverityCatalog(defaultSeverities)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Read a severity catalog in the same format as the bundled severities.yaml
+func ReadSeverityCatalog(path string) (SeverityCatalog, error) {
+	d, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := parseSeverityCatalog(d)
+	if err != nil {
+		return nil, fmt.Errorf("invalid severity catalog %s - %w", path, err)
+	}
+	return c, nil
+}
+
+func parseSeverityCatalog(d []byte) (SeverityCatalog, error) {
+	c := SeverityCatalog{}
+	if err := yaml.Unmarshal(d, &c); err != nil {
+		return nil, err
+	}
+	var err error
+	for tool, checks := range c {
+		for id, severity := range checks {
+			s := NormalizeSeverity(severity)
+			if s == "" {
+				err = multierror.Append(err, fmt.Errorf("invalid severity %s for %s %s", severity, tool, id))
+				continue
+			}
+			checks[id] = s
+		}
+	}
+	return c, err
+}
+
+// Merge the severities from other into this catalog, with other taking
+// precedence
+func (c SeverityCatalog) Merge(other SeverityCatalog) SeverityCatalog {
+	for tool, checks := range other {
+		m := c[tool]
+		if m == nil {
+			m = map[string]string{}
+			c[tool] = m
+		}
+		for id, severity := range checks {
+			m[id] = severity
+		}
+	}
+	return c
+}
+
+// Returns the local severity of a finding.  The catalog entry for the
+// finding's check takes precedence, then the severity the tool reported,
+// and finally the tool's default severity from the catalog.
+func (c SeverityCatalog) GetSeverity(tool string, f *Finding) string {
+	checks := c[tool]
+	if id := f.GetRuleID(); id != "" {
+		if s := checks[id]; s != "" {
+			return s
+		}
+	}
+	if s := NormalizeSeverity(f.GetSeverity()); s != "" {
+		return s
+	}
+	return checks["*"]
+}
+
+// Returns the severity name for a tool's native severity, or "" if
+// the severity isn't recognized
+func NormalizeSeverity(severity string) string {
+	return nativeSeverities[strings.ToLower(strings.TrimSpace(severity))]
+}
+
+// Set the severity of all the findings that don't have one from the catalog
+func (findings Findings) ApplySeverities(tool string, catalog SeverityCatalog) {
+	for _, f := range findings {
+		if f.Severity == "" {
+			f.Severity = catalog.GetSeverity(tool, f)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assessments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeverityCatalog(t *testing.T) {
+	assert := assert.New(t)
+	c := GetDefaultSeverityCatalog()
+	assert.Equal("high", c["checkov"]["CKV_AWS_20"])
+	override, err := parseSeverityCatalog([]byte("checkov:\n  CKV_AWS_20: CRITICAL\ntfsec:\n  \"*\": low\n"))
+	assert.NoError(err)
+	c.Merge(override)
+	findings := Findings{
+		{Tool: map[string]string{"check_id": "CKV_AWS_20"}},
+		{Tool: map[string]string{"check_id": "CKV_AWS_9999"}},
+		{Severity: "low", Tool: map[string]string{"check_id": "CKV_AWS_20"}},
+	}
+	findings.ApplySeverities("checkov", c)
+	assert.Equal("critical", findings[0].Severity)
+	assert.Equal("medium", findings[1].Severity)
+	assert.Equal("low", findings[2].Severity)
+	var testCases = []struct {
+		tool, native, severity string
+	}{
+		{"tfsec", "HIGH", "high"},
+		{"tfsec", "", "low"},
+		{"semgrep", "ERROR", "high"},
+		{"semgrep", "WARNING", "medium"},
+		{"hadolint", "style", "info"},
+		{"npm-audit", "moderate", "medium"},
+		{"unknown", "", ""},
+	}
+	for _, tc := range testCases {
+		f := &Finding{Tool: map[string]string{"severity": tc.native}}
+		assert.Equal(tc.severity, c.GetSeverity(tc.tool, f), tc)
+	}
+	_, err = parseSeverityCatalog([]byte("checkov:\n  CKV_AWS_20: urgent\n"))
+	assert.Error(err)
+}
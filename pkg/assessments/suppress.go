@@ -0,0 +1,152 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assessments
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+)
+
+// Inline suppressions are comments of the form:
+//
+//	# soluble:ignore CKV_AWS_20 reason="public website"
+//
+// at the end of the line of a finding or on the comment line(s)
+// immediately above it.  Any comment syntax can be used.  Multiple ids
+// can be separated by commas or spaces, and if no ids are given all
+// findings on the line are suppressed.
+var (
+	suppressionMarker = regexp.MustCompile(`\b(?:soluble|lacework):ignore\b`)
+	suppressionReason = regexp.MustCompile(`reason=(?:"([^"]*)"|'([^']*)'|(\S+))`)
+	commentTerminator = regexp.MustCompile(`(\*/|-->)\s*$`)
+)
+
+const defaultSuppressionReason = "suppressed by comment"
+
+type inlineSuppression struct {
+	ids    []string
+	reason string
+	// true if the comment is on a line by itself
+	standalone bool
+}
+
+func parseInlineSuppression(line string) *inlineSuppression {
+	loc := suppressionMarker.FindStringIndex(line)
+	if loc == nil {
+		return nil
+	}
+	rest := commentTerminator.ReplaceAllString(line[loc[1]:], "")
+	s := &inlineSuppression{
+		reason:     defaultSuppressionReason,
+		standalone: strings.Trim(line[:loc[0]], " \t#/*-<!;") == "",
+	}
+	if m := suppressionReason.FindStringSubmatch(rest); m != nil {
+		for _, r := range m[1:] {
+			if r != "" {
+				s.reason = r
+				break
+			}
+		}
+		rest = strings.Replace(rest, m[0], "", 1)
+	}
+	s.ids = strings.FieldsFunc(rest, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	return s
+}
+
+func (s *inlineSuppression) matches(f *Finding) bool {
+	if len(s.ids) == 0 {
+		return true
+	}
+	id := f.GetRuleID()
+	for _, sid := range s.ids {
+		if strings.EqualFold(sid, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// The suppressions in a file, keyed by line number
+type fileSuppressions map[int]*inlineSuppression
+
+func readFileSuppressions(path string) fileSuppressions {
+	fs := fileSuppressions{}
+	lineNumber := 0
+	err := util.ForEachLine(path, func(line string) bool {
+		lineNumber++
+		if s := parseInlineSuppression(line); s != nil {
+			fs[lineNumber] = s
+		}
+		return true
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Warnf("Could not read suppressions from {warning:%s} - {warning:%s}", path, err)
+	}
+	return fs
+}
+
+// Returns the suppression that applies to the finding, looking at
+// the finding's line and the block of suppression comments directly
+// above it
+func (fs fileSuppressions) find(f *Finding) *inlineSuppression {
+	for line := f.Line; line > 0; line-- {
+		s := fs[line]
+		if s == nil || (line != f.Line && !s.standalone) {
+			if line == f.Line {
+				continue
+			}
+			break
+		}
+		if s.matches(f) {
+			return s
+		}
+	}
+	return nil
+}
+
+// Suppress findings that have an inline suppression comment, returning
+// the number of findings suppressed.  The file paths of the findings are
+// relative to dir.
+func (findings Findings) ApplyInlineSuppressions(dir string) int {
+	files := map[string]fileSuppressions{}
+	count := 0
+	for _, f := range findings {
+		if f.Pass || f.Suppressed || f.FilePath == "" || f.Line <= 0 {
+			continue
+		}
+		fs, ok := files[f.FilePath]
+		if !ok {
+			path := f.FilePath
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			fs = readFileSuppressions(path)
+			files[f.FilePath] = fs
+		}
+		if s := fs.find(f); s != nil {
+			f.Suppress(SuppressedByComment, s.reason)
+			count++
+		}
+	}
+	return count
+}
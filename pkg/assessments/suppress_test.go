@@ -0,0 +1,59 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assessments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseInlineSuppression(t *testing.T) {
+	assert := assert.New(t)
+	assert.Nil(parseInlineSuppression(`acl = "private"`))
+	s := parseInlineSuppression(`# soluble:ignore CKV_AWS_20 reason="public website"`)
+	assert.Equal([]string{"CKV_AWS_20"}, s.ids)
+	assert.Equal("public website", s.reason)
+	s = parseInlineSuppression(`<!-- soluble:ignore DS001,DS002 -->`)
+	assert.Equal([]string{"DS001", "DS002"}, s.ids)
+	assert.Equal(defaultSuppressionReason, s.reason)
+	s = parseInlineSuppression(`/* lacework:ignore */`)
+	assert.Empty(s.ids)
+}
+
+func TestApplyInlineSuppressions(t *testing.T) {
+	assert := assert.New(t)
+	check := func(id string, line int) *Finding {
+		return &Finding{FilePath: "suppressed.tf", Line: line, Tool: map[string]string{"check_id": id}}
+	}
+	findings := Findings{
+		check("CKV_AWS_20", 3),
+		check("CKV_AWS_21", 4),
+		check("CKV_AWS_18", 9),
+		check("CKV_AWS_19", 9),
+		check("CKV_AWS_144", 10),
+		check("CKV_AWS_20", 1),
+		check("CKV_AWS_145", 9),
+		check("CKV_AWS_21", 5),
+		{FilePath: "missing.tf", Line: 1},
+	}
+	assert.Equal(5, findings.ApplyInlineSuppressions("testdata"))
+	for i, suppressed := range []bool{true, true, true, true, true, false, false, false, false} {
+		assert.Equal(suppressed, findings[i].Suppressed, i)
+	}
+	assert.Equal("public website", findings[0].SuppressionReason)
+	assert.Equal(SuppressedByComment, findings[0].SuppressedBy)
+	assert.Equal("encrypted-elsewhere", findings[3].SuppressionReason)
+}
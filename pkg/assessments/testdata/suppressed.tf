@@ -0,0 +1,11 @@
+resource "aws_s3_bucket" "website" {
+  # soluble:ignore CKV_AWS_20 reason="public website"
+  acl = "public-read"
+  versioning { # soluble:ignore CKV_AWS_21
+    enabled = false
+  }
+  // lacework:ignore CKV_AWS_18, CKV_AWS_52
+  // soluble:ignore CKV_AWS_19 reason=encrypted-elsewhere
+  bucket = "example"
+  logging = false /* soluble:ignore */
+}
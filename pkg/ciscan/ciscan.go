@@ -0,0 +1,131 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ciscan looks for security problems in the configuration of
+// CI pipelines.
+package ciscan
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A Rule is a kind of problem in a pipeline
+type Rule struct {
+	ID       string
+	Title    string
+	Severity string
+}
+
+var (
+	UnpinnedDependency = &Rule{"CI_UNPINNED_DEPENDENCY",
+		"Third-party action, orb, or plugin is not pinned", "medium"}
+	PullRequestTargetCheckout = &Rule{"CI_PR_TARGET_CHECKOUT",
+		"pull_request_target workflow checks out the pull request", "critical"}
+	SecretEchoed = &Rule{"CI_SECRET_ECHOED",
+		"Secret is printed to the build log", "high"}
+	SecretToUntrustedStep = &Rule{"CI_SECRET_TO_UNTRUSTED_STEP",
+		"Secret is passed to an unpinned third-party action", "high"}
+	BroadPermissions = &Rule{"CI_BROAD_PERMISSIONS",
+		"Workflow grants write access to all scopes", "medium"}
+	CurlPipeShell = &Rule{"CI_CURL_PIPE_SHELL",
+		"Downloaded script is piped to a shell", "high"}
+	PullRequestTargetWrite = &Rule{"CI_PR_TARGET_WRITE",
+		"pull_request_target workflow can write the repository contents", "high"}
+)
+
+var Rules = []*Rule{
+	UnpinnedDependency, PullRequestTargetCheckout, SecretEchoed,
+	SecretToUntrustedStep, BroadPermissions, CurlPipeShell, PullRequestTargetWrite,
+}
+
+// An Issue is a problem found in a pipeline
+type Issue struct {
+	*Rule
+	Line int
+	// what the problem is about e.g. the action or the command
+	Detail string
+}
+
+// The CI systems whose configuration can be checked
+const (
+	GitHub    = "github"
+	GitLab    = "gitlab"
+	CircleCI  = "circleci"
+	Buildkite = "buildkite"
+	Jenkins   = "jenkins"
+)
+
+// Returns the CI system of a configuration file from its path, or
+// "" if the file isn't recognized
+func GetSystem(path string) string {
+	base := filepath.Base(path)
+	dir := filepath.Base(filepath.Dir(path))
+	switch {
+	case base == "Jenkinsfile":
+		return Jenkins
+	case base == ".gitlab-ci.yml":
+		return GitLab
+	case dir == "workflows" && filepath.Base(filepath.Dir(filepath.Dir(path))) == ".github":
+		return GitHub
+	case dir == ".circleci":
+		return CircleCI
+	case dir == ".buildkite" || base == "buildkite.yml" || base == "buildkite.yaml":
+		return Buildkite
+	}
+	return ""
+}
+
+// Check the configuration of a CI system, returning the issues ordered
+// by line
+func Check(system string, content []byte) ([]*Issue, error) {
+	var issues []*Issue
+	if system == Jenkins {
+		issues = checkJenkinsfile(content)
+	} else {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(content, &doc); err != nil {
+			return nil, err
+		}
+		if len(doc.Content) == 0 {
+			return nil, nil
+		}
+		root := doc.Content[0]
+		switch system {
+		case GitHub:
+			issues = checkGitHubWorkflow(root)
+		case GitLab:
+			issues = checkGitLabCI(root)
+		case CircleCI:
+			issues = checkCircleCI(root)
+		case Buildkite:
+			issues = checkBuildkite(root)
+		default:
+			return nil, fmt.Errorf("unsupported CI system %q", system)
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Line < issues[j].Line
+	})
+	return issues, nil
+}
+
+type issues []*Issue
+
+func (is *issues) add(rule *Rule, line int, detail string) {
+	*is = append(*is, &Issue{Rule: rule, Line: line, Detail: detail})
+}
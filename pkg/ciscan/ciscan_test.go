@@ -0,0 +1,155 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ciscan
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func checkTestFile(t *testing.T, system, name string) []string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues, err := Check(system, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r []string
+	for _, issue := range issues {
+		r = append(r, fmt.Sprintf("%s:%d:%s", issue.ID, issue.Line, issue.Detail))
+	}
+	return r
+}
+
+func TestGitHub(t *testing.T) {
+	assert.Equal(t, []string{
+		"CI_BROAD_PERMISSIONS:5:write-all",
+		"CI_PR_TARGET_CHECKOUT:14:${{ github.event.pull_request.head.sha }}",
+		"CI_UNPINNED_DEPENDENCY:16:docker://alpine:3.16",
+		"CI_UNPINNED_DEPENDENCY:17:aquasecurity/trivy-action@0.7.1",
+		"CI_SECRET_TO_UNTRUSTED_STEP:21:TOKEN",
+		"CI_CURL_PIPE_SHELL:27:curl -sSL https://example.com/install.sh | sudo bash",
+		"CI_SECRET_ECHOED:28:${{ secrets.DEPLOY_TOKEN }}",
+		"CI_BROAD_PERMISSIONS:32:write-all",
+		"CI_UNPINNED_DEPENDENCY:33:example/workflows/.github/workflows/release.yml@main",
+		"CI_SECRET_TO_UNTRUSTED_STEP:34:example/workflows/.github/workflows/release.yml@main",
+		"CI_PR_TARGET_WRITE:38:contents: write",
+		"CI_BROAD_PERMISSIONS:44:write to all scopes",
+		"CI_PR_TARGET_WRITE:46:contents: write",
+	}, checkTestFile(t, GitHub, "github.yml"))
+}
+
+func TestGitHubPermissions(t *testing.T) {
+	assert := assert.New(t)
+	// writing the contents is only a problem for pull_request_target
+	issues, err := Check(GitHub, []byte(`on: push
+permissions:
+  contents: write
+  issues: read
+`))
+	assert.NoError(err)
+	assert.Empty(issues)
+	issues, err = Check(GitHub, []byte(`on: [pull_request_target]
+permissions:
+  contents: write
+`))
+	assert.NoError(err)
+	if assert.Len(issues, 1) {
+		assert.Equal(PullRequestTargetWrite, issues[0].Rule)
+		assert.Equal(3, issues[0].Line)
+	}
+}
+
+func TestGitLab(t *testing.T) {
+	assert.Equal(t, []string{
+		"CI_UNPINNED_DEPENDENCY:2:https://example.com/ci/template.yml",
+		"CI_UNPINNED_DEPENDENCY:3:group/templates",
+		"CI_SECRET_ECHOED:11:$CI_JOB_TOKEN",
+		"CI_CURL_PIPE_SHELL:15:wget -qO- https://example.com/setup | sh",
+	}, checkTestFile(t, GitLab, "gitlab-ci.yml"))
+}
+
+func TestCircleCI(t *testing.T) {
+	assert.Equal(t, []string{
+		"CI_UNPINNED_DEPENDENCY:4:example/aws-cli@volatile",
+		"CI_UNPINNED_DEPENDENCY:6:example/helm@1",
+		"CI_CURL_PIPE_SHELL:13:bash <(curl -s https://codecov.io/bash)",
+		"CI_SECRET_ECHOED:16:$AWS_SECRET_ACCESS_KEY",
+	}, checkTestFile(t, CircleCI, "circleci.yml"))
+}
+
+func TestBuildkite(t *testing.T) {
+	assert.Equal(t, []string{
+		"CI_CURL_PIPE_SHELL:5:curl -fsSL https://example.com/install | sh -s -- -y",
+		"CI_UNPINNED_DEPENDENCY:9:example/unversioned",
+		"CI_UNPINNED_DEPENDENCY:11:another-plugin",
+	}, checkTestFile(t, Buildkite, "buildkite.yml"))
+}
+
+func TestJenkins(t *testing.T) {
+	assert.Equal(t, []string{
+		"CI_UNPINNED_DEPENDENCY:1:shared-lib",
+		"CI_SECRET_ECHOED:9:$API_TOKEN",
+		"CI_CURL_PIPE_SHELL:11:sh 'curl https://example.com/install.sh | bash'",
+	}, checkTestFile(t, Jenkins, "Jenkinsfile"))
+}
+
+func TestGetSystem(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(GitHub, GetSystem(".github/workflows/ci.yml"))
+	assert.Equal(GitLab, GetSystem(".gitlab-ci.yml"))
+	assert.Equal(CircleCI, GetSystem(".circleci/config.yml"))
+	assert.Equal(Buildkite, GetSystem(".buildkite/pipeline.yml"))
+	assert.Equal(Buildkite, GetSystem("buildkite.yml"))
+	assert.Equal(Jenkins, GetSystem("Jenkinsfile"))
+	assert.Equal("", GetSystem("main.tf"))
+}
+
+func TestParseAction(t *testing.T) {
+	assert := assert.New(t)
+	for _, tc := range []struct {
+		uses               string
+		thirdParty, pinned bool
+	}{
+		{"./.github/actions/build", false, true},
+		{"actions/checkout@v3", false, false},
+		{"example/action@v1", true, false},
+		{"example/action@b466648d6e39e7c75324f25d83891162a721f2d1", true, true},
+		{"example/action/sub@b466648d6e39e7c75324f25d83891162a721f2d1", true, true},
+		{"docker://alpine:3.16", true, false},
+		{"docker://alpine@sha256:bc41182d7ef5ffc53a40b044e725193bc10142a1243f395ee852a8d9730fc2ad", true, true},
+	} {
+		thirdParty, pinned := parseAction(tc.uses)
+		assert.Equal(tc.thirdParty, thirdParty, tc.uses)
+		assert.Equal(tc.pinned, pinned, tc.uses)
+	}
+}
+
+func TestFindEchoedSecret(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("${{ secrets.TOKEN }}", findEchoedSecret(`echo "${{ secrets.TOKEN }}"`))
+	assert.Equal("$AWS_SECRET_ACCESS_KEY", findEchoedSecret(`printf "%s" $AWS_SECRET_ACCESS_KEY`))
+	assert.Equal("", findEchoedSecret(`echo "${{ secrets.TOKEN }}" | docker login --password-stdin`))
+	assert.Equal("", findEchoedSecret(`echo "${{ secrets.TOKEN }}" > token.txt`))
+	assert.Equal("", findEchoedSecret(`echo "::add-mask::${{ secrets.TOKEN }}"`))
+	assert.Equal("", findEchoedSecret(`echo "$HOME"`))
+}
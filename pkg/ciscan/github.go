@@ -0,0 +1,176 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ciscan
+
+import (
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// expressions that refer to the code of a pull request
+	pullRequestHead = regexp.MustCompile(
+		`github\.event\.pull_request\.head\.(?:sha|ref)|github\.head_ref|refs/pull/`)
+	pullRequestCheckout = regexp.MustCompile(
+		`\bgit\s+(?:checkout|fetch|switch)\b.*(?:github\.event\.pull_request\.head|github\.head_ref|refs/pull/)|\bgh\s+pr\s+checkout\b`)
+	secretsExpr = regexp.MustCompile(`\$\{\{[^}]*\bsecrets\.`)
+)
+
+func checkGitHubWorkflow(root *yaml.Node) []*Issue {
+	var is issues
+	pullRequestTarget := false
+	for _, event := range getEvents(lookup(root, "on")) {
+		if event == "pull_request_target" {
+			pullRequestTarget = true
+		}
+	}
+	is.checkPermissions(lookup(root, "permissions"), pullRequestTarget)
+	forEachEntry(lookup(root, "jobs"), func(_, job *yaml.Node) {
+		is.checkPermissions(lookup(job, "permissions"), pullRequestTarget)
+		if uses := lookup(job, "uses"); uses != nil {
+			// a reusable workflow
+			if is.checkAction(uses) {
+				if secrets := lookup(job, "secrets"); secrets != nil {
+					is.add(SecretToUntrustedStep, secrets.Line, uses.Value)
+				}
+			}
+		}
+		for _, step := range elements(lookup(job, "steps")) {
+			is.checkStep(step, pullRequestTarget)
+		}
+	})
+	return is
+}
+
+// The events can be a string, a list, or a mapping of event to its
+// configuration
+func getEvents(n *yaml.Node) []string {
+	var events []string
+	if n != nil && n.Kind == yaml.MappingNode {
+		forEachEntry(n, func(key, _ *yaml.Node) {
+			events = append(events, key.Value)
+		})
+		return events
+	}
+	for _, s := range scalars(n) {
+		events = append(events, s.Value)
+	}
+	return events
+}
+
+// The scopes of the GITHUB_TOKEN that permissions can be granted for
+var permissionScopes = []string{
+	"actions", "checks", "contents", "deployments", "discussions", "id-token", "issues",
+	"packages", "pages", "pull-requests", "repository-projects", "security-events", "statuses",
+}
+
+// Permissions are either write-all or read-all, or a mapping of scope to
+// its access.  Granting write to every scope is the same as write-all,
+// and a pull_request_target workflow that can write the contents lets
+// code from a pull request be pushed to the repository.
+func (is *issues) checkPermissions(n *yaml.Node, pullRequestTarget bool) {
+	switch {
+	case n == nil:
+	case n.Kind == yaml.ScalarNode:
+		if n.Value == "write-all" {
+			is.add(BroadPermissions, n.Line, n.Value)
+		}
+	case n.Kind == yaml.MappingNode:
+		all := true
+		for _, scope := range permissionScopes {
+			if access := lookup(n, scope); access == nil || access.Value != "write" {
+				all = false
+			}
+		}
+		if all {
+			is.add(BroadPermissions, n.Line, "write to all scopes")
+		}
+		if contents := lookup(n, "contents"); pullRequestTarget && contents != nil && contents.Value == "write" {
+			is.add(PullRequestTargetWrite, contents.Line, "contents: write")
+		}
+	}
+}
+
+func (is *issues) checkStep(step *yaml.Node, pullRequestTarget bool) {
+	untrusted := false
+	if uses := lookup(step, "uses"); uses != nil {
+		untrusted = is.checkAction(uses)
+		if pullRequestTarget && strings.HasPrefix(uses.Value, "actions/checkout@") {
+			if ref := lookup(lookup(step, "with"), "ref"); ref != nil && pullRequestHead.MatchString(ref.Value) {
+				is.add(PullRequestTargetCheckout, ref.Line, ref.Value)
+			}
+		}
+	}
+	if untrusted {
+		for _, key := range []string{"with", "env"} {
+			forEachEntry(lookup(step, key), func(k, v *yaml.Node) {
+				if secretsExpr.MatchString(v.Value) {
+					is.add(SecretToUntrustedStep, v.Line, k.Value)
+				}
+			})
+		}
+	}
+	if run := lookup(step, "run"); run != nil {
+		if pullRequestTarget && pullRequestCheckout.MatchString(run.Value) {
+			is.add(PullRequestTargetCheckout, run.Line, truncate(run.Value))
+		}
+		is.checkScriptNode(run)
+	}
+}
+
+// Check that a third-party action is pinned to a commit, returning
+// true if the action is third-party and isn't pinned
+func (is *issues) checkAction(uses *yaml.Node) bool {
+	if thirdParty, pinned := parseAction(uses.Value); thirdParty && !pinned {
+		is.add(UnpinnedDependency, uses.Line, uses.Value)
+		return true
+	}
+	return false
+}
+
+// Tags and branches can be moved, so an action is only pinned when its
+// ref is a full commit SHA (or a docker image digest.)  Local actions
+// and actions from GitHub are trusted.
+func parseAction(uses string) (thirdParty, pinned bool) {
+	switch {
+	case strings.HasPrefix(uses, "./"):
+		return false, true
+	case strings.HasPrefix(uses, "docker://"):
+		return true, strings.Contains(uses, "@sha256:")
+	}
+	owner := uses
+	if slash := strings.IndexByte(uses, '/'); slash >= 0 {
+		owner = uses[:slash]
+	}
+	thirdParty = owner != "actions" && owner != "github"
+	if at := strings.LastIndexByte(uses, '@'); at >= 0 {
+		pinned = isCommitSHA(uses[at+1:])
+	}
+	return
+}
+
+func isCommitSHA(s string) bool {
+	if len(s) != 40 {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
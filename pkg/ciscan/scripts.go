@@ -0,0 +1,88 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ciscan
+
+import (
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	curlPipeShell = regexp.MustCompile(
+		`\b(?:curl|wget)\b[^|\n]*\|\s*(?:sudo\s+(?:-\S+\s+)*)?(?:\S*/)?(?:(?:ba|z|k|da|fi)?sh|python[0-9.]*|perl|ruby|node)\b`)
+	shellOfCurl = regexp.MustCompile(
+		`(?:\b(?:ba|z|k|da)?sh\b(?:\s+-c)?|\bsource)\s+["']?(?:<\(|\$\()\s*(?:curl|wget)\b`)
+	echoCommand = regexp.MustCompile(`\b(?:echo|printf|Write-Output|Write-Host)\b`)
+	secretRef   = regexp.MustCompile(
+		`\$\{\{\s*secrets\.\w+\s*\}\}|\$\{?(?:env\.)?[A-Z0-9_]*(?:TOKEN|SECRET|PASSWORD|PASSWD|API_KEY|APIKEY|PRIVATE_KEY|CREDENTIAL)[A-Z0-9_]*\}?`)
+)
+
+const maxDetailLength = 120
+
+func truncate(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) > maxDetailLength {
+		return s[:maxDetailLength] + "..."
+	}
+	return s
+}
+
+// Check the lines of a shell script that starts at line
+func (is *issues) checkScript(script string, line int) {
+	for i, l := range strings.Split(script, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(l), "#") {
+			continue
+		}
+		if curlPipeShell.MatchString(l) || shellOfCurl.MatchString(l) {
+			is.add(CurlPipeShell, line+i, truncate(l))
+		}
+		if ref := findEchoedSecret(l); ref != "" {
+			is.add(SecretEchoed, line+i, ref)
+		}
+	}
+}
+
+// Returns the secret that a line prints, or "".  Secrets that are
+// piped or redirected somewhere else don't end up in the log.
+func findEchoedSecret(line string) string {
+	loc := echoCommand.FindStringIndex(line)
+	if loc == nil {
+		return ""
+	}
+	rest := line[loc[1]:]
+	if strings.Contains(rest, "::add-mask::") {
+		return ""
+	}
+	ref := secretRef.FindStringIndex(rest)
+	if ref == nil || strings.ContainsAny(rest[ref[1]:], "|>") {
+		return ""
+	}
+	return rest[ref[0]:ref[1]]
+}
+
+// Check the scripts in a node that's either a string or a list of
+// strings
+func (is *issues) checkScriptNode(n *yaml.Node) {
+	for _, s := range scalars(n) {
+		line := s.Line
+		if s.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+			// the script starts on the line after the | or >
+			line++
+		}
+		is.checkScript(s.Value, line)
+	}
+}
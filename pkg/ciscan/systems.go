@@ -0,0 +1,143 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ciscan
+
+import (
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	fullVersion    = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+	jenkinsLibrary = regexp.MustCompile(`@Library\(\s*\[?([^)]*)\)`)
+	quoted         = regexp.MustCompile(`['"]([^'"]+)['"]`)
+)
+
+var gitlabScriptKeys = []string{"before_script", "script", "after_script"}
+
+func checkGitLabCI(root *yaml.Node) []*Issue {
+	var is issues
+	// the global scripts are deprecated but still work
+	for _, key := range gitlabScriptKeys {
+		is.checkScriptNode(lookup(root, key))
+	}
+	forEachEntry(root, func(key, job *yaml.Node) {
+		if key.Value == "include" {
+			is.checkGitLabIncludes(job)
+			return
+		}
+		for _, key := range gitlabScriptKeys {
+			is.checkScriptNode(lookup(job, key))
+		}
+	})
+	return is
+}
+
+// Remote includes can change at any time, and project includes
+// without a ref follow the project's default branch
+func (is *issues) checkGitLabIncludes(n *yaml.Node) {
+	includes := elements(n)
+	if n != nil && n.Kind != yaml.SequenceNode {
+		includes = []*yaml.Node{n}
+	}
+	for _, include := range includes {
+		switch {
+		case include.Kind == yaml.ScalarNode:
+			if strings.HasPrefix(include.Value, "http://") || strings.HasPrefix(include.Value, "https://") {
+				is.add(UnpinnedDependency, include.Line, include.Value)
+			}
+		case lookup(include, "remote") != nil:
+			remote := lookup(include, "remote")
+			is.add(UnpinnedDependency, remote.Line, remote.Value)
+		case lookup(include, "project") != nil && lookup(include, "ref") == nil:
+			project := lookup(include, "project")
+			is.add(UnpinnedDependency, project.Line, project.Value)
+		}
+	}
+}
+
+func checkCircleCI(root *yaml.Node) []*Issue {
+	var is issues
+	forEachEntry(lookup(root, "orbs"), func(_, orb *yaml.Node) {
+		// orbs can also be defined inline
+		if orb.Kind != yaml.ScalarNode || strings.HasPrefix(orb.Value, "circleci/") {
+			return
+		}
+		if at := strings.LastIndexByte(orb.Value, '@'); at < 0 || !fullVersion.MatchString(orb.Value[at+1:]) {
+			is.add(UnpinnedDependency, orb.Line, orb.Value)
+		}
+	})
+	walk(root, func(key, value *yaml.Node) {
+		if key.Value != "run" {
+			return
+		}
+		if value.Kind == yaml.MappingNode {
+			value = lookup(value, "command")
+		}
+		if value != nil && value.Kind == yaml.ScalarNode {
+			is.checkScriptNode(value)
+		}
+	})
+	return is
+}
+
+func checkBuildkite(root *yaml.Node) []*Issue {
+	var is issues
+	walk(root, func(key, value *yaml.Node) {
+		switch key.Value {
+		case "command", "commands":
+			is.checkScriptNode(value)
+		case "plugins":
+			for _, plugin := range elements(value) {
+				if plugin.Kind == yaml.MappingNode {
+					forEachEntry(plugin, func(name, _ *yaml.Node) {
+						is.checkBuildkitePlugin(name)
+					})
+				} else {
+					is.checkBuildkitePlugin(plugin)
+				}
+			}
+		}
+	})
+	return is
+}
+
+// Plugins without a version use the latest commit of the plugin
+func (is *issues) checkBuildkitePlugin(name *yaml.Node) {
+	if name.Kind == yaml.ScalarNode && !strings.Contains(name.Value, "#") {
+		is.add(UnpinnedDependency, name.Line, name.Value)
+	}
+}
+
+func checkJenkinsfile(content []byte) []*Issue {
+	var is issues
+	for i, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "//") {
+			continue
+		}
+		// shared libraries without a version use the default branch
+		if m := jenkinsLibrary.FindStringSubmatch(line); m != nil {
+			for _, lib := range quoted.FindAllStringSubmatch(m[1], -1) {
+				if !strings.Contains(lib[1], "@") {
+					is.add(UnpinnedDependency, i+1, lib[1])
+				}
+			}
+		}
+		is.checkScript(line, i+1)
+	}
+	return is
+}
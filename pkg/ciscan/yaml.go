@@ -0,0 +1,100 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ciscan
+
+import "gopkg.in/yaml.v3"
+
+func resolve(n *yaml.Node) *yaml.Node {
+	if n != nil && n.Kind == yaml.AliasNode {
+		return n.Alias
+	}
+	return n
+}
+
+// Returns the value of key in a mapping, or nil
+func lookup(n *yaml.Node, key string) *yaml.Node {
+	n = resolve(n)
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return resolve(n.Content[i+1])
+		}
+	}
+	return nil
+}
+
+// Call fn for each key and value of a mapping
+func forEachEntry(n *yaml.Node, fn func(key, value *yaml.Node)) {
+	n = resolve(n)
+	if n == nil || n.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		fn(n.Content[i], resolve(n.Content[i+1]))
+	}
+}
+
+// Returns the elements of a sequence
+func elements(n *yaml.Node) []*yaml.Node {
+	n = resolve(n)
+	if n == nil || n.Kind != yaml.SequenceNode {
+		return nil
+	}
+	r := make([]*yaml.Node, len(n.Content))
+	for i, e := range n.Content {
+		r[i] = resolve(e)
+	}
+	return r
+}
+
+// Returns the strings of a node that's either a string or a list of
+// strings
+func scalars(n *yaml.Node) []*yaml.Node {
+	n = resolve(n)
+	if n == nil {
+		return nil
+	}
+	if n.Kind == yaml.ScalarNode {
+		return []*yaml.Node{n}
+	}
+	var r []*yaml.Node
+	for _, e := range elements(n) {
+		if e.Kind == yaml.ScalarNode {
+			r = append(r, e)
+		}
+	}
+	return r
+}
+
+// Call fn for every key and value of every mapping under n
+func walk(n *yaml.Node, fn func(key, value *yaml.Node)) {
+	n = resolve(n)
+	if n == nil {
+		return
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		forEachEntry(n, func(key, value *yaml.Node) {
+			fn(key, value)
+			walk(value, fn)
+		})
+	case yaml.SequenceNode:
+		for _, e := range n.Content {
+			walk(e, fn)
+		}
+	}
+}
@@ -14,14 +14,33 @@
 
 package exit
 
+import "sync"
+
 // Exit code and message.  The root command will look at these and
 // log the error and exit with the code when a command completes
 var (
 	Code int
 	Func func()
+
+	lock sync.Mutex
 )
 
 func AddFunc(f func()) {
+	lock.Lock()
+	defer lock.Unlock()
+	addFunc(f)
+}
+
+// Set the exit code and add a function that logs why the command
+// failed.  This is safe to call from tools that run concurrently.
+func Fail(code int, f func()) {
+	lock.Lock()
+	defer lock.Unlock()
+	Code = code
+	addFunc(f)
+}
+
+func addFunc(f func()) {
 	g := Func
 	Func = func() {
 		f()
@@ -0,0 +1,104 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type ansibleDetector int
+
+var _ FileDetector = ansibleDetector(0)
+
+// Returns the role directory if path is tasks/main.yml or meta/main.yml
+// of a role
+func getAnsibleRole(path string) string {
+	base := filepath.Base(path)
+	if base != "main.yml" && base != "main.yaml" {
+		return ""
+	}
+	dir := filepath.Dir(path)
+	switch filepath.Base(dir) {
+	case "tasks", "meta":
+		return filepath.Dir(dir)
+	}
+	return ""
+}
+
+func (ansibleDetector) DetectFileName(m *Manifest, path string) ContentDetector {
+	if strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") {
+		return ansibleDetector(0)
+	}
+	return nil
+}
+
+func (ansibleDetector) DetectContent(m *Manifest, path string, buf []byte) {
+	if role := getAnsibleRole(path); role != "" {
+		// roles are in a roles directory, or have galaxy metadata
+		if filepath.Base(filepath.Dir(role)) == "roles" {
+			m.AnsibleRoles.Add(role)
+			return
+		}
+		if filepath.Base(filepath.Dir(path)) == "meta" && decodeYAMLMap(buf)["galaxy_info"] != nil {
+			m.AnsibleRoles.Add(role)
+			return
+		}
+	}
+	if isAnsiblePlaybook(buf) {
+		m.AnsiblePlaybooks.Add(path)
+	}
+}
+
+// A playbook is a list of plays, each of which has hosts, or imports
+// another playbook
+func isAnsiblePlaybook(buf []byte) bool {
+	if !mightBeAnsiblePlaybook(buf) {
+		return false
+	}
+	var plays []map[string]interface{}
+	if err := yaml.Unmarshal(buf, &plays); err != nil || len(plays) == 0 {
+		return false
+	}
+	for _, play := range plays {
+		_, hosts := play["hosts"]
+		_, imports := play["import_playbook"]
+		_, ansibleImports := play["ansible.builtin.import_playbook"]
+		if !hosts && !imports && !ansibleImports {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns false if buf can't be a playbook, without decoding it.  A
+// playbook mentions hosts or import_playbook, and its first line that
+// isn't a comment or a document marker starts a top level sequence.
+func mightBeAnsiblePlaybook(buf []byte) bool {
+	if !bytes.Contains(buf, []byte("hosts:")) && !bytes.Contains(buf, []byte("import_playbook")) {
+		return false
+	}
+	for _, line := range bytes.Split(buf, []byte("\n")) {
+		line = bytes.TrimRight(line, " \t\r")
+		if len(line) == 0 || line[0] == '#' || line[0] == '%' || bytes.HasPrefix(line, []byte("---")) {
+			continue
+		}
+		return line[0] == '-' && (len(line) == 1 || line[1] == ' ')
+	}
+	return false
+}
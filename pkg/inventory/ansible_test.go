@@ -0,0 +1,49 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnsible(t *testing.T) {
+	assert := assert.New(t)
+	m := &Manifest{}
+	m.scan("testdata", ansibleDetector(0))
+	assert.ElementsMatch(m.AnsiblePlaybooks.Values(),
+		[]string{filepath.FromSlash("ansible/site.yml"), filepath.FromSlash("ansible/webservers.yml")})
+	assert.ElementsMatch(m.AnsibleRoles.Values(),
+		[]string{filepath.FromSlash("ansible/roles/web"), filepath.FromSlash("ansible/galaxy-role")})
+}
+
+func TestIsAnsiblePlaybook(t *testing.T) {
+	assert := assert.New(t)
+	assert.True(isAnsiblePlaybook([]byte("- hosts: all\n  tasks: []\n")))
+	assert.False(isAnsiblePlaybook([]byte("- name: a task\n  ping:\n")))
+	assert.False(isAnsiblePlaybook([]byte("hosts: all\n")))
+	assert.False(isAnsiblePlaybook([]byte("[]")))
+	assert.True(isAnsiblePlaybook([]byte("# site\n---\n- import_playbook: web.yml\n")))
+}
+
+func TestMightBeAnsiblePlaybook(t *testing.T) {
+	assert := assert.New(t)
+	assert.True(mightBeAnsiblePlaybook([]byte("---\n-\n  hosts: all\n")))
+	assert.False(mightBeAnsiblePlaybook([]byte("- name: a task\n  ping:\n")))
+	assert.False(mightBeAnsiblePlaybook([]byte("plays:\n  - hosts: all\n")))
+	assert.False(mightBeAnsiblePlaybook([]byte("  - hosts: all\n")))
+}
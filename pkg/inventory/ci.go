@@ -36,6 +36,7 @@ func (cidetector) DetectFileName(m *Manifest, path string) ContentDetector {
 	switch path {
 	case "Jenkinsfile":
 		m.CISystems.Add("jenkins")
+		m.CIConfigFiles.Add(path)
 	case "azure-pipelines.yml":
 		m.CISystems.Add("azure")
 	case ".travis.yml":
@@ -44,10 +45,21 @@ func (cidetector) DetectFileName(m *Manifest, path string) ContentDetector {
 		m.CISystems.Add("drone")
 	case ".gitlab-ci.yml":
 		m.CISystems.Add("gitlab")
+		m.CIConfigFiles.Add(path)
+	case "buildkite.yml", "buildkite.yaml":
+		m.CIConfigFiles.Add(path)
 	}
 	if strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") {
-		if filepath.Dir(path) == filepath.Join(".github", "workflows") {
+		switch filepath.Dir(path) {
+		case filepath.Join(".github", "workflows"):
 			m.CISystems.Add("github")
+			m.CIConfigFiles.Add(path)
+		case ".circleci":
+			if base := filepath.Base(path); base == "config.yml" || base == "config.yaml" {
+				m.CIConfigFiles.Add(path)
+			}
+		case ".buildkite":
+			m.CIConfigFiles.Add(path)
 		}
 	}
 	return nil
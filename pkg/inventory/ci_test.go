@@ -28,6 +28,10 @@ func TestCI(t *testing.T) {
 	assert.ElementsMatch(m.CISystems.Values(), []string{
 		"github", "drone", "gitlab", "circleci", "jenkins", "travis", "azure",
 	})
+	assert.ElementsMatch(m.CIConfigFiles.Values(), []string{
+		filepath.FromSlash(".github/workflows/main.yml"), ".gitlab-ci.yml",
+		filepath.FromSlash(".circleci/config.yml"), "Jenkinsfile",
+	})
 	m.CISystems.Reset()
 	m.scan("testdata", cidetector(0))
 	if m.CISystems.Len() != 0 {
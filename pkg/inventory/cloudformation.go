@@ -30,6 +30,14 @@ func (cloudformationDetector) DetectFileName(m *Manifest, path string) ContentDe
 }
 
 func (cloudformationDetector) DetectContent(m *Manifest, path string, buf []byte) {
+	// SAM templates are cloudformation templates with the serverless
+	// transform, and don't need AWSTemplateFormatVersion
+	for _, transform := range decodeStrings(path, buf, "Transform") {
+		if strings.HasPrefix(transform, "AWS::Serverless") {
+			m.SAMTemplates.Add(path)
+			return
+		}
+	}
 	d := decodeDocument(path, buf)
 	if _, ok := d["AWSTemplateFormatVersion"]; ok {
 		m.CloudformationFiles.Add(path)
@@ -19,20 +19,31 @@ import "testing"
 func TestCloudformationDetector(t *testing.T) {
 	var testCases = []struct {
 		name, content string
-		match         bool
+		match, sam    bool
 	}{
 		{"foo.yaml", `---
-AWSTemplateFormatVersion: '2010-09-09'`, true},
+AWSTemplateFormatVersion: '2010-09-09'`, true, false},
 		{"foo.yml", `---
-AWSTemplateFormatVersion: '2010-09-09'`, true},
-		{"foo.yaml", "#AWSTemplateFormatVersion: '2010-09-09", false},
+AWSTemplateFormatVersion: '2010-09-09'`, true, false},
+		{"foo.yaml", "#AWSTemplateFormatVersion: '2010-09-09", false, false},
 		{"foo.json", `{ "AWSTemplateFormatVersion" :
-		"2010-09-09", "bar": 1`, true},
+		"2010-09-09", "bar": 1`, true, false},
+		{"template.yaml", `---
+Transform: AWS::Serverless-2016-10-31
+Resources: {}`, true, true},
+		{"template.json", `{ "AWSTemplateFormatVersion": "2010-09-09",
+		"Transform": ["AWS::Serverless-2016-10-31"] }`, true, true},
 	}
 	d := cloudformationDetector(0)
 	for _, tc := range testCases {
 		m := &Manifest{}
 		d.DetectContent(m, tc.name, []byte(tc.content))
+		if tc.sam {
+			if m.SAMTemplates.Len() != 1 || m.CloudformationFiles.Len() != 0 {
+				t.Error(tc)
+			}
+			continue
+		}
 		if tc.match && (m.CloudformationFiles.Len() != 1 || m.CloudformationFiles.Get(0) != tc.name) {
 			t.Error(tc)
 		} else if !tc.match && m.CloudformationFiles.Len() != 0 {
@@ -32,10 +32,24 @@ func decodeJSON(buf []byte) map[string]string {
 	return r
 }
 
-func decodeYAML(buf []byte) map[string]string {
+// Returns the top-level keys of a JSON object
+func decodeJSONKeys(buf []byte) map[string]bool {
+	r := map[string]bool{}
+	for k := range gjson.ParseBytes(buf).Map() {
+		r[k] = true
+	}
+	return r
+}
+
+func decodeYAMLMap(buf []byte) map[string]interface{} {
 	var m map[string]interface{}
 	// truncated yaml is still mostly yaml, but we'll just ignore errors
 	_ = yaml.Unmarshal(buf, &m)
+	return m
+}
+
+func decodeYAML(buf []byte) map[string]string {
+	m := decodeYAMLMap(buf)
 	r := map[string]string{}
 	for k, v := range m {
 		if s, ok := v.(string); ok {
@@ -53,3 +67,40 @@ func decodeDocument(name string, buf []byte) map[string]string {
 		return decodeJSON(buf)
 	}
 }
+
+// Returns the value of a top-level key that's either a string or a
+// list of strings.  The key must not contain gjson path characters.
+func decodeStrings(name string, buf []byte, key string) []string {
+	var values []string
+	if strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml") {
+		return toStrings(decodeYAMLMap(buf)[key])
+	}
+	v := gjson.GetBytes(buf, key)
+	if v.IsArray() {
+		for _, e := range v.Array() {
+			if e.Type == gjson.String {
+				values = append(values, e.Str)
+			}
+		}
+	} else if v.Type == gjson.String {
+		values = append(values, v.Str)
+	}
+	return values
+}
+
+// Returns a decoded YAML value that's either a string or a list of strings
+// as a list of strings
+func toStrings(v interface{}) []string {
+	var values []string
+	switch v := v.(type) {
+	case string:
+		values = append(values, v)
+	case []interface{}:
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
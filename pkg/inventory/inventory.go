@@ -15,21 +15,23 @@
 package inventory
 
 import (
-	"os"
-	"path/filepath"
-
-	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 )
 
 type Manifest struct {
-	root                          string
+	root    string
+	options *Options
+	// the kustomization directories and the local resources they use
+	kustomizations map[string][]string
+	// the kustomization directories used by another kustomization
+	kustomizeReferenced           util.StringSet
 	TerraformRootModules          util.StringSet `json:"terraform_root_modules"`
 	TerraformModules              util.StringSet `json:"terraform_modules"`
 	CloudformationFiles           util.StringSet `json:"cloudformation_files"`
 	HelmCharts                    util.StringSet `json:"helm_charts"`
 	KubernetesManifestDirectories util.StringSet `json:"kubernetes_manifest_directories"`
 	CISystems                     util.StringSet `json:"ci_systems"`
+	CIConfigFiles                 util.StringSet `json:"ci_config_files"`
 	DockerDirectories             util.StringSet `json:"docker_directories"`
 	GODirectories                 util.StringSet `json:"go_directories"`
 	PythonDirectories             util.StringSet `json:"python_directories"`
@@ -37,6 +39,13 @@ type Manifest struct {
 	JavaDirectories               util.StringSet `json:"java_directories"`
 	RubyDirectories               util.StringSet `json:"ruby_directories"`
 	CDKDirectories                util.StringSet `json:"cdk_directories"`
+	SAMTemplates                  util.StringSet `json:"sam_templates"`
+	ServerlessProjects            util.StringSet `json:"serverless_projects"`
+	PulumiProjects                util.StringSet `json:"pulumi_projects"`
+	AnsiblePlaybooks              util.StringSet `json:"ansible_playbooks"`
+	AnsibleRoles                  util.StringSet `json:"ansible_roles"`
+	KustomizeBases                util.StringSet `json:"kustomize_bases"`
+	KustomizeOverlays             util.StringSet `json:"kustomize_overlays"`
 }
 
 type FileDetector interface {
@@ -69,76 +78,56 @@ func (m *Manifest) getDetectors(detectors []interface{}) (fds []FileDetector, dd
 	return
 }
 
-func (m *Manifest) scan(root string, detectors ...interface{}) {
-	root, _ = filepath.Abs(root)
-	fileDetectors, dirDetectors := m.getDetectors(detectors)
-	_ = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			log.Warnf("Could not scan {info:%s}: {warning:%s}", path, err)
-			return nil
-		}
-		if info.IsDir() && info.Name() == ".git" {
-			// skip .git directory
-			return filepath.SkipDir
-		}
-		if isdir := info.IsDir(); isdir || info.Mode().IsRegular() {
-			relpath := path
-			if filepath.IsAbs(relpath) {
-				relpath, _ = filepath.Rel(root, relpath)
-			}
-			var cds []ContentDetector
-			if isdir {
-				for _, dd := range dirDetectors {
-					dd.DetectDirName(m, relpath)
-				}
-			} else {
-				for _, fd := range fileDetectors {
-					if cd := fd.DetectFileName(m, relpath); cd != nil {
-						cds = append(cds, cd)
-					}
-				}
-			}
-			if len(cds) > 0 {
-				buf, err := os.ReadFile(path)
-				if err != nil {
-					log.Warnf("Could not read {info:%s}: {warning:%s}", path, err)
-					return nil
-				}
-				if len(buf) > 0 {
-					for _, d := range cds {
-						d.DetectContent(m, relpath, buf)
-					}
-				}
-			}
-		}
-		return nil
-	})
-	for _, d := range detectors {
-		if fd, ok := d.(FinalizeDetector); ok {
-			fd.FinalizeDetection(m)
-		}
+func getAllDetectors() []interface{} {
+	return []interface{}{
+		cloudformationDetector(0),
+		kubernetesDetector(0),
+		cidetector(0),
+		dockerDetector(0),
+		&terraformDetector{},
+		goDetector(),
+		pythonDetector(),
+		javaAntMavenDetector(),
+		javaGradleDetector(),
+		nodeDetector(),
+		rubyDetector(),
+		cdkDetector(),
+		serverlessDetector(0),
+		pulumiDetector(0),
+		ansibleDetector(0),
 	}
 }
 
 func Do(root string) *Manifest {
-	return cache.Get(root, func(dir string) interface{} {
+	return DoWithOptions(root, nil)
+}
+
+// Returns the (cached) manifest of root, scanning it with opts.  If
+// opts is nil then the default options are used.
+func DoWithOptions(root string, opts *Options) *Manifest {
+	scan := func(string) interface{} {
 		m := &Manifest{
-			root: root,
+			root:    root,
+			options: opts,
 		}
-		m.scan(root,
-			cloudformationDetector(0),
-			kubernetesDetector(0),
-			cidetector(0),
-			dockerDetector(0),
-			&terraformDetector{},
-			goDetector(),
-			pythonDetector(),
-			javaAntMavenDetector(),
-			javaGradleDetector(),
-			nodeDetector(),
-			rubyDetector(),
-			cdkDetector(),
-		)
+		m.scan(root, getAllDetectors()...)
 		return m
-	}).(*Manifest)
+	}
+	key, ok := opts.getCacheKey(root)
+	if !ok {
+		return scan(root).(*Manifest)
+	}
+	return cache.Get(key, scan).(*Manifest)
+}
+
+// Returns the serverless, SAM, pulumi and ansible projects in a list
+// of files relative to root, such as the files that git tracks, instead
+// of walking root.  Only the detectors for those projects are run.
+func ScanProjectFiles(root string, files []string) *Manifest {
+	m := &Manifest{
+		root: root,
+	}
+	m.scanFiles(root, files, cloudformationDetector(0), serverlessDetector(0),
+		pulumiDetector(0), ansibleDetector(0))
+	return m
 }
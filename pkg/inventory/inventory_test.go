@@ -15,3 +15,18 @@ func TestInventory(t *testing.T) {
 		assert.ElementsMatch(m.TerraformRootModules.Values(), []string{"tf/r1", "tf/r1j", "tf/r2"})
 	}
 }
+
+func TestScanProjectFiles(t *testing.T) {
+	assert := assert.New(t)
+	m := ScanProjectFiles("testdata", []string{
+		"sam/template.yaml", "sls/api/serverless.yml", "pulumi/infra/Pulumi.yaml",
+		"ansible/roles/web/tasks/main.yml", "ansible/webservers.yml", "tf/r1/aws.tf",
+	})
+	assert.ElementsMatch(m.SAMTemplates.Values(), []string{"sam/template.yaml"})
+	assert.ElementsMatch(m.ServerlessProjects.Values(), []string{"sls/api"})
+	assert.ElementsMatch(m.PulumiProjects.Values(), []string{"pulumi/infra"})
+	assert.ElementsMatch(m.AnsibleRoles.Values(), []string{"ansible/roles/web"})
+	assert.ElementsMatch(m.AnsiblePlaybooks.Values(), []string{"ansible/webservers.yml"})
+	assert.Equal(0, m.TerraformRootModules.Len())
+	assert.Equal(0, m.CloudformationFiles.Len())
+}
@@ -16,15 +16,34 @@ package inventory
 
 import (
 	"path/filepath"
+	"sort"
 	"strings"
+
+	"github.com/soluble-ai/soluble-cli/pkg/util"
 )
 
 type kubernetesDetector int
 
-var _ FileDetector = kubernetesDetector(0)
+var (
+	_ FileDetector     = kubernetesDetector(0)
+	_ FinalizeDetector = kubernetesDetector(0)
+)
+
+func isKustomization(path string) bool {
+	switch filepath.Base(path) {
+	case "kustomization.yaml", "kustomization.yml", "Kustomization":
+		return true
+	}
+	return false
+}
+
+func isRemoteKustomizeResource(r string) bool {
+	return strings.Contains(r, "://") || strings.HasPrefix(r, "git@") ||
+		strings.HasPrefix(r, "github.com/")
+}
 
 func (kubernetesDetector) DetectFileName(m *Manifest, path string) ContentDetector {
-	if strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") ||
+	if isKustomization(path) || strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") ||
 		strings.HasSuffix(path, ".json") {
 		return kubernetesDetector(0)
 	}
@@ -32,6 +51,10 @@ func (kubernetesDetector) DetectFileName(m *Manifest, path string) ContentDetect
 }
 
 func (kubernetesDetector) DetectContent(m *Manifest, path string, content []byte) {
+	if isKustomization(path) {
+		detectKustomization(m, path, content)
+		return
+	}
 	d := decodeDocument(path, content)
 	if filepath.Base(path) == "Chart.yaml" && d["apiVersion"] != "" {
 		// assume this is a helm chart
@@ -39,12 +62,97 @@ func (kubernetesDetector) DetectContent(m *Manifest, path string, content []byte
 		return
 	}
 	if d["apiVersion"] != "" && d["kind"] != "" {
-		// ignore templates in helm charts
-		for _, ch := range m.HelmCharts.Values() {
-			if strings.HasPrefix(path, ch) {
-				return
+		m.KubernetesManifestDirectories.Add(filepath.Dir(path))
+	}
+}
+
+func detectKustomization(m *Manifest, path string, content []byte) {
+	dir := filepath.Dir(path)
+	d := decodeYAMLMap(content)
+	var resources []string
+	// bases are deprecated in favor of resources but are still common
+	for _, key := range []string{"resources", "bases", "components"} {
+		for _, r := range toStrings(d[key]) {
+			if !isRemoteKustomizeResource(r) {
+				resources = append(resources, filepath.Join(dir, filepath.FromSlash(r)))
 			}
 		}
-		m.KubernetesManifestDirectories.Add(filepath.Dir(path))
+	}
+	if m.kustomizations == nil {
+		m.kustomizations = map[string][]string{}
+	}
+	m.kustomizations[dir] = resources
+}
+
+// A kustomization that uses another kustomization is an overlay, and
+// the others are bases.  The files in overlays are patches rather than
+// complete kubernetes manifests, and the files in helm charts are
+// templates, so those directories aren't manifest directories.
+func (kubernetesDetector) FinalizeDetection(m *Manifest) {
+	dirs := make([]string, 0, len(m.kustomizations))
+	for dir := range m.kustomizations {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		overlay := false
+		for _, r := range m.kustomizations[dir] {
+			if _, ok := m.kustomizations[r]; ok {
+				overlay = true
+				m.kustomizeReferenced.Add(r)
+			}
+		}
+		if overlay {
+			m.KustomizeOverlays.Add(dir)
+		} else {
+			m.KustomizeBases.Add(dir)
+		}
+	}
+	if m.KustomizeOverlays.Len() > 0 || m.HelmCharts.Len() > 0 {
+		var manifestDirs util.StringSet
+		for _, dir := range m.KubernetesManifestDirectories.Values() {
+			if !isUnderAny(dir, m.KustomizeOverlays.Values()) && !isUnderAny(dir, m.HelmCharts.Values()) {
+				manifestDirs.Add(dir)
+			}
+		}
+		m.KubernetesManifestDirectories = manifestDirs
+	}
+}
+
+// Returns true if dir is one of dirs or is a sub-directory of one of them
+func isUnderAny(dir string, dirs []string) bool {
+	for _, d := range dirs {
+		if d == "." || dir == d || strings.HasPrefix(dir, d+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the kustomizations that can be built by themselves, which
+// are the overlays and the bases that aren't used by other kustomizations
+func (m *Manifest) GetKustomizeTargets() []string {
+	targets := append([]string{}, m.KustomizeOverlays.Values()...)
+	for _, base := range m.KustomizeBases.Values() {
+		if !m.kustomizeReferenced.Contains(base) {
+			targets = append(targets, base)
+		}
+	}
+	return targets
+}
+
+// Returns the local files and directories that the kustomization in
+// dir uses, including the ones used by the kustomizations it uses
+func (m *Manifest) GetKustomizeResources(dir string) []string {
+	var resources util.StringSet
+	m.addKustomizeResources(&resources, dir)
+	return resources.Values()
+}
+
+func (m *Manifest) addKustomizeResources(resources *util.StringSet, dir string) {
+	for _, r := range m.kustomizations[dir] {
+		if resources.Add(r) {
+			m.addKustomizeResources(resources, r)
+		}
 	}
 }
@@ -32,3 +32,14 @@ func TestHelm(t *testing.T) {
 		"t",
 	})
 }
+
+func TestKustomize(t *testing.T) {
+	assert := assert.New(t)
+	m := &Manifest{}
+	m.scan(filepath.Join("testdata", "kustomize"), kubernetesDetector(0))
+	assert.ElementsMatch(m.KustomizeBases.Values(), []string{"base", "standalone"})
+	assert.ElementsMatch(m.KustomizeOverlays.Values(), []string{filepath.FromSlash("overlays/prod")})
+	assert.ElementsMatch(m.KubernetesManifestDirectories.Values(), []string{"base", "standalone"})
+	assert.ElementsMatch(m.GetKustomizeTargets(), []string{filepath.FromSlash("overlays/prod"), "standalone"})
+	assert.Contains(m.GetKustomizeResources(filepath.FromSlash("overlays/prod")), "base")
+}
@@ -0,0 +1,39 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"path/filepath"
+)
+
+type pulumiDetector int
+
+var _ FileDetector = pulumiDetector(0)
+
+func (pulumiDetector) DetectFileName(m *Manifest, path string) ContentDetector {
+	switch filepath.Base(path) {
+	case "Pulumi.yaml", "Pulumi.yml":
+		return pulumiDetector(0)
+	}
+	return nil
+}
+
+func (pulumiDetector) DetectContent(m *Manifest, path string, buf []byte) {
+	// the runtime may be a string or a mapping with a name
+	d := decodeYAMLMap(buf)
+	if _, ok := d["runtime"]; ok && d["name"] != nil {
+		m.PulumiProjects.Add(filepath.Dir(path))
+	}
+}
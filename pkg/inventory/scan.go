@@ -0,0 +1,356 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	ignore "github.com/sabhiram/go-gitignore"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+)
+
+// The directories that typically hold vendored or generated files
+var DefaultSkipDirectories = []string{
+	"node_modules",
+	"bower_components",
+	"vendor",
+	".terraform",
+	".venv",
+	"venv",
+	"__pycache__",
+	".tox",
+	".aws-sam",
+	".serverless",
+}
+
+// Files larger than this aren't read to detect their content
+const DefaultMaxFileSize = 2 * 1024 * 1024
+
+// Options control how a directory is scanned.  Files that match a
+// .gitignore in the directory (or its sub-directories) are always
+// skipped.
+type Options struct {
+	// Directories with these names are skipped.  If nil then the
+	// DefaultSkipDirectories are skipped.
+	SkipDirectories []string
+	// Files larger than this aren't read.  If 0 then DefaultMaxFileSize
+	// is used.
+	MaxFileSize int64
+	// The number of files to read concurrently, defaults to the number
+	// of CPUs
+	Concurrency int
+	// If set, returns true if a file or directory should be skipped.  The
+	// path is relative to the directory being scanned.
+	Ignore func(path string) bool
+	// Identifies what Ignore skips, so that manifests scanned with the
+	// same Ignore can be cached.  If Ignore is set without an IgnoreID
+	// then the manifest isn't cached.
+	IgnoreID string
+}
+
+func (opts *Options) getSkipDirectories() []string {
+	if opts == nil || opts.SkipDirectories == nil {
+		return DefaultSkipDirectories
+	}
+	return opts.SkipDirectories
+}
+
+func (opts *Options) getMaxFileSize() int64 {
+	if opts == nil || opts.MaxFileSize <= 0 {
+		return DefaultMaxFileSize
+	}
+	return opts.MaxFileSize
+}
+
+func (opts *Options) getConcurrency() int {
+	if opts == nil || opts.Concurrency <= 0 {
+		return runtime.NumCPU()
+	}
+	return opts.Concurrency
+}
+
+// Returns the key of the manifest of root in the cache, or false if
+// the manifest can't be cached.  The key includes everything that
+// changes what the scan finds.
+func (opts *Options) getCacheKey(root string) (string, bool) {
+	if opts != nil && opts.Ignore != nil && opts.IgnoreID == "" {
+		return "", false
+	}
+	key := []string{root, strconv.FormatInt(opts.getMaxFileSize(), 10)}
+	if opts != nil {
+		key = append(key, opts.IgnoreID)
+	} else {
+		key = append(key, "")
+	}
+	key = append(key, opts.getSkipDirectories()...)
+	return strings.Join(key, "\x00"), true
+}
+
+func (opts *Options) isIgnored(path string) bool {
+	return opts != nil && opts.Ignore != nil && opts.Ignore(path)
+}
+
+// A file whose content needs to be looked at.  The content detectors
+// run concurrently, so each job collects its results in its own
+// manifest which is then merged into the main manifest.
+type contentJob struct {
+	path      string
+	relpath   string
+	detectors []ContentDetector
+	result    *Manifest
+}
+
+// The .gitignore files found while scanning, keyed by the directory
+// they're in
+type gitIgnores map[string]*ignore.GitIgnore
+
+func (gi gitIgnores) read(path, relpath string) {
+	f := filepath.Join(path, ".gitignore")
+	if _, err := os.Stat(f); err != nil {
+		return
+	}
+	ign, err := ignore.CompileIgnoreFile(f)
+	if err != nil {
+		log.Warnf("Could not read {info:%s}: {warning:%s}", f, err)
+		return
+	}
+	gi[relpath] = ign
+}
+
+func (gi gitIgnores) matches(relpath string, isdir bool) bool {
+	if len(gi) == 0 {
+		return false
+	}
+	p := filepath.ToSlash(relpath)
+	if isdir {
+		// patterns that end in / only match directories
+		p += "/"
+	}
+	for dir, ign := range gi {
+		rp := p
+		if dir != "." {
+			prefix := filepath.ToSlash(dir) + "/"
+			if !strings.HasPrefix(p, prefix) {
+				continue
+			}
+			rp = p[len(prefix):]
+		}
+		if ign.MatchesPath(rp) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Manifest) scan(root string, detectors ...interface{}) {
+	root, _ = filepath.Abs(root)
+	fileDetectors, dirDetectors := m.getDetectors(detectors)
+	skipDirectories := util.NewStringSetWithValues(m.options.getSkipDirectories())
+	ignores := gitIgnores{}
+	var jobs []*contentJob
+	_ = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			log.Warnf("Could not scan {info:%s}: {warning:%s}", path, err)
+			return nil
+		}
+		isdir := info.IsDir()
+		if isdir && info.Name() == ".git" {
+			// skip .git directory
+			return filepath.SkipDir
+		}
+		relpath, _ := filepath.Rel(root, path)
+		if relpath != "." {
+			if (isdir && skipDirectories.Contains(info.Name())) || ignores.matches(relpath, isdir) ||
+				m.options.isIgnored(relpath) {
+				if isdir {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+		if isdir {
+			ignores.read(path, relpath)
+			m.detectDirName(relpath, dirDetectors)
+		} else if info.Mode().IsRegular() {
+			if job := m.detectFileName(path, relpath, fileDetectors); job != nil {
+				jobs = append(jobs, job)
+			}
+		}
+		return nil
+	})
+	m.detectContent(jobs)
+	m.finalize(detectors)
+}
+
+// Run the detectors on a list of files relative to root
+func (m *Manifest) scanFiles(root string, files []string, detectors ...interface{}) {
+	fileDetectors, dirDetectors := m.getDetectors(detectors)
+	skipDirectories := util.NewStringSetWithValues(m.options.getSkipDirectories())
+	var (
+		dirs    util.StringSet
+		skipped util.StringSet
+		jobs    []*contentJob
+	)
+files:
+	for _, file := range files {
+		var parents []string
+		for dir := filepath.Dir(file); dir != "."; dir = filepath.Dir(dir) {
+			if skipped.Contains(dir) {
+				continue files
+			}
+			if skipDirectories.Contains(filepath.Base(dir)) {
+				skipped.Add(dir)
+				continue files
+			}
+			if dirs.Contains(dir) {
+				break
+			}
+			parents = append(parents, dir)
+		}
+		for i := len(parents) - 1; i >= 0; i-- {
+			dirs.Add(parents[i])
+			m.detectDirName(parents[i], dirDetectors)
+		}
+		if job := m.detectFileName(filepath.Join(root, file), file, fileDetectors); job != nil {
+			jobs = append(jobs, job)
+		}
+	}
+	m.detectContent(jobs)
+	m.finalize(detectors)
+}
+
+func (m *Manifest) detectDirName(relpath string, dirDetectors []DirDetector) {
+	for _, dd := range dirDetectors {
+		dd.DetectDirName(m, relpath)
+	}
+}
+
+// Run the file name detectors, returning a job to look at the content
+// of the file if any of them need to
+func (m *Manifest) detectFileName(path, relpath string, fileDetectors []FileDetector) *contentJob {
+	var cds []ContentDetector
+	for _, fd := range fileDetectors {
+		if cd := fd.DetectFileName(m, relpath); cd != nil {
+			cds = append(cds, cd)
+		}
+	}
+	if len(cds) == 0 {
+		return nil
+	}
+	return &contentJob{
+		path:      path,
+		relpath:   relpath,
+		detectors: cds,
+	}
+}
+
+// Run the content detection jobs concurrently, and then merge the
+// results in order
+func (m *Manifest) detectContent(jobs []*contentJob) {
+	maxFileSize := m.options.getMaxFileSize()
+	sem := make(chan struct{}, m.options.getConcurrency())
+	wg := &sync.WaitGroup{}
+	for _, job := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(job *contentJob) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			job.run(maxFileSize)
+		}(job)
+	}
+	wg.Wait()
+	for _, job := range jobs {
+		if job.result != nil {
+			m.merge(job.result)
+		}
+	}
+}
+
+func (job *contentJob) run(maxFileSize int64) {
+	buf, err := readFile(job.path, maxFileSize)
+	if err != nil {
+		if errors.Is(err, errFileTooLarge) {
+			log.Debugf("Skipping {info:%s} because it's larger than {info:%s}",
+				job.relpath, util.Size(uint64(maxFileSize)))
+		} else {
+			log.Warnf("Could not read {info:%s}: {warning:%s}", job.path, err)
+		}
+		return
+	}
+	if len(buf) > 0 {
+		job.result = &Manifest{}
+		for _, d := range job.detectors {
+			d.DetectContent(job.result, job.relpath, buf)
+		}
+	}
+}
+
+var errFileTooLarge = errors.New("file too large")
+
+func readFile(path string, maxFileSize int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxFileSize {
+		return nil, errFileTooLarge
+	}
+	return io.ReadAll(io.LimitReader(f, maxFileSize))
+}
+
+var stringSetType = reflect.TypeOf(util.StringSet{})
+
+// Add the values found in other to m
+func (m *Manifest) merge(other *Manifest) {
+	mv := reflect.ValueOf(m).Elem()
+	ov := reflect.ValueOf(other).Elem()
+	for i := 0; i < mv.NumField(); i++ {
+		if f := mv.Type().Field(i); f.Type == stringSetType && f.IsExported() {
+			ss := mv.Field(i).Addr().Interface().(*util.StringSet)
+			for _, v := range ov.Field(i).Addr().Interface().(*util.StringSet).Values() {
+				ss.Add(v)
+			}
+		}
+	}
+	for dir, resources := range other.kustomizations {
+		if m.kustomizations == nil {
+			m.kustomizations = map[string][]string{}
+		}
+		m.kustomizations[dir] = resources
+	}
+}
+
+func (m *Manifest) finalize(detectors []interface{}) {
+	for _, d := range detectors {
+		if fd, ok := d.(FinalizeDetector); ok {
+			fd.FinalizeDetection(m)
+		}
+	}
+}
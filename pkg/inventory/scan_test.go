@@ -0,0 +1,115 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+const testPod = "apiVersion: v1\nkind: Pod\n"
+
+func TestScanSkips(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeTestFiles(t, map[string]string{
+		".gitignore":              "build/\n*.generated.yaml\n",
+		"app/pod.yaml":            testPod,
+		"app/pod.generated.yaml":  testPod,
+		"build/pod.yaml":          testPod,
+		"node_modules/x/pod.yaml": testPod,
+		"nested/.gitignore":       "/out\n",
+		"nested/out/pod.yaml":     testPod,
+		"nested/ok/pod.yaml":      testPod,
+		"nested/ok/out/pod.yaml":  testPod,
+		"big/pod.yaml":            testPod + strings.Repeat("#\n", 100),
+		"excluded/pod.yaml":       testPod,
+	})
+	m := &Manifest{
+		options: &Options{
+			MaxFileSize: 100,
+			Ignore: func(path string) bool {
+				return path == "excluded"
+			},
+		},
+	}
+	m.scan(dir, kubernetesDetector(0))
+	assert.ElementsMatch(m.KubernetesManifestDirectories.Values(), []string{
+		"app", filepath.FromSlash("nested/ok"), filepath.FromSlash("nested/ok/out"),
+	})
+	m = &Manifest{
+		options: &Options{
+			SkipDirectories: []string{},
+			Concurrency:     1,
+		},
+	}
+	m.scan(dir, kubernetesDetector(0))
+	assert.Contains(m.KubernetesManifestDirectories.Values(), filepath.FromSlash("node_modules/x"))
+	assert.Contains(m.KubernetesManifestDirectories.Values(), "big")
+}
+
+func TestScanFilesSkips(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeTestFiles(t, map[string]string{
+		"app/pod.yaml":           testPod,
+		"vendor/k8s/pod.yaml":    testPod,
+		"vendor/k8s/svc/pod.yml": testPod,
+	})
+	m := &Manifest{}
+	m.scanFiles(dir, []string{"app/pod.yaml", "vendor/k8s/pod.yaml", "vendor/k8s/svc/pod.yml"},
+		kubernetesDetector(0))
+	assert.ElementsMatch(m.KubernetesManifestDirectories.Values(), []string{"app"})
+}
+
+func TestDoWithOptionsCache(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeTestFiles(t, map[string]string{
+		"app/pod.yaml":    testPod,
+		"vendor/pod.yaml": testPod,
+	})
+	m := DoWithOptions(dir, nil)
+	assert.ElementsMatch(m.KubernetesManifestDirectories.Values(), []string{"app"})
+	m = DoWithOptions(dir, &Options{SkipDirectories: []string{}})
+	assert.ElementsMatch(m.KubernetesManifestDirectories.Values(), []string{"app", "vendor"})
+	ignoreApp := &Options{
+		Ignore:   func(path string) bool { return path == "app" },
+		IgnoreID: "app",
+	}
+	m = DoWithOptions(dir, ignoreApp)
+	assert.Equal(0, m.KubernetesManifestDirectories.Len())
+	assert.Same(m, DoWithOptions(dir, ignoreApp))
+	assert.Same(DoWithOptions(dir, nil), DoWithOptions(dir, nil))
+	// without an IgnoreID the manifest isn't cached
+	ignoreApp.IgnoreID = ""
+	assert.NotSame(DoWithOptions(dir, ignoreApp), DoWithOptions(dir, ignoreApp))
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"path/filepath"
+)
+
+type serverlessDetector int
+
+var _ FileDetector = serverlessDetector(0)
+
+func (serverlessDetector) DetectFileName(m *Manifest, path string) ContentDetector {
+	switch filepath.Base(path) {
+	case "serverless.yml", "serverless.yaml", "serverless.json":
+		return serverlessDetector(0)
+	case "serverless.ts", "serverless.js":
+		// can't tell much from the code
+		m.ServerlessProjects.Add(filepath.Dir(path))
+	}
+	return nil
+}
+
+func (serverlessDetector) DetectContent(m *Manifest, path string, buf []byte) {
+	var service, provider bool
+	if ext := filepath.Ext(path); ext == ".yml" || ext == ".yaml" {
+		d := decodeYAMLMap(buf)
+		_, service = d["service"]
+		_, provider = d["provider"]
+	} else {
+		d := decodeJSONKeys(buf)
+		service, provider = d["service"], d["provider"]
+	}
+	if service && provider {
+		m.ServerlessProjects.Add(filepath.Dir(path))
+	}
+}
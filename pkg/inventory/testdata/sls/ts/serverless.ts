@@ -0,0 +1,5 @@
+const serverlessConfiguration = {
+  service: "ts",
+  provider: { name: "aws", runtime: "nodejs14.x" },
+};
+module.exports = serverlessConfiguration;
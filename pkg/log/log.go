@@ -16,6 +16,7 @@ package log
 
 import (
 	"fmt"
+	"io"
 	"sync"
 
 	"github.com/fatih/color"
@@ -68,6 +69,50 @@ func Warnf(template string, args ...interface{}) {
 	Log(Warning, template, args...)
 }
 
+// A Logger writes log messages to its own writer, so that the messages
+// of tasks that run at the same time can be kept apart.  A nil Logger,
+// or one without a writer, logs like Infof etc.
+type Logger struct {
+	Writer io.Writer
+}
+
+func NewLogger(w io.Writer) *Logger {
+	return &Logger{Writer: w}
+}
+
+func (l *Logger) Log(level int, template string, args ...interface{}) {
+	if l == nil || l.Writer == nil {
+		Log(level, template, args...)
+		return
+	}
+	if level <= Level {
+		s := colorize.SColorize("{secondary:[%s]} ", levelNames[level]) +
+			colorize.SColorize(template, args...)
+		if template[len(template)-1] != '\n' {
+			s += "\n"
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		_, _ = io.WriteString(l.Writer, s)
+	}
+}
+
+func (l *Logger) Infof(template string, args ...interface{}) {
+	l.Log(Info, template, args...)
+}
+
+func (l *Logger) Debugf(template string, args ...interface{}) {
+	l.Log(Debug, template, args...)
+}
+
+func (l *Logger) Errorf(template string, args ...interface{}) {
+	l.Log(Error, template, args...)
+}
+
+func (l *Logger) Warnf(template string, args ...interface{}) {
+	l.Log(Warning, template, args...)
+}
+
 type TempLevel struct {
 	orig int
 }
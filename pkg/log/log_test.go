@@ -48,3 +48,19 @@ func TestTemp(t *testing.T) {
 		t.Error(s)
 	}
 }
+
+func TestLogger(t *testing.T) {
+	w := bytes.Buffer{}
+	color.Output = &w
+	color.NoColor = true
+	lw := bytes.Buffer{}
+	NewLogger(&lw).Warnf("hello %s", "there")
+	var l *Logger
+	l.Infof("shared")
+	if s := lw.String(); s != "[ Warn] hello there\n" {
+		t.Error(s)
+	}
+	if s := w.String(); s != "[ Info] shared\n" {
+		t.Error(s)
+	}
+}
@@ -46,7 +46,9 @@ type PrintOpts struct {
 	Formatters          map[string]print.Formatter
 	ComputedColumns     map[string]print.ColumnFunction
 	DiffContextSize     int
-	outputSource        func() io.Writer
+	// If set, the command supports --format junit
+	JUnitColumns *print.JUnitColumns
+	outputSource func() io.Writer
 }
 
 var _ Interface = &PrintOpts{}
@@ -206,6 +208,14 @@ func (p *PrintOpts) GetPrinter() (print.Interface, error) {
 			Columns:     p.getEffectiveColumns(),
 			Formatters:  p.Formatters,
 		}, nil
+	case "junit":
+		if p.Path == nil || p.JUnitColumns == nil {
+			return nil, fmt.Errorf("this command does not support --format junit")
+		}
+		return &print.JUnitPrinter{
+			PathSupport:  p.getPathSupport(),
+			JUnitColumns: *p.JUnitColumns,
+		}, nil
 	default:
 		return nil, fmt.Errorf("this command does not support --format %s", p.OutputFormat)
 	}
@@ -221,13 +231,16 @@ func (p *PrintOpts) getPathSupport() print.PathSupport {
 	}
 }
 
-func (p *PrintOpts) PrintResult(result *jnode.Node) {
-	var w io.Writer
+// Returns the writer that results are printed to
+func (p *PrintOpts) GetOutputWriter() io.Writer {
 	if p.outputSource != nil {
-		w = p.outputSource()
-	} else {
-		w = os.Stdout
+		return p.outputSource()
 	}
+	return os.Stdout
+}
+
+func (p *PrintOpts) PrintResult(result *jnode.Node) {
+	w := p.GetOutputWriter()
 	printer, err := p.GetPrinter()
 	if err != nil {
 		log.Errorf("Cannot print results: {warning:%s}", err.Error())
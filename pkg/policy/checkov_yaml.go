@@ -6,7 +6,6 @@ import (
 	"path/filepath"
 
 	"github.com/hashicorp/go-multierror"
-	"github.com/soluble-ai/soluble-cli/pkg/assessments"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/checkov"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
@@ -34,6 +33,21 @@ func (h checkovYAMLType) Prepare(rule *Rule, target Target, dst string) error {
 	return os.WriteFile(filepath.Join(dst, fmt.Sprintf("%s-%s.yaml", target, rule.ID)), d, 0600)
 }
 
+func (checkovYAMLType) Unprepare(name string, d []byte) (*RuleFile, error) {
+	var ruleBody map[string]interface{}
+	if err := yaml.Unmarshal(d, &ruleBody); err != nil {
+		return nil, err
+	}
+	rf := &RuleFile{Name: "rule.yaml"}
+	if metadata, ok := ruleBody["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "id")
+		rf.Title, _ = metadata["name"].(string)
+	}
+	var err error
+	rf.Data, err = yaml.Marshal(ruleBody)
+	return rf, err
+}
+
 func (checkovYAMLType) readRule(rule *Rule, target Target) (map[string]interface{}, error) {
 	d, err := os.ReadFile(filepath.Join(rule.Path, string(target), "rule.yaml"))
 	if err != nil {
@@ -49,8 +63,7 @@ func (checkovYAMLType) readRule(rule *Rule, target Target) (map[string]interface
 func (h checkovYAMLType) Validate(rule *Rule) error {
 	var err error
 	for _, target := range rule.Targets {
-		_, terr := h.readRule(rule, target)
-		if terr != nil {
+		if terr := validateCheckovYAMLRule(rule, target); terr != nil {
 			err = multierror.Append(err, terr)
 		}
 	}
@@ -63,8 +76,8 @@ func (checkovYAMLType) GetTestRunner(target Target) tools.Single {
 	}
 }
 
-func (checkovYAMLType) FindRuleResult(findings assessments.Findings, id string) PassFail {
-	for _, finding := range findings {
+func (checkovYAMLType) FindRuleResult(result *tools.Result, id string) PassFail {
+	for _, finding := range result.Findings {
 		if finding.Tool["check_id"] == id {
 			return &finding.Pass
 		}
@@ -0,0 +1,227 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+// The structure of checkov YAML policies is described in
+// https://www.checkov.io/3.Custom%20Policies/YAML%20Custom%20Policies.html
+
+var checkovAttributeOperators = util.NewStringSetWithValues([]string{
+	"equals", "not_equals",
+	"equals_ignore_case", "not_equals_ignore_case",
+	"regex_match", "not_regex_match",
+	"exists", "not_exists",
+	"any",
+	"contains", "not_contains",
+	"within", "not_within",
+	"starting_with", "not_starting_with",
+	"ending_with", "not_ending_with",
+	"greater_than", "greater_than_or_equal",
+	"less_than", "less_than_or_equal",
+	"subset", "not_subset",
+	"intersects", "not_intersects",
+	"is_empty", "is_not_empty",
+	"is_true", "is_false",
+	"length_equals", "length_not_equals",
+	"length_greater_than", "length_greater_than_or_equal",
+	"length_less_than", "length_less_than_or_equal",
+	"jsonpath_equals", "jsonpath_not_equals",
+	"jsonpath_exists", "jsonpath_not_exists",
+	"range_includes", "range_not_includes",
+	"cidr_range_subset", "cidr_range_not_subset",
+})
+
+// Operators that don't compare against a value
+var checkovValuelessOperators = util.NewStringSetWithValues([]string{
+	"exists", "not_exists",
+	"is_empty", "is_not_empty",
+	"is_true", "is_false",
+	"jsonpath_exists", "jsonpath_not_exists",
+})
+
+var checkovConnectionOperators = util.NewStringSetWithValues([]string{
+	"exists", "not_exists", "one_exists",
+})
+
+var checkovBlockKeys = map[string][]string{
+	"attribute":  {"cond_type", "resource_types", "attribute", "operator", "value"},
+	"connection": {"cond_type", "resource_types", "connected_resource_types", "operator"},
+	"filter":     {"cond_type", "attribute", "operator", "value"},
+}
+
+// The form of resource types for each target.  This only checks the
+// shape of a resource type, not that it exists, since the types
+// depend on the provider versions and Kubernetes kinds can't be
+// enumerated because of custom resources.  A mismatch is only a
+// warning for the same reason.
+var checkovResourceTypePatterns = map[Target]*regexp.Regexp{
+	Terraform:      regexp.MustCompile(`^[a-z][a-z0-9]*_[a-z0-9_]+$`),
+	Cloudformation: regexp.MustCompile(`^(?:(?:AWS|Alexa)::\w+::\w+(?:::\w+)?|Custom::\w+)$`),
+	Kubernetes:     regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`),
+}
+
+type checkovValidator struct {
+	diagnostics
+	target Target
+}
+
+func validateCheckovYAMLRule(rule *Rule, target Target) error {
+	v := &checkovValidator{
+		diagnostics: diagnostics{path: filepath.Join(rule.Path, string(target), "rule.yaml")},
+		target:      target,
+	}
+	n, err := readYAMLMapping(v.path)
+	if err != nil {
+		return err
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		switch key := n.Content[i]; key.Value {
+		case "metadata", "scope":
+			if n.Content[i+1].Kind != yaml.MappingNode {
+				v.add(n.Content[i+1], "%s must be a mapping", key.Value)
+			}
+		case "definition":
+		default:
+			v.add(key, "unknown attribute %s", key.Value)
+		}
+	}
+	if _, def := getMappingEntry(n, "definition"); def != nil {
+		v.validateDefinition(def)
+	} else {
+		v.add(n, "must have a definition")
+	}
+	return v.err
+}
+
+func (v *checkovValidator) validateDefinition(n *yaml.Node) {
+	if n.Kind != yaml.MappingNode {
+		v.add(n, "the definition must be a mapping")
+		return
+	}
+	for _, op := range []string{"and", "or"} {
+		key, list := getMappingEntry(n, op)
+		if key == nil {
+			continue
+		}
+		if len(n.Content) != 2 {
+			v.add(key, "%s must be the only attribute of its block", op)
+		}
+		if list.Kind != yaml.SequenceNode || len(list.Content) == 0 {
+			v.add(list, "%s must be a non-empty list of blocks", op)
+			return
+		}
+		for _, e := range list.Content {
+			v.validateDefinition(e)
+		}
+		return
+	}
+	v.validateBlock(n)
+}
+
+func (v *checkovValidator) validateBlock(n *yaml.Node) {
+	_, condType := getMappingEntry(n, "cond_type")
+	if condType == nil {
+		v.add(n, "must have a cond_type, or be an and or or block")
+		return
+	}
+	keys := checkovBlockKeys[condType.Value]
+	if keys == nil {
+		v.add(condType, "unknown cond_type %s, must be one of %s", condType.Value,
+			strings.Join(sortedKeys(checkovBlockKeys), ", "))
+		return
+	}
+	for i := 0; i < len(n.Content); i += 2 {
+		if key := n.Content[i]; !util.StringSliceContains(keys, key.Value) {
+			v.add(key, "unknown attribute %s in %s block", key.Value, condType.Value)
+		}
+	}
+	for _, key := range keys {
+		if _, value := getMappingEntry(n, key); value == nil && key != "value" {
+			v.add(n, "%s block must have %s", condType.Value, key)
+		}
+	}
+	_, operator := getMappingEntry(n, "operator")
+	_, value := getMappingEntry(n, "value")
+	switch condType.Value {
+	case "attribute":
+		if operator != nil {
+			switch {
+			case !checkovAttributeOperators.Contains(operator.Value):
+				v.add(operator, "unknown operator %s", operator.Value)
+			case checkovValuelessOperators.Contains(operator.Value) && value != nil:
+				v.add(value, "operator %s does not take a value", operator.Value)
+			case !checkovValuelessOperators.Contains(operator.Value) && value == nil:
+				v.add(operator, "operator %s requires a value", operator.Value)
+			}
+		}
+		if _, rt := getMappingEntry(n, "resource_types"); rt != nil {
+			v.validateResourceTypes(rt, "resource_types")
+		}
+	case "connection":
+		if operator != nil && !checkovConnectionOperators.Contains(operator.Value) {
+			v.add(operator, "unknown connection operator %s", operator.Value)
+		}
+		for _, key := range []string{"resource_types", "connected_resource_types"} {
+			if _, rt := getMappingEntry(n, key); rt != nil {
+				v.validateResourceTypes(rt, key)
+			}
+		}
+	case "filter":
+		if _, attribute := getMappingEntry(n, "attribute"); attribute != nil && attribute.Value != "resource_type" {
+			v.add(attribute, "filter attribute must be resource_type")
+		}
+		if operator != nil && operator.Value != "within" {
+			v.add(operator, "filter operator must be within")
+		}
+		if value == nil {
+			v.add(n, "filter block must have value")
+		} else {
+			v.validateResourceTypes(value, "value")
+		}
+	}
+}
+
+func (v *checkovValidator) validateResourceTypes(n *yaml.Node, name string) {
+	if isStringNode(n) && n.Value == "all" {
+		return
+	}
+	pattern := checkovResourceTypePatterns[v.target]
+	for _, rt := range v.getStrings(n, name) {
+		if rt.Value == "all" {
+			continue
+		}
+		if pattern != nil && !pattern.MatchString(rt.Value) {
+			v.warn(rt, "%s does not look like a %s resource type", rt.Value, v.target)
+		}
+	}
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+)
+
+// The templates for new rules are in templates/<rule-type>/<target>.
+// Files that end in .tmpl are rendered as go templates.
+//
+//go:embed templates
+var templates embed.FS
+
+var ruleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+type ruleTemplateData struct {
+	ID      string
+	Name    string
+	Title   string
+	Package string
+}
+
+// Returns the rule type with name, or nil
+func GetRuleType(name string) RuleType {
+	return getRuleType(name)
+}
+
+// Returns the targets that new rules can be created for
+func GetTemplateTargets(ruleType RuleType) []Target {
+	entries, _ := templates.ReadDir(path.Join("templates", ruleTypeName(ruleType)))
+	var targets []Target
+	for _, e := range entries {
+		if e.IsDir() {
+			targets = append(targets, Target(e.Name()))
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i] < targets[j] })
+	return targets
+}
+
+// Create a new rule with sample tests from a template.  If the rule
+// already exists, the target is added to it.
+func (m *Manager) CreateRule(ruleType RuleType, target Target, name string) (*Rule, error) {
+	if !ruleNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("the rule name %s must be lower case letters, digits, or underscores", name)
+	}
+	templateDir := path.Join("templates", ruleTypeName(ruleType), string(target))
+	if _, err := fs.Stat(templates, templateDir); err != nil {
+		var names []string
+		for _, t := range GetTemplateTargets(ruleType) {
+			names = append(names, string(t))
+		}
+		return nil, fmt.Errorf("%s rules can't be created for %s, the supported targets are %s",
+			ruleTypeName(ruleType), target, strings.Join(names, ", "))
+	}
+	ruleDir := filepath.Join(m.Dir, "policies", ruleTypeName(ruleType), name)
+	if util.DirExists(filepath.Join(ruleDir, string(target))) {
+		return nil, fmt.Errorf("%s already exists", filepath.Join(ruleDir, string(target)))
+	}
+	data := &ruleTemplateData{
+		ID:      getRuleID(ruleType, name),
+		Name:    name,
+		Title:   getRuleTitle(name),
+		Package: fmt.Sprintf("rules.%s", name),
+	}
+	if !util.FileExists(filepath.Join(ruleDir, "metadata.yaml")) {
+		if err := renderTemplate("templates/metadata.yaml.tmpl", filepath.Join(ruleDir, "metadata.yaml"), data); err != nil {
+			return nil, err
+		}
+	}
+	err := fs.WalkDir(templates, templateDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel := strings.TrimPrefix(p, templateDir+"/")
+		return renderTemplate(p, filepath.Join(ruleDir, string(target), filepath.FromSlash(rel)), data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m.LoadRule(ruleType, ruleDir)
+}
+
+func renderTemplate(name, dest string, data *ruleTemplateData) error {
+	d, err := templates.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(name, ".tmpl") {
+		dest = strings.TrimSuffix(dest, ".tmpl")
+		t, err := template.New(path.Base(name)).Parse(string(d))
+		if err != nil {
+			return err
+		}
+		buf := &bytes.Buffer{}
+		if err := t.Execute(buf, data); err != nil {
+			return err
+		}
+		d = buf.Bytes()
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(dest, d, 0600)
+}
+
+func getRuleTitle(name string) string {
+	title := strings.ReplaceAll(name, "_", " ")
+	return strings.ToUpper(title[0:1]) + title[1:]
+}
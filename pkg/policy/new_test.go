@@ -0,0 +1,63 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateRule(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	m := NewManager(dir)
+	for _, ruleType := range allRuleTypes {
+		targets := GetTemplateTargets(ruleType)
+		assert.NotEmpty(targets, ruleTypeName(ruleType))
+		for _, target := range targets {
+			rule, err := m.CreateRule(ruleType, target, "team_tag")
+			if !assert.NoError(err, "%s %s", ruleTypeName(ruleType), target) {
+				continue
+			}
+			assert.Equal(getRuleID(ruleType, "team_tag"), rule.ID)
+			assert.Equal("Team tag", rule.Metadata["title"])
+			assert.Contains(rule.Targets, target)
+			targetDir := filepath.Join(rule.Path, string(target))
+			assert.True(util.DirExists(filepath.Join(targetDir, "tests", "pass")))
+			assert.True(util.DirExists(filepath.Join(targetDir, "tests", "fail")))
+			_, dt, dr, dtarget, err := DetectPolicy(targetDir)
+			assert.NoError(err)
+			assert.Equal(ruleType, dt)
+			if assert.NotNil(dr) {
+				assert.Equal(rule.ID, dr.ID)
+			}
+			assert.Equal(target, dtarget)
+		}
+	}
+	assert.NoError(m.LoadAllRules())
+	assert.NoError(m.ValidateRules())
+	for _, rule := range m.Rules[Rego] {
+		assert.NoError(m.TestRule(rule))
+	}
+	_, err := m.CreateRule(CheckovYAML, Terraform, "team_tag")
+	assert.ErrorContains(err, "already exists")
+	_, err = m.CreateRule(CheckovYAML, Target("helm"), "team_tag")
+	assert.ErrorContains(err, "supported targets are cloudformation, kubernetes, terraform")
+	_, err = m.CreateRule(CheckovYAML, Terraform, "Team-Tag")
+	assert.ErrorContains(err, "must be lower case")
+}
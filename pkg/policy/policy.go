@@ -11,7 +11,6 @@ import (
 	"strings"
 
 	"github.com/hashicorp/go-multierror"
-	"github.com/soluble-ai/soluble-cli/pkg/assessments"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
@@ -27,6 +26,15 @@ type Rule struct {
 	Error    error
 }
 
+// The file of a rule's target
+type RuleFile struct {
+	// The name of the file in the target directory
+	Name string
+	Data []byte
+	// The title of the rule if the rule has one
+	Title string
+}
+
 type Target string
 
 const (
@@ -36,10 +44,11 @@ const (
 	Helm           = Target("helm")
 	Docker         = Target("docker")
 	Secrets        = Target("secrets")
+	Code           = Target("code")
 )
 
 var allTargets = []Target{
-	Terraform, Cloudformation, Kubernetes, Helm, Docker, Secrets,
+	Terraform, Cloudformation, Kubernetes, Helm, Docker, Secrets, Code,
 }
 
 type PassFail *bool
@@ -51,28 +60,43 @@ type TestRunner interface {
 type RuleType interface {
 	GetCode() string
 	Prepare(rule *Rule, target Target, dest string) error
+	// Convert a rule that Prepare wrote back to the rule file of a
+	// target, for the rule with the directory name
+	Unprepare(name string, d []byte) (*RuleFile, error)
 	Validate(rule *Rule) error
 	GetTestRunner(target Target) tools.Single
-	FindRuleResult(findings assessments.Findings, id string) PassFail
+	FindRuleResult(result *tools.Result, id string) PassFail
 }
 
 var allRuleTypes = []RuleType{
 	CheckovYAML,
+	Rego,
+	Semgrep,
+	Tfsec,
 }
 
 type Manager struct {
 	Dir   string
 	Rules map[RuleType][]*Rule
+	// The number of rule targets that are tested at the same time
+	Concurrency int
+	// The results of the tests that have been run
+	TestResults TestResults
 }
 
 func ruleTypeName(ruleType RuleType) string {
 	return fmt.Sprint(ruleType)
 }
 
+func getRuleID(ruleType RuleType, name string) string {
+	return fmt.Sprintf("c-%s-%s", ruleType.GetCode(), strings.ReplaceAll(name, "_", "-"))
+}
+
 func NewManager(dir string) *Manager {
 	return &Manager{
-		Dir:   dir,
-		Rules: make(map[RuleType][]*Rule),
+		Dir:         dir,
+		Rules:       make(map[RuleType][]*Rule),
+		Concurrency: 1,
 	}
 }
 
@@ -111,7 +135,7 @@ func (m *Manager) LoadRules(ruleType RuleType) error {
 }
 
 func (m *Manager) LoadRule(ruleType RuleType, path string) (*Rule, error) {
-	id := fmt.Sprintf("c-%s-%s", ruleType.GetCode(), strings.ReplaceAll(filepath.Base(path), "_", "-"))
+	id := getRuleID(ruleType, filepath.Base(path))
 	d, err := os.ReadFile(filepath.Join(path, "metadata.yaml"))
 	if err != nil {
 		return nil, err
@@ -151,7 +175,11 @@ func (m *Manager) ValidateRules() error {
 	var err error
 	for ruleType, rules := range m.Rules {
 		for _, rule := range rules {
-			if rule.Error = ruleType.Validate(rule); rule.Error != nil {
+			rule.Error = validateMetadata(rule)
+			if terr := ruleType.Validate(rule); terr != nil {
+				rule.Error = multierror.Append(rule.Error, terr)
+			}
+			if rule.Error != nil {
 				err = multierror.Append(err, rule.Error)
 			}
 		}
@@ -214,90 +242,21 @@ func (m *Manager) CreateTarBall(path string) error {
 }
 
 func (m *Manager) TestRules() error {
-	var err error
-	for ruleType := range m.Rules {
-		terr := m.TestRuleType(ruleType)
-		if terr != nil {
-			err = multierror.Append(err, terr)
-		}
+	var tests []ruleTarget
+	for _, ruleType := range allRuleTypes {
+		tests = append(tests, m.getRuleTypeTests(ruleType)...)
 	}
-	return err
+	return m.runTests(tests)
 }
 
 func (m *Manager) TestRuleType(ruleType RuleType) error {
-	var err error
-	for _, rule := range m.Rules[ruleType] {
-		terr := m.TestRule(rule)
-		if terr != nil {
-			err = multierror.Append(err, terr)
-		}
-	}
-	return err
+	return m.runTests(m.getRuleTypeTests(ruleType))
 }
 
 func (m *Manager) TestRule(rule *Rule) error {
-	var err error
-	for _, target := range rule.Targets {
-		terr := m.TestRuleTarget(rule, target)
-		if terr != nil {
-			err = multierror.Append(err, terr)
-		}
-	}
-	return err
+	return m.runTests(getRuleTests(rule))
 }
 
 func (m *Manager) TestRuleTarget(rule *Rule, target Target) error {
-	dir, err := os.MkdirTemp("", "test*")
-	if err != nil {
-		return err
-	}
-	defer os.RemoveAll(dir)
-	if err := rule.Type.Prepare(rule, target, dir); err != nil {
-		return err
-	}
-	failures := false
-tests:
-	for _, passFailName := range []string{"pass", "fail"} {
-		testDir := filepath.Join(rule.Path, string(target), "tests", passFailName)
-		if !util.DirExists(testDir) {
-			continue
-		}
-		tool := rule.Type.GetTestRunner(target)
-		opts := tool.GetAssessmentOptions()
-		opts.Tool = tool
-		opts.CustomPoliciesDir = dir
-		opts.UploadEnabled = false
-		if dir, ok := tool.(tools.HasDirectory); ok {
-			dir.SetDirectory(testDir)
-		}
-		opts.Quiet = true
-		result, err := tools.RunSingleAssessment(tool)
-		if err != nil {
-			return err
-		}
-		passFailResult := rule.Type.FindRuleResult(result.Findings, rule.ID)
-		if passFailResult != nil {
-			ok := *passFailResult
-			if passFailName == "fail" {
-				ok = !ok
-			}
-			p := rule.Path
-			if rp, err := filepath.Rel(m.Dir, rule.Path); err == nil {
-				p = rp
-			}
-			if ok {
-				log.Infof("{primary:%s} %s %s - {info:OK}", p, passFailName, target)
-			} else {
-				log.Errorf("{primary:%s} %s %s - {danger:FAILED}", p, passFailName, target)
-				failures = true
-			}
-			continue tests
-		}
-		log.Errorf("{primary:%s} - {danger:NOT FOUND}", testDir)
-		failures = true
-	}
-	if failures {
-		return fmt.Errorf("one or more tests have failed")
-	}
-	return nil
+	return m.runTests([]ruleTarget{{rule, target}})
 }
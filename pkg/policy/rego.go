@@ -0,0 +1,126 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/format"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/opa"
+)
+
+type regoType string
+
+// Rego rules are written in policies/opa/<rule>/<target>/rule.rego and
+// are evaluated with the opa tool.  The package of the rule is replaced
+// with one that identifies the rule when it's prepared.
+var Rego RuleType = regoType("opa")
+
+func (regoType) GetCode() string {
+	return "opa"
+}
+
+func (r regoType) Prepare(rule *Rule, target Target, dst string) error {
+	module, err := r.readRule(rule, target)
+	if err != nil {
+		return err
+	}
+	module.Package.Path = append(opa.PolicyPackage.Copy(), ast.StringTerm(rule.ID))
+	d, err := format.Ast(module)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dst, fmt.Sprintf("%s-%s.rego", target, rule.ID)), d, 0600)
+}
+
+func (regoType) Unprepare(name string, d []byte) (*RuleFile, error) {
+	module, err := ast.ParseModule("rule.rego", string(d))
+	if err != nil {
+		return nil, err
+	}
+	if module == nil {
+		return nil, fmt.Errorf("the rego rule is empty")
+	}
+	module.Package.Path = ast.MustParseRef(fmt.Sprintf("data.rules.%s", name))
+	rf := &RuleFile{Name: "rule.rego"}
+	rf.Data, err = format.Ast(module)
+	return rf, err
+}
+
+func (regoType) readRule(rule *Rule, target Target) (*ast.Module, error) {
+	path := filepath.Join(rule.Path, string(target), "rule.rego")
+	d, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	module, err := ast.ParseModule(path, string(d))
+	if err != nil {
+		return nil, fmt.Errorf("the rego rule in %s/%s/rule.rego is not legal - %w", rule.Path, target, err)
+	}
+	if module == nil {
+		return nil, fmt.Errorf("the rego rule in %s/%s/rule.rego is empty", rule.Path, target)
+	}
+	return module, nil
+}
+
+func (r regoType) Validate(rule *Rule) error {
+	var err error
+	for _, target := range rule.Targets {
+		if !opa.IsSupportedFramework(string(target)) {
+			err = multierror.Append(err, fmt.Errorf("rego rules do not support %s in %s", target, rule.Path))
+			continue
+		}
+		module, terr := r.readRule(rule, target)
+		if terr != nil {
+			err = multierror.Append(err, terr)
+			continue
+		}
+		if !opa.HasViolationRule(module) {
+			err = multierror.Append(err, fmt.Errorf("the rego rule in %s/%s/rule.rego must have a deny or violation rule",
+				rule.Path, target))
+			continue
+		}
+		compiler := ast.NewCompiler()
+		if compiler.Compile(map[string]*ast.Module{"rule.rego": module}); compiler.Failed() {
+			err = multierror.Append(err, fmt.Errorf("the rego rule in %s/%s/rule.rego does not compile - %w",
+				rule.Path, target, compiler.Errors))
+		}
+	}
+	return err
+}
+
+func (regoType) GetTestRunner(target Target) tools.Single {
+	return &opa.Tool{
+		Framework: string(target),
+	}
+}
+
+func (regoType) FindRuleResult(result *tools.Result, id string) PassFail {
+	var passFail PassFail
+	for _, finding := range result.Findings {
+		if finding.Tool["rule_id"] == id {
+			if !finding.Pass {
+				return &finding.Pass
+			}
+			passFail = &finding.Pass
+		}
+	}
+	return passFail
+}
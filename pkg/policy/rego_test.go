@@ -0,0 +1,58 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRego(t *testing.T) {
+	assert := assert.New(t)
+	m := NewManager("testdata")
+	assert.NoError(m.LoadRules(Rego))
+	if !assert.Len(m.Rules[Rego], 1) {
+		return
+	}
+	rule := m.Rules[Rego][0]
+	assert.Equal("c-opa-required-labels", rule.ID)
+	assert.ElementsMatch([]Target{Terraform, Kubernetes}, rule.Targets)
+	assert.NoError(m.ValidateRules())
+	tmp := t.TempDir()
+	assert.NoError(m.PrepareRules(tmp, Rego, Kubernetes))
+	d, err := os.ReadFile(filepath.Join(tmp, "kubernetes-c-opa-required-labels.rego"))
+	assert.NoError(err)
+	module, err := ast.ParseModule("rule.rego", string(d))
+	assert.NoError(err)
+	assert.Equal(`data.lacework["c-opa-required-labels"]`, module.Package.Path.String())
+	assert.NoError(m.TestRule(rule))
+}
+
+func TestRegoValidate(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	ruleDir := filepath.Join(dir, "policies", "opa", "bad")
+	assert.NoError(os.MkdirAll(filepath.Join(ruleDir, "terraform"), 0700))
+	assert.NoError(os.WriteFile(filepath.Join(ruleDir, "metadata.yaml"), []byte("title: bad\n"), 0600))
+	assert.NoError(os.WriteFile(filepath.Join(ruleDir, "terraform", "rule.rego"),
+		[]byte("package bad\n\nallow { true }\n"), 0600))
+	m := NewManager(dir)
+	assert.NoError(m.LoadRules(Rego))
+	assert.ErrorContains(m.ValidateRules(), "must have a deny or violation rule")
+}
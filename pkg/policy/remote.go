@@ -0,0 +1,322 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/format"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/api"
+	"github.com/soluble-ai/soluble-cli/pkg/archive"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// The server builds an artifact with the rules of each rule type from
+// the uploaded policies.  The artifacts are what the tools use for
+// custom policies, so the rules are in the form that Prepare writes.
+
+// A rule for a target in the form that Prepare writes
+type PreparedRule struct {
+	Type   RuleType
+	ID     string
+	Target Target
+	Path   string
+}
+
+// The difference between a local rule and the server's rule
+type RuleDiff struct {
+	Type   RuleType
+	ID     string
+	Target Target
+	// "added" if the rule is only local, "removed" if it's only on the
+	// server, or "changed"
+	Status string
+	Diff   string
+}
+
+type RuleDiffs []*RuleDiff
+
+const (
+	ruleAdded   = "added"
+	ruleRemoved = "removed"
+	ruleChanged = "changed"
+)
+
+var preparedRuleName = regexp.MustCompile(`^([a-z]+)-(c-[a-z]+-[a-z0-9-]+?)(?:_tfchecks)?\.(?:yaml|rego)$`)
+
+func getRulesArtifactPath(ruleType RuleType) string {
+	return fmt.Sprintf("org/{org}/rules/%s/rules.tgz", ruleTypeName(ruleType))
+}
+
+// Download the organization's rules for each rule type into dir/<rule-type>
+func DownloadPreparedRules(client *api.Client, dir string) error {
+	for _, ruleType := range allRuleTypes {
+		if err := downloadPreparedRules(client, ruleType, dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func downloadPreparedRules(client *api.Client, ruleType RuleType, dir string) error {
+	name := ruleTypeName(ruleType)
+	tgz := filepath.Join(dir, fmt.Sprintf("%s-rules.tgz", name))
+	// the download can leave a partial file behind if it fails
+	defer os.Remove(tgz)
+	if err := client.Download(getRulesArtifactPath(ruleType), tgz); err != nil {
+		if api.GetStatusCode(err) == 404 {
+			log.Infof("{primary:%s} has no custom policies", name)
+			return nil
+		}
+		return err
+	}
+	if err := untarPreparedRules(tgz, filepath.Join(dir, name)); err != nil {
+		return fmt.Errorf("could not unpack the %s rules - %w", name, err)
+	}
+	return nil
+}
+
+func untarPreparedRules(tgz, dest string) error {
+	d, err := os.ReadFile(tgz)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+	if len(d) == 0 {
+		return nil
+	}
+	// the artifact is usually, but not always, compressed
+	compressed := len(d) > 2 && d[0] == 0x1f && d[1] == 0x8b
+	return archive.UntarReader(bytes.NewReader(d), compressed,
+		afero.NewBasePathFs(afero.NewOsFs(), dest), nil)
+}
+
+// Returns the rules that were prepared in dir/<rule-type>
+func FindPreparedRules(dir string) ([]*PreparedRule, error) {
+	var rules []*PreparedRule
+	for _, ruleType := range allRuleTypes {
+		ruleTypeDir := filepath.Join(dir, ruleTypeName(ruleType))
+		err := filepath.Walk(ruleTypeDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			m := preparedRuleName.FindStringSubmatch(info.Name())
+			if m == nil || !isTarget(m[1]) || !strings.HasPrefix(m[2], fmt.Sprintf("c-%s-", ruleType.GetCode())) {
+				return nil
+			}
+			rules = append(rules, &PreparedRule{
+				Type:   ruleType,
+				ID:     m[2],
+				Target: Target(m[1]),
+				Path:   path,
+			})
+			return nil
+		})
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+func isTarget(name string) bool {
+	for _, target := range allTargets {
+		if string(target) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the directory name of the rule, which is the inverse of getRuleID
+func (pr *PreparedRule) GetRuleName() string {
+	name := strings.TrimPrefix(pr.ID, fmt.Sprintf("c-%s-", pr.Type.GetCode()))
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// Write the prepared rules back as rules in m.Dir.  Existing rule
+// files that are different are left alone unless overwrite is true.
+func (m *Manager) UnprepareRules(rules []*PreparedRule, overwrite bool) error {
+	for _, pr := range rules {
+		d, err := os.ReadFile(pr.Path)
+		if err != nil {
+			return err
+		}
+		name := pr.GetRuleName()
+		rf, err := pr.Type.Unprepare(name, d)
+		if err != nil {
+			return fmt.Errorf("could not read the %s rule %s - %w", ruleTypeName(pr.Type), pr.ID, err)
+		}
+		ruleDir := filepath.Join(m.Dir, "policies", ruleTypeName(pr.Type), name)
+		path := filepath.Join(ruleDir, string(pr.Target), rf.Name)
+		if current, err := os.ReadFile(path); err == nil && !overwrite && !bytes.Equal(current, rf.Data) {
+			log.Warnf("Not overwriting {warning:%s} which has local changes", path)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, rf.Data, 0600); err != nil {
+			return err
+		}
+		metadataPath := filepath.Join(ruleDir, "metadata.yaml")
+		if !util.FileExists(metadataPath) {
+			title := rf.Title
+			if title == "" {
+				title = getRuleTitle(name)
+			}
+			md, _ := yaml.Marshal(map[string]string{"title": title})
+			if err := os.WriteFile(metadataPath, md, 0600); err != nil {
+				return err
+			}
+		}
+		log.Infof("Wrote {primary:%s} %s to {info:%s}", pr.ID, pr.Target, path)
+	}
+	return nil
+}
+
+// Compare the rules in m to the prepared rules, returning the rules
+// that are different
+func (m *Manager) DiffRules(rules []*PreparedRule) (RuleDiffs, error) {
+	dir, err := os.MkdirTemp("", "policy-diff*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	type key struct {
+		ruleType RuleType
+		id       string
+		target   Target
+	}
+	local := map[key]string{}
+	for _, ruleType := range allRuleTypes {
+		ruleTypeDir := filepath.Join(dir, ruleTypeName(ruleType))
+		if err := os.Mkdir(ruleTypeDir, 0700); err != nil {
+			return nil, err
+		}
+		for _, rule := range m.Rules[ruleType] {
+			for _, target := range rule.Targets {
+				if err := ruleType.Prepare(rule, target, ruleTypeDir); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	localRules, err := FindPreparedRules(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, pr := range localRules {
+		text, err := normalizePreparedRule(pr.Path)
+		if err != nil {
+			return nil, err
+		}
+		local[key{pr.Type, pr.ID, pr.Target}] = text
+	}
+	var diffs RuleDiffs
+	for _, pr := range rules {
+		k := key{pr.Type, pr.ID, pr.Target}
+		remote, err := normalizePreparedRule(pr.Path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the %s rule %s - %w", ruleTypeName(pr.Type), pr.ID, err)
+		}
+		text, ok := local[k]
+		delete(local, k)
+		switch {
+		case !ok:
+			diffs = append(diffs, &RuleDiff{Type: pr.Type, ID: pr.ID, Target: pr.Target, Status: ruleRemoved,
+				Diff: getUnifiedDiff(pr, remote, "")})
+		case text != remote:
+			diffs = append(diffs, &RuleDiff{Type: pr.Type, ID: pr.ID, Target: pr.Target, Status: ruleChanged,
+				Diff: getUnifiedDiff(pr, remote, text)})
+		}
+	}
+	for k, text := range local {
+		pr := &PreparedRule{Type: k.ruleType, ID: k.id, Target: k.target}
+		diffs = append(diffs, &RuleDiff{Type: k.ruleType, ID: k.id, Target: k.target, Status: ruleAdded,
+			Diff: getUnifiedDiff(pr, "", text)})
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].ID != diffs[j].ID {
+			return diffs[i].ID < diffs[j].ID
+		}
+		return diffs[i].Target < diffs[j].Target
+	})
+	return diffs, nil
+}
+
+// Returns the differences in a form that can be printed with PrintOpts,
+// with the differences in an array named "diffs"
+func (diffs RuleDiffs) ToJNode() *jnode.Node {
+	n := jnode.NewObjectNode()
+	a := n.PutArray("diffs")
+	for _, d := range diffs {
+		a.AppendObject().
+			Put("ruleId", d.ID).
+			Put("ruleType", ruleTypeName(d.Type)).
+			Put("target", string(d.Target)).
+			Put("status", d.Status).
+			Put("diff", d.Diff)
+	}
+	return n
+}
+
+func normalizePreparedRule(path string) (string, error) {
+	d, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if filepath.Ext(path) == ".rego" {
+		module, err := ast.ParseModule(path, string(d))
+		if err != nil {
+			return "", err
+		}
+		d, err = format.Ast(module)
+		return string(d), err
+	}
+	var body interface{}
+	if err := yaml.Unmarshal(d, &body); err != nil {
+		return "", err
+	}
+	d, err = yaml.Marshal(body)
+	return string(d), err
+}
+
+func getUnifiedDiff(pr *PreparedRule, remote, local string) string {
+	name := fmt.Sprintf("%s %s", pr.ID, pr.Target)
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(remote),
+		B:        difflib.SplitLines(local),
+		FromFile: fmt.Sprintf("server %s", name),
+		ToFile:   fmt.Sprintf("local %s", name),
+		Context:  3,
+	})
+	return diff
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/soluble-ai/soluble-cli/pkg/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func getPreparedRulesTarball(t *testing.T, m *Manager, ruleType RuleType) []byte {
+	t.Helper()
+	dir := t.TempDir()
+	for _, rule := range m.Rules[ruleType] {
+		for _, target := range rule.Targets {
+			if err := ruleType.Prepare(rule, target, dir); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	w := tar.NewWriter(gz)
+	files, _ := os.ReadDir(dir)
+	for _, f := range files {
+		d, _ := os.ReadFile(filepath.Join(dir, f.Name()))
+		_ = w.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: f.Name(), Size: int64(len(d)), Mode: 0644})
+		_, _ = w.Write(d)
+	}
+	_ = w.Close()
+	_ = gz.Close()
+	return buf.Bytes()
+}
+
+func TestDownloadAndDiff(t *testing.T) {
+	assert := assert.New(t)
+	m := NewManager("testdata")
+	assert.NoError(m.LoadAllRules())
+	client := api.NewClient(&api.Config{
+		APIServer:    "https://api.example.com",
+		Organization: "test",
+	})
+	httpmock.ActivateNonDefault(client.Client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	for _, ruleType := range []RuleType{CheckovYAML, Rego, Semgrep} {
+		httpmock.RegisterResponder("GET",
+			"https://api.example.com/api/v1/org/test/rules/"+ruleTypeName(ruleType)+"/rules.tgz",
+			httpmock.NewBytesResponder(200, getPreparedRulesTarball(t, m, ruleType)))
+	}
+	httpmock.RegisterResponder("GET", "https://api.example.com/api/v1/org/test/rules/tfsec/rules.tgz",
+		httpmock.NewStringResponder(404, "not found"))
+	preparedDir := t.TempDir()
+	assert.NoError(DownloadPreparedRules(client, preparedDir))
+	tgzs, _ := filepath.Glob(filepath.Join(preparedDir, "*.tgz"))
+	assert.Empty(tgzs)
+	rules, err := FindPreparedRules(preparedDir)
+	assert.NoError(err)
+	assert.Len(rules, 4)
+
+	// round trip the rules to a new directory
+	dir := t.TempDir()
+	d := NewManager(dir)
+	assert.NoError(d.UnprepareRules(rules, false))
+	assert.NoError(d.LoadAllRules())
+	// prepared rules don't include a category or remediation, so
+	// those must be filled in before the rules are valid again
+	diags := getDiagnostics(d.ValidateRules())
+	assert.NotEmpty(diags)
+	for _, diag := range diags {
+		assert.Regexp(`metadata.yaml:1: must have a (category|remediation)$`, diag)
+	}
+	diffs, err := d.DiffRules(rules)
+	assert.NoError(err)
+	assert.Empty(diffs)
+	md, err := os.ReadFile(filepath.Join(dir, "policies", "checkov", "team_tag", "metadata.yaml"))
+	assert.NoError(err)
+	assert.Equal("title: Resources must have a Team tag\n", string(md))
+
+	// the tfsec rule is only local
+	diffs, err = m.DiffRules(rules)
+	assert.NoError(err)
+	if assert.Len(diffs, 1) {
+		assert.Equal("c-tfs-cost-centre-tag", diffs[0].ID)
+		assert.Equal(ruleAdded, diffs[0].Status)
+	}
+
+	// change a rule and remove another locally
+	rulePath := filepath.Join(dir, "policies", "checkov", "team_tag", "terraform", "rule.yaml")
+	ruleYAML, _ := os.ReadFile(rulePath)
+	changed := bytes.Replace(ruleYAML, []byte("tags.Team"), []byte("tags.Owner"), 1)
+	assert.NoError(os.WriteFile(rulePath, changed, 0600))
+	assert.NoError(os.RemoveAll(filepath.Join(dir, "policies", "semgrep")))
+	d = NewManager(dir)
+	assert.NoError(d.LoadAllRules())
+	diffs, err = d.DiffRules(rules)
+	assert.NoError(err)
+	if assert.Len(diffs, 2) {
+		assert.Equal("c-ckv-team-tag", diffs[0].ID)
+		assert.Equal(ruleChanged, diffs[0].Status)
+		assert.Contains(diffs[0].Diff, "+    attribute: tags.Owner")
+		assert.Equal("c-sem-no-md5", diffs[1].ID)
+		assert.Equal(ruleRemoved, diffs[1].Status)
+	}
+	n := diffs.ToJNode()
+	assert.Equal("changed", n.Path("diffs").Get(0).Path("status").AsText())
+
+	// local changes aren't overwritten unless forced
+	assert.NoError(d.UnprepareRules(rules, false))
+	ruleYAML, _ = os.ReadFile(rulePath)
+	assert.Equal(changed, ruleYAML)
+	assert.NoError(d.UnprepareRules(rules, true))
+	ruleYAML, _ = os.ReadFile(rulePath)
+	assert.NotEqual(changed, ruleYAML)
+}
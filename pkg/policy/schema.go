@@ -0,0 +1,173 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+// The categories that a rule can be in
+var CategoryNames = util.NewStringSetWithValues([]string{
+	"Compliance Validation", "Data Protection", "Identity and Access Management",
+	"Infrastructure Security", "Logging", "Network Security", "Operational Efficiency",
+	"Resilience", "Secrets", "Security Best Practices",
+})
+
+// A Diagnostic is a problem found at a line of a file
+type Diagnostic struct {
+	Path    string
+	Line    int
+	Message string
+}
+
+func (d *Diagnostic) Error() string {
+	if d.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", d.Path, d.Line, d.Message)
+	}
+	return fmt.Sprintf("%s: %s", d.Path, d.Message)
+}
+
+// diagnostics collects the problems found in a YAML file
+type diagnostics struct {
+	path string
+	err  error
+}
+
+func (d *diagnostics) add(n *yaml.Node, format string, args ...interface{}) {
+	diag := &Diagnostic{
+		Path:    d.path,
+		Message: fmt.Sprintf(format, args...),
+	}
+	if n != nil {
+		diag.Line = n.Line
+	}
+	d.err = multierror.Append(d.err, diag)
+}
+
+// Logs a problem that might not be one, without failing validation
+func (d *diagnostics) warn(n *yaml.Node, format string, args ...interface{}) {
+	diag := &Diagnostic{
+		Path:    d.path,
+		Line:    n.Line,
+		Message: fmt.Sprintf(format, args...),
+	}
+	log.Warnf("{warning:%s}", diag.Error())
+}
+
+// Reads a YAML file, returning its top level mapping node
+func readYAMLMapping(path string) (*yaml.Node, error) {
+	d, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(d, &doc); err != nil {
+		return nil, &Diagnostic{Path: path, Message: fmt.Sprintf("is not legal yaml - %s", err)}
+	}
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode}, nil
+	}
+	n := doc.Content[0]
+	if n.Kind != yaml.MappingNode {
+		return nil, &Diagnostic{Path: path, Line: n.Line, Message: "must be a mapping"}
+	}
+	return n, nil
+}
+
+// Returns the key and value nodes of a mapping, or nils if the key
+// isn't present
+func getMappingEntry(n *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i], n.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+func isStringNode(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && (n.Tag == "!!str" || n.Tag == "")
+}
+
+// Returns the values of a string or a sequence of strings, with
+// diagnostics for anything else
+func (d *diagnostics) getStrings(n *yaml.Node, name string) []*yaml.Node {
+	switch {
+	case isStringNode(n):
+		return []*yaml.Node{n}
+	case n.Kind == yaml.SequenceNode:
+		var values []*yaml.Node
+		for _, e := range n.Content {
+			if isStringNode(e) {
+				values = append(values, e)
+			} else {
+				d.add(e, "%s must be a list of strings", name)
+			}
+		}
+		if len(values) == 0 {
+			d.add(n, "%s must not be empty", name)
+		}
+		return values
+	default:
+		d.add(n, "%s must be a string or a list of strings", name)
+		return nil
+	}
+}
+
+// Validate metadata.yaml.  The title, category and remediation are
+// required, and the other attributes that the server uses must have
+// legal values if they're present.
+func validateMetadata(rule *Rule) error {
+	d := &diagnostics{path: filepath.Join(rule.Path, "metadata.yaml")}
+	n, err := readYAMLMapping(d.path)
+	if err != nil {
+		return err
+	}
+	for _, key := range []string{"title", "category", "remediation"} {
+		if _, v := getMappingEntry(n, key); v == nil {
+			d.add(n, "must have a %s", key)
+		}
+	}
+	if _, id := getMappingEntry(n, "id"); id != nil && id.Value != rule.ID {
+		d.add(id, "id must be %s, which is derived from the rule's directory", rule.ID)
+	}
+	if _, severity := getMappingEntry(n, "severity"); severity != nil {
+		if !isStringNode(severity) || !assessments.SeverityNames.Contains(severity.Value) {
+			d.add(severity, "severity must be one of %s", strings.Join(assessments.SeverityNames.Values(), ", "))
+		}
+	}
+	for _, key := range []string{"title", "category", "description", "remediation"} {
+		if _, v := getMappingEntry(n, key); v != nil {
+			if !isStringNode(v) || strings.TrimSpace(v.Value) == "" {
+				d.add(v, "%s must be non-empty text", key)
+			} else if key == "category" && !CategoryNames.Contains(v.Value) {
+				d.add(v, "category must be one of %s", strings.Join(CategoryNames.Values(), ", "))
+			}
+		}
+	}
+	return d.err
+}
@@ -0,0 +1,145 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestRule(t *testing.T, dir, metadata, ruleYAML string) string {
+	t.Helper()
+	ruleDir := filepath.Join(dir, "policies", "checkov", "bad")
+	if err := os.MkdirAll(filepath.Join(ruleDir, "terraform"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ruleDir, "metadata.yaml"), []byte(metadata), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ruleDir, "terraform", "rule.yaml"), []byte(ruleYAML), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return ruleDir
+}
+
+func getDiagnostics(err error) []string {
+	var diags []string
+	var merr *multierror.Error
+	if errors.As(err, &merr) {
+		for _, e := range merr.Errors {
+			var d *Diagnostic
+			if errors.As(e, &d) {
+				diags = append(diags, d.Error())
+			}
+		}
+	}
+	return diags
+}
+
+func TestValidateMetadata(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	ruleDir := writeTestRule(t, dir, `id: c-ckv-wrong
+severity: severe
+category: Things
+description: ""
+remediation:
+  - not text
+`, `definition:
+  cond_type: attribute
+  resource_types: all
+  attribute: tags.Team
+  operator: exists
+`)
+	m := NewManager(dir)
+	assert.NoError(m.LoadRules(CheckovYAML))
+	diags := getDiagnostics(m.ValidateRules())
+	metadata := filepath.Join(ruleDir, "metadata.yaml")
+	assert.ElementsMatch([]string{
+		metadata + ":1: must have a title",
+		metadata + ":1: id must be c-ckv-bad, which is derived from the rule's directory",
+		metadata + ":2: severity must be one of info, low, medium, high, critical",
+		metadata + ":3: category must be one of " + strings.Join(CategoryNames.Values(), ", "),
+		metadata + ":4: description must be non-empty text",
+		metadata + ":6: remediation must be non-empty text",
+	}, diags)
+	dir = t.TempDir()
+	ruleDir = writeTestRule(t, dir, "title: Bad\n", "definition:\n  cond_type: attribute\n")
+	m = NewManager(dir)
+	assert.NoError(m.LoadRules(CheckovYAML))
+	diags = getDiagnostics(m.ValidateRules())
+	metadata = filepath.Join(ruleDir, "metadata.yaml")
+	assert.Subset(diags, []string{
+		metadata + ":1: must have a category",
+		metadata + ":1: must have a remediation",
+	})
+}
+
+func TestValidateCheckovYAML(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	ruleDir := writeTestRule(t, dir, "title: Bad\nseverity: high\ncategory: Logging\nremediation: Fix it\n", `metadata:
+  name: bad
+scope: aws
+definition:
+  and:
+    - cond_type: attribute
+      resource_types: [aws_s3_bucket, AWS::S3::Bucket]
+      attribute: tags.Team
+      operator: is_present
+    - cond_type: attribute
+      resource_types: all
+      attribute: tags.Team
+      operator: equals
+    - cond_type: connection
+      resource_types: [aws_instance]
+      connected_resource_types: [aws_security_group]
+      operator: exists
+    - or:
+        - cond_type: filter
+          attribute: resource_type
+          operator: within
+          value: [aws_s3_bucket]
+        - cond_type: resource
+        - attribute: tags.Team
+          color: blue
+extra: true
+`)
+	m := NewManager(dir)
+	assert.NoError(m.LoadRules(CheckovYAML))
+	diags := getDiagnostics(m.ValidateRules())
+	rule := filepath.Join(ruleDir, "terraform", "rule.yaml")
+	assert.ElementsMatch([]string{
+		rule + ":3: scope must be a mapping",
+		rule + ":9: unknown operator is_present",
+		rule + ":13: operator equals requires a value",
+		rule + ":23: unknown cond_type resource, must be one of attribute, connection, filter",
+		rule + ":24: must have a cond_type, or be an and or or block",
+		rule + ":26: unknown attribute extra",
+	}, diags)
+}
+
+func TestValidateTestdata(t *testing.T) {
+	assert := assert.New(t)
+	m := NewManager("testdata")
+	assert.NoError(m.LoadAllRules())
+	assert.NoError(m.ValidateRules())
+}
@@ -0,0 +1,179 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/semgrep"
+	"gopkg.in/yaml.v3"
+)
+
+type semgrepType string
+
+// Semgrep rules are written in policies/semgrep/<rule>/code/rule.yaml,
+// which must contain a single semgrep rule.
+var Semgrep RuleType = semgrepType("semgrep")
+
+var semgrepPatternKeys = []string{
+	"pattern", "patterns", "pattern-either", "pattern-regex",
+}
+
+var semgrepSeverities = []string{"ERROR", "WARNING", "INFO"}
+
+func (semgrepType) GetCode() string {
+	return "sem"
+}
+
+func (s semgrepType) Prepare(rule *Rule, target Target, dst string) error {
+	ruleBody, err := s.readRule(rule, target)
+	if err != nil {
+		return err
+	}
+	ruleBody["id"] = rule.ID
+	d, err := yaml.Marshal(map[string]interface{}{
+		"rules": []interface{}{ruleBody},
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dst, fmt.Sprintf("%s-%s.yaml", target, rule.ID)), d, 0600)
+}
+
+func (semgrepType) Unprepare(name string, d []byte) (*RuleFile, error) {
+	var body struct {
+		Rules []map[string]interface{} `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(d, &body); err != nil {
+		return nil, err
+	}
+	for _, ruleBody := range body.Rules {
+		delete(ruleBody, "id")
+	}
+	rf := &RuleFile{Name: "rule.yaml"}
+	var err error
+	rf.Data, err = yaml.Marshal(&body)
+	return rf, err
+}
+
+func (semgrepType) readRule(rule *Rule, target Target) (map[string]interface{}, error) {
+	d, err := os.ReadFile(filepath.Join(rule.Path, string(target), "rule.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	var body struct {
+		Rules []map[string]interface{} `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(d, &body); err != nil {
+		return nil, fmt.Errorf("the semgrep rule in %s/%s/rule.yaml is not legal yaml - %w", rule.Path, target, err)
+	}
+	if len(body.Rules) != 1 {
+		return nil, fmt.Errorf("%s/%s/rule.yaml must contain exactly one semgrep rule", rule.Path, target)
+	}
+	return body.Rules[0], nil
+}
+
+func (s semgrepType) Validate(rule *Rule) error {
+	var err error
+	for _, target := range rule.Targets {
+		if target != Code {
+			err = multierror.Append(err, fmt.Errorf("semgrep rules do not support %s in %s", target, rule.Path))
+			continue
+		}
+		ruleBody, terr := s.readRule(rule, target)
+		if terr != nil {
+			err = multierror.Append(err, terr)
+			continue
+		}
+		if terr := validateSemgrepRule(ruleBody); terr != nil {
+			err = multierror.Append(err, fmt.Errorf("the semgrep rule in %s/%s/rule.yaml %w", rule.Path, target, terr))
+		}
+	}
+	return err
+}
+
+func validateSemgrepRule(ruleBody map[string]interface{}) error {
+	if s, _ := ruleBody["message"].(string); s == "" {
+		return fmt.Errorf("must have a message")
+	}
+	if languages, _ := ruleBody["languages"].([]interface{}); len(languages) == 0 {
+		return fmt.Errorf("must have a list of languages")
+	}
+	hasPattern := false
+	for _, key := range semgrepPatternKeys {
+		if ruleBody[key] != nil {
+			hasPattern = true
+			break
+		}
+	}
+	if !hasPattern {
+		return fmt.Errorf("must have one of %s", strings.Join(semgrepPatternKeys, ", "))
+	}
+	severity, _ := ruleBody["severity"].(string)
+	for _, s := range semgrepSeverities {
+		if s == severity {
+			return nil
+		}
+	}
+	return fmt.Errorf("must have a severity of %s", strings.Join(semgrepSeverities, ", "))
+}
+
+func (semgrepType) GetTestRunner(target Target) tools.Single {
+	return &semgrep.Tool{}
+}
+
+// Semgrep only reports the code that matches a rule, so if there's
+// no finding for the rule and semgrep ran it then it passed.  The
+// check_id semgrep reports is prefixed with the path of the rule file
+// that Prepare wrote.
+func (semgrepType) FindRuleResult(result *tools.Result, id string) PassFail {
+	for _, finding := range result.Findings {
+		if isSemgrepCheckID(finding.Tool["check_id"], id) {
+			return &finding.Pass
+		}
+	}
+	if !semgrepRuleRan(result.Data, id) {
+		return nil
+	}
+	pass := true
+	return &pass
+}
+
+func isSemgrepCheckID(checkID, id string) bool {
+	ruleFileID := fmt.Sprintf("%s-%s.%s", Code, id, id)
+	return checkID == id || checkID == ruleFileID || strings.HasSuffix(checkID, "."+ruleFileID)
+}
+
+// The rule ran if semgrep scanned some files and didn't report an
+// error for it or skip it.
+func semgrepRuleRan(n *jnode.Node, id string) bool {
+	if n == nil || n.Path("paths").Path("scanned").Size() == 0 {
+		return false
+	}
+	for _, key := range []string{"errors", "skipped_rules"} {
+		for _, e := range n.Path(key).Elements() {
+			if isSemgrepCheckID(e.Path("rule_id").AsText(), id) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSemgrep(t *testing.T) {
+	assert := assert.New(t)
+	m := NewManager("testdata")
+	assert.NoError(m.LoadRules(Semgrep))
+	if !assert.Len(m.Rules[Semgrep], 1) {
+		return
+	}
+	rule := m.Rules[Semgrep][0]
+	assert.Equal("c-sem-no-md5", rule.ID)
+	assert.Equal([]Target{Code}, rule.Targets)
+	assert.NoError(m.ValidateRules())
+	tmp := t.TempDir()
+	assert.NoError(m.PrepareRules(tmp, Semgrep, Code))
+	d, err := os.ReadFile(filepath.Join(tmp, "code-c-sem-no-md5.yaml"))
+	assert.NoError(err)
+	var body struct {
+		Rules []map[string]interface{} `yaml:"rules"`
+	}
+	assert.NoError(yaml.Unmarshal(d, &body))
+	if assert.Len(body.Rules, 1) {
+		assert.Equal("c-sem-no-md5", body.Rules[0]["id"])
+		assert.Equal("hashlib.md5(...)", body.Rules[0]["pattern"])
+	}
+}
+
+func TestSemgrepFindRuleResult(t *testing.T) {
+	assert := assert.New(t)
+	result := &tools.Result{
+		Data: jnode.NewObjectNode(),
+		Findings: assessments.Findings{
+			{Tool: map[string]string{"check_id": "policy.code-c-sem-other.c-sem-other"}},
+		},
+	}
+	pf := Semgrep.FindRuleResult(result, "c-sem-other")
+	if assert.NotNil(pf) {
+		assert.False(*pf)
+	}
+	// a rule with the same last segment isn't this rule
+	assert.Nil(Semgrep.FindRuleResult(result, "other"))
+	// semgrep didn't scan anything so the rule didn't run
+	assert.Nil(Semgrep.FindRuleResult(result, "c-sem-no-md5"))
+	result.Data.PutObject("paths").PutArray("scanned").Append("main.py")
+	pf = Semgrep.FindRuleResult(result, "c-sem-no-md5")
+	if assert.NotNil(pf) {
+		assert.True(*pf)
+	}
+	result.Data.PutArray("errors").AppendObject().Put("rule_id", "policy.code-c-sem-no-md5.c-sem-no-md5")
+	assert.Nil(Semgrep.FindRuleResult(result, "c-sem-no-md5"))
+}
+
+func TestSemgrepValidate(t *testing.T) {
+	assert := assert.New(t)
+	assert.ErrorContains(validateSemgrepRule(map[string]interface{}{
+		"message":   "hello",
+		"languages": []interface{}{"go"},
+		"severity":  "WARNING",
+	}), "must have one of pattern")
+	assert.ErrorContains(validateSemgrepRule(map[string]interface{}{
+		"message":   "hello",
+		"languages": []interface{}{"go"},
+		"pattern":   "fmt.Println(...)",
+		"severity":  "HIGH",
+	}), "must have a severity")
+}
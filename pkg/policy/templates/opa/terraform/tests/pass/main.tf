@@ -0,0 +1,6 @@
+resource "aws_s3_bucket" "example" {
+  bucket = "example"
+  tags = {
+    Team = "platform"
+  }
+}
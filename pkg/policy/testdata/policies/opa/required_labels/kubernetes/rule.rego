@@ -0,0 +1,6 @@
+package k8s.required_labels
+
+deny[msg] {
+	not input.metadata.labels.team
+	msg := sprintf("%s %s must have a team label", [input.kind, input.metadata.name])
+}
@@ -0,0 +1,10 @@
+package terraform.required_labels
+
+deny[violation] {
+	bucket := input.resource.aws_s3_bucket[name]
+	not bucket.tags.Team
+	violation := {
+		"msg": sprintf("aws_s3_bucket.%s must have a Team tag", [name]),
+		"resource": sprintf("aws_s3_bucket.%s", [name]),
+	}
+}
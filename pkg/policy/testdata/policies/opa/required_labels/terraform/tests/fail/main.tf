@@ -0,0 +1,10 @@
+resource "aws_s3_bucket" "logs" {
+  bucket = "${var.prefix}-logs"
+}
+
+resource "aws_s3_bucket" "data" {
+  bucket = "data"
+  tags = {
+    Project = "data"
+  }
+}
@@ -0,0 +1,6 @@
+resource "aws_s3_bucket" "logs" {
+  bucket = "${var.prefix}-logs"
+  tags = {
+    Team = "platform"
+  }
+}
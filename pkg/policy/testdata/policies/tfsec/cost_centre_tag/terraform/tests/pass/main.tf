@@ -0,0 +1,7 @@
+resource "aws_instance" "web" {
+  ami           = "ami-12345678"
+  instance_type = "t3.micro"
+  tags = {
+    CostCentre = "web"
+  }
+}
@@ -0,0 +1,284 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/print"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+)
+
+const (
+	pass     = "pass"
+	fail     = "fail"
+	notFound = "not found"
+)
+
+// The result of running the pass or fail tests of a rule's target
+type TestResult struct {
+	Rule   *Rule
+	Target Target
+	// The tests that were run, either "pass" or "fail"
+	Test string
+	// The result of the rule that the tests expect, the same as Test
+	Expected string
+	// The result of the rule, "pass", "fail", or "not found" if the
+	// rule didn't report a result.  If the tests couldn't be run then
+	// this is empty and Error is set.
+	Actual   string
+	Duration time.Duration
+	Error    error
+}
+
+type TestResults []*TestResult
+
+// The columns that the results are printed with
+var TestResultColumns = []string{"ruleId", "target", "test", "expected", "actual", "status", "duration"}
+
+// Use these with PrintOpts to print the results in JUnit format
+var TestResultJUnitColumns = &print.JUnitColumns{
+	Suite:     "ruleType",
+	Name:      "name",
+	ClassName: "ruleId",
+	File:      "rulePath",
+	Failure:   "failure",
+	Error:     "error",
+	Time:      "duration",
+}
+
+type ruleTarget struct {
+	rule   *Rule
+	target Target
+}
+
+func (r *TestResult) Passed() bool {
+	return r.Error == nil && r.Actual == r.Expected
+}
+
+func (r *TestResult) GetStatus() string {
+	switch {
+	case r.Error != nil:
+		return "ERROR"
+	case r.Passed():
+		return "OK"
+	default:
+		return "FAILED"
+	}
+}
+
+// Returns a description of the failure, or "" if the test passed or
+// errored
+func (r *TestResult) GetFailure() string {
+	if r.Error != nil || r.Passed() {
+		return ""
+	}
+	if r.Actual == notFound {
+		return fmt.Sprintf("expected %s but the rule was not found in the results", r.Expected)
+	}
+	return fmt.Sprintf("expected %s but was %s", r.Expected, r.Actual)
+}
+
+func (r *TestResult) getTestDir() string {
+	return filepath.Join(r.Rule.Path, string(r.Target), "tests", r.Test)
+}
+
+func (r *TestResult) getRulePath(dir string) string {
+	if rp, err := filepath.Rel(dir, r.Rule.Path); err == nil {
+		return filepath.ToSlash(rp)
+	}
+	return r.Rule.Path
+}
+
+func (r *TestResult) log(dir string) {
+	p := r.getRulePath(dir)
+	switch {
+	case r.Error != nil:
+		log.Errorf("{primary:%s} %s %s - {danger:ERROR} %s", p, r.Test, r.Target, r.Error)
+	case r.Actual == notFound:
+		log.Errorf("{primary:%s} - {danger:NOT FOUND}", r.getTestDir())
+	case r.Passed():
+		log.Infof("{primary:%s} %s %s - {info:OK}", p, r.Test, r.Target)
+	default:
+		log.Errorf("{primary:%s} %s %s - {danger:FAILED}", p, r.Test, r.Target)
+	}
+}
+
+// Returns the number of tests that failed or couldn't be run
+func (results TestResults) Failures() int {
+	n := 0
+	for _, r := range results {
+		if !r.Passed() {
+			n++
+		}
+	}
+	return n
+}
+
+// Returns the results in a form that can be printed with PrintOpts,
+// with the results in an array named "results".  Paths are relative
+// to dir.
+func (results TestResults) ToJNode(dir string) *jnode.Node {
+	n := jnode.NewObjectNode()
+	n.Put("tests", len(results))
+	n.Put("failures", results.Failures())
+	a := n.PutArray("results")
+	for _, r := range results {
+		e := a.AppendObject()
+		e.Put("ruleId", r.Rule.ID)
+		e.Put("ruleType", ruleTypeName(r.Rule.Type))
+		e.Put("rulePath", r.getRulePath(dir))
+		e.Put("target", string(r.Target))
+		e.Put("test", r.Test)
+		e.Put("name", fmt.Sprintf("%s %s", r.Target, r.Test))
+		e.Put("expected", r.Expected)
+		e.Put("actual", r.Actual)
+		e.Put("passed", r.Passed())
+		e.Put("status", r.GetStatus())
+		e.Put("duration", r.Duration.Truncate(time.Millisecond).Seconds())
+		if failure := r.GetFailure(); failure != "" {
+			e.Put("failure", failure)
+		}
+		if r.Error != nil {
+			e.Put("error", r.Error.Error())
+		}
+	}
+	return n
+}
+
+func (m *Manager) getRuleTypeTests(ruleType RuleType) []ruleTarget {
+	var tests []ruleTarget
+	for _, rule := range m.Rules[ruleType] {
+		tests = append(tests, getRuleTests(rule)...)
+	}
+	return tests
+}
+
+func getRuleTests(rule *Rule) []ruleTarget {
+	tests := make([]ruleTarget, len(rule.Targets))
+	for i, target := range rule.Targets {
+		tests[i] = ruleTarget{rule, target}
+	}
+	return tests
+}
+
+// Run the tests of the rule targets, up to m.Concurrency at a time.  The
+// results are added to m.TestResults in the same order as the tests.
+func (m *Manager) runTests(tests []ruleTarget) error {
+	concurrency := m.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	results := make([]TestResults, len(tests))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range tests {
+		i := i
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = m.testRuleTarget(tests[i].rule, tests[i].target)
+		}()
+	}
+	wg.Wait()
+	var all TestResults
+	for _, r := range results {
+		all = append(all, r...)
+	}
+	m.TestResults = append(m.TestResults, all...)
+	if failures := all.Failures(); failures > 0 {
+		return fmt.Errorf("%d of %d tests have failed", failures, len(all))
+	}
+	return nil
+}
+
+func (m *Manager) testRuleTarget(rule *Rule, target Target) TestResults {
+	var results TestResults
+	for _, passFail := range []string{pass, fail} {
+		r := &TestResult{
+			Rule:     rule,
+			Target:   target,
+			Test:     passFail,
+			Expected: passFail,
+		}
+		if util.DirExists(r.getTestDir()) {
+			results = append(results, r)
+		}
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	dir, err := os.MkdirTemp("", "test*")
+	if err == nil {
+		defer os.RemoveAll(dir)
+		err = rule.Type.Prepare(rule, target, dir)
+	}
+	for _, r := range results {
+		start := time.Now()
+		if err != nil {
+			r.Error = err
+		} else {
+			r.Actual, r.Error = runTest(r, dir)
+		}
+		r.Duration = time.Since(start)
+		r.log(m.Dir)
+	}
+	return results
+}
+
+func runTest(r *TestResult, customPoliciesDir string) (string, error) {
+	tool := r.Rule.Type.GetTestRunner(r.Target)
+	opts := tool.GetAssessmentOptions()
+	opts.Tool = tool
+	opts.CustomPoliciesDir = customPoliciesDir
+	opts.UploadEnabled = false
+	if dir, ok := tool.(tools.HasDirectory); ok {
+		dir.SetDirectory(r.getTestDir())
+	}
+	opts.Quiet = true
+	// tests may run concurrently, so keep the tool's output with
+	// its error
+	stderr := &bytes.Buffer{}
+	opts.Stderr = stderr
+	result, err := tools.RunSingleAssessment(tool)
+	if err != nil {
+		if s := strings.TrimSpace(stderr.String()); s != "" {
+			err = fmt.Errorf("%w\n%s", err, s)
+		}
+		return "", err
+	}
+	passFail := r.Rule.Type.FindRuleResult(result, r.Rule.ID)
+	switch {
+	case passFail == nil:
+		return notFound, nil
+	case *passFail:
+		return pass, nil
+	default:
+		return fail, nil
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunTests(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	m := NewManager(dir)
+	m.Concurrency = 4
+	for _, name := range []string{"ok_tag", "bad_tag"} {
+		for _, target := range []Target{Terraform, Kubernetes} {
+			_, err := m.CreateRule(Rego, target, name)
+			assert.NoError(err)
+		}
+	}
+	// make the fail test of bad_tag pass
+	badTests := filepath.Join(dir, "policies", "opa", "bad_tag", "kubernetes", "tests")
+	d, err := os.ReadFile(filepath.Join(badTests, "pass", "deployment.yaml"))
+	assert.NoError(err)
+	assert.NoError(os.WriteFile(filepath.Join(badTests, "fail", "deployment.yaml"), d, 0600))
+	m = NewManager(dir)
+	m.Concurrency = 4
+	assert.NoError(m.LoadAllRules())
+	assert.EqualError(m.TestRules(), "1 of 8 tests have failed")
+	if !assert.Len(m.TestResults, 8) {
+		return
+	}
+	var failed *TestResult
+	for i, r := range m.TestResults {
+		// results are in the same order as the rules and targets
+		assert.Equal(m.Rules[Rego][i/4].ID, r.Rule.ID)
+		if !r.Passed() {
+			failed = r
+		}
+	}
+	if assert.NotNil(failed) {
+		assert.Equal("c-opa-bad-tag", failed.Rule.ID)
+		assert.Equal(Kubernetes, failed.Target)
+		assert.Equal("fail", failed.Expected)
+		assert.Equal("pass", failed.Actual)
+		assert.Equal("FAILED", failed.GetStatus())
+		assert.Equal("expected fail but was pass", failed.GetFailure())
+	}
+	n := m.TestResults.ToJNode(dir)
+	assert.Equal(8, n.Path("tests").AsInt())
+	assert.Equal(1, n.Path("failures").AsInt())
+	r := n.Path("results").Get(0)
+	assert.Equal("policies/opa/bad_tag", r.Path("rulePath").AsText())
+	assert.Equal("opa", r.Path("ruleType").AsText())
+	assert.True(r.Path("passed").AsBool())
+	assert.True(r.Path("failure").IsMissing())
+}
+
+func TestRunTestsError(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	m := NewManager(dir)
+	rule, err := m.CreateRule(Rego, Terraform, "broken")
+	assert.NoError(err)
+	assert.NoError(os.WriteFile(filepath.Join(rule.Path, "terraform", "rule.rego"),
+		[]byte("package broken\n\ndeny[msg] {\n"), 0600))
+	assert.Error(m.TestRule(rule))
+	if assert.Len(m.TestResults, 2) {
+		r := m.TestResults[0]
+		assert.Error(r.Error)
+		assert.Equal("ERROR", r.GetStatus())
+		assert.Equal("", r.GetFailure())
+		assert.False(m.TestResults.ToJNode(dir).Path("results").Get(0).Path("error").IsMissing())
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/tfsec"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+type tfsecType string
+
+// tfsec rules are custom checks written in policies/tfsec/<rule>/terraform/rule.yaml
+// (or rule.json) in tfsec's custom check format, with a single check.
+// See https://aquasecurity.github.io/tfsec/latest/guides/configuration/custom-checks/
+var Tfsec RuleType = tfsecType("tfsec")
+
+type tfsecChecks struct {
+	Checks []*tfsecCheck `yaml:"checks" json:"checks"`
+}
+
+type tfsecCheck struct {
+	Code           string          `yaml:"code" json:"code"`
+	Description    string          `yaml:"description" json:"description"`
+	Impact         string          `yaml:"impact,omitempty" json:"impact,omitempty"`
+	Resolution     string          `yaml:"resolution,omitempty" json:"resolution,omitempty"`
+	RequiredTypes  []string        `yaml:"requiredTypes" json:"requiredTypes"`
+	RequiredLabels []string        `yaml:"requiredLabels,omitempty" json:"requiredLabels,omitempty"`
+	Severity       string          `yaml:"severity" json:"severity"`
+	ErrorMessage   string          `yaml:"errorMessage" json:"errorMessage"`
+	MatchSpec      *tfsecMatchSpec `yaml:"matchSpec" json:"matchSpec"`
+	RelatedLinks   []string        `yaml:"relatedLinks,omitempty" json:"relatedLinks,omitempty"`
+}
+
+type tfsecMatchSpec struct {
+	Name               string            `yaml:"name,omitempty" json:"name,omitempty"`
+	Action             string            `yaml:"action" json:"action"`
+	Value              interface{}       `yaml:"value,omitempty" json:"value,omitempty"`
+	IgnoreUndefined    bool              `yaml:"ignoreUndefined,omitempty" json:"ignoreUndefined,omitempty"`
+	SubMatch           *tfsecMatchSpec   `yaml:"subMatch,omitempty" json:"subMatch,omitempty"`
+	SubMatchOne        bool              `yaml:"subMatchOne,omitempty" json:"subMatchOne,omitempty"`
+	PredicateMatchSpec []*tfsecMatchSpec `yaml:"predicateMatchSpec,omitempty" json:"predicateMatchSpec,omitempty"`
+	AssignVariable     string            `yaml:"assignVariable,omitempty" json:"assignVariable,omitempty"`
+}
+
+var (
+	tfsecRequiredTypes = []string{
+		"data", "locals", "module", "output", "provider", "resource", "terraform", "variable",
+	}
+	tfsecSeverities = []string{
+		"CRITICAL", "HIGH", "MEDIUM", "LOW", "ERROR", "WARNING", "INFO",
+	}
+	// the actions that require a value
+	tfsecValueActions = []string{
+		"startsWith", "endsWith", "contains", "notContains", "onlyContains",
+		"equals", "notEqual", "lessThan", "lessThanOrEqual", "greaterThan",
+		"greaterThanOrEqual", "regexMatches", "requiresPresence", "isAny",
+		"isNone", "hasTag",
+	}
+	tfsecActions = append([]string{
+		"inModule", "isPresent", "notPresent", "isEmpty", "and", "or", "not",
+	}, tfsecValueActions...)
+)
+
+func (tfsecType) GetCode() string {
+	return "tfs"
+}
+
+func (t tfsecType) Prepare(rule *Rule, target Target, dst string) error {
+	check, err := t.readRule(rule, target)
+	if err != nil {
+		return err
+	}
+	check.Code = rule.ID
+	d, err := yaml.Marshal(&tfsecChecks{Checks: []*tfsecCheck{check}})
+	if err != nil {
+		return err
+	}
+	// tfsec only reads files with this suffix from the custom check dir
+	return os.WriteFile(filepath.Join(dst, fmt.Sprintf("%s-%s_tfchecks.yaml", target, rule.ID)), d, 0600)
+}
+
+func (tfsecType) Unprepare(name string, d []byte) (*RuleFile, error) {
+	var checks tfsecChecks
+	if err := yaml.Unmarshal(d, &checks); err != nil {
+		return nil, err
+	}
+	rf := &RuleFile{Name: "rule.yaml"}
+	for _, check := range checks.Checks {
+		rf.Title = check.Description
+	}
+	var err error
+	rf.Data, err = yaml.Marshal(&checks)
+	return rf, err
+}
+
+func (tfsecType) readRule(rule *Rule, target Target) (*tfsecCheck, error) {
+	dir := filepath.Join(rule.Path, string(target))
+	name := "rule.yaml"
+	if !util.FileExists(filepath.Join(dir, name)) && util.FileExists(filepath.Join(dir, "rule.json")) {
+		name = "rule.json"
+	}
+	d, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return nil, err
+	}
+	var checks tfsecChecks
+	if name == "rule.json" {
+		dec := json.NewDecoder(bytes.NewReader(d))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&checks)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(d))
+		dec.KnownFields(true)
+		err = dec.Decode(&checks)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("the tfsec check in %s/%s/%s is not legal - %w", rule.Path, target, name, err)
+	}
+	if len(checks.Checks) != 1 || checks.Checks[0] == nil {
+		return nil, fmt.Errorf("%s/%s/%s must contain exactly one tfsec check", rule.Path, target, name)
+	}
+	return checks.Checks[0], nil
+}
+
+func (t tfsecType) Validate(rule *Rule) error {
+	var err error
+	for _, target := range rule.Targets {
+		if target != Terraform {
+			err = multierror.Append(err, fmt.Errorf("tfsec rules do not support %s in %s", target, rule.Path))
+			continue
+		}
+		check, terr := t.readRule(rule, target)
+		if terr != nil {
+			err = multierror.Append(err, terr)
+			continue
+		}
+		if terr := check.validate(); terr != nil {
+			err = multierror.Append(err, fmt.Errorf("the tfsec check in %s/%s %w", rule.Path, target, terr))
+		}
+	}
+	return err
+}
+
+func (c *tfsecCheck) validate() error {
+	var err error
+	if c.Description == "" {
+		err = multierror.Append(err, fmt.Errorf("must have a description"))
+	}
+	if c.ErrorMessage == "" {
+		err = multierror.Append(err, fmt.Errorf("must have an errorMessage"))
+	}
+	if !util.StringSliceContains(tfsecSeverities, c.Severity) {
+		err = multierror.Append(err, fmt.Errorf("must have a severity of %s", strings.Join(tfsecSeverities, ", ")))
+	}
+	if len(c.RequiredTypes) == 0 {
+		err = multierror.Append(err, fmt.Errorf("must have requiredTypes"))
+	}
+	for _, rt := range c.RequiredTypes {
+		if !util.StringSliceContains(tfsecRequiredTypes, rt) {
+			err = multierror.Append(err, fmt.Errorf("has an unknown requiredType %s", rt))
+		}
+	}
+	if c.MatchSpec == nil {
+		err = multierror.Append(err, fmt.Errorf("must have a matchSpec"))
+	} else if merr := c.MatchSpec.validate("matchSpec"); merr != nil {
+		err = multierror.Append(err, merr)
+	}
+	return err
+}
+
+func (m *tfsecMatchSpec) validate(path string) error {
+	var err error
+	switch {
+	case !util.StringSliceContains(tfsecActions, m.Action):
+		err = multierror.Append(err, fmt.Errorf("%s has an unknown action %s", path, m.Action))
+	case m.Action == "and" || m.Action == "or":
+		if len(m.PredicateMatchSpec) == 0 {
+			err = multierror.Append(err, fmt.Errorf("%s must have a predicateMatchSpec for %s", path, m.Action))
+		}
+	case m.Action == "not":
+		if len(m.PredicateMatchSpec) != 1 {
+			err = multierror.Append(err, fmt.Errorf("%s must have exactly one predicateMatchSpec for not", path))
+		}
+	case util.StringSliceContains(tfsecValueActions, m.Action):
+		if m.Value == nil {
+			err = multierror.Append(err, fmt.Errorf("%s must have a value for %s", path, m.Action))
+		}
+		if s, ok := m.Value.(string); ok && m.Action == "regexMatches" {
+			if _, rerr := regexp.Compile(s); rerr != nil {
+				err = multierror.Append(err, fmt.Errorf("%s has an invalid regex - %w", path, rerr))
+			}
+		}
+	}
+	if m.SubMatch != nil {
+		if serr := m.SubMatch.validate(path + ".subMatch"); serr != nil {
+			err = multierror.Append(err, serr)
+		}
+	}
+	for i, p := range m.PredicateMatchSpec {
+		if perr := p.validate(fmt.Sprintf("%s.predicateMatchSpec[%d]", path, i)); perr != nil {
+			err = multierror.Append(err, perr)
+		}
+	}
+	return err
+}
+
+func (tfsecType) GetTestRunner(target Target) tools.Single {
+	return &tfsec.Tool{
+		NoInit: true,
+	}
+}
+
+// tfsec only reports failed checks, so if there's no finding for the
+// check then it passed.  tfsec may report the code of a custom check
+// with a prefix.
+func (tfsecType) FindRuleResult(result *tools.Result, id string) PassFail {
+	id = strings.ToLower(id)
+	for _, finding := range result.Findings {
+		ruleID := strings.ToLower(finding.Tool["rule_id"])
+		if ruleID == id || strings.HasSuffix(ruleID, "-"+id) {
+			return &finding.Pass
+		}
+	}
+	pass := true
+	return &pass
+}
@@ -0,0 +1,91 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestTfsec(t *testing.T) {
+	assert := assert.New(t)
+	m := NewManager("testdata")
+	assert.NoError(m.LoadRules(Tfsec))
+	if !assert.Len(m.Rules[Tfsec], 1) {
+		return
+	}
+	rule := m.Rules[Tfsec][0]
+	assert.Equal("c-tfs-cost-centre-tag", rule.ID)
+	assert.NoError(m.ValidateRules())
+	tmp := t.TempDir()
+	assert.NoError(m.PrepareRules(tmp, Tfsec, Terraform))
+	d, err := os.ReadFile(filepath.Join(tmp, "terraform-c-tfs-cost-centre-tag_tfchecks.yaml"))
+	assert.NoError(err)
+	var checks tfsecChecks
+	assert.NoError(yaml.Unmarshal(d, &checks))
+	if assert.Len(checks.Checks, 1) {
+		assert.Equal("c-tfs-cost-centre-tag", checks.Checks[0].Code)
+		assert.Equal("contains", checks.Checks[0].MatchSpec.Action)
+		assert.Equal("CostCentre", checks.Checks[0].MatchSpec.Value)
+	}
+	pf := Tfsec.FindRuleResult(&tools.Result{
+		Findings: assessments.Findings{
+			{Tool: map[string]string{"rule_id": "custom-custom-c-tfs-cost-centre-tag"}},
+		},
+	}, rule.ID)
+	if assert.NotNil(pf) {
+		assert.False(*pf)
+	}
+}
+
+func TestTfsecValidate(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	ruleDir := filepath.Join(dir, "policies", "tfsec", "bad")
+	assert.NoError(os.MkdirAll(filepath.Join(ruleDir, "terraform"), 0700))
+	assert.NoError(os.WriteFile(filepath.Join(ruleDir, "metadata.yaml"), []byte("title: bad\n"), 0600))
+	assert.NoError(os.WriteFile(filepath.Join(ruleDir, "terraform", "rule.json"), []byte(`{
+  "checks": [{
+    "code": "BAD",
+    "description": "bad",
+    "requiredTypes": ["resource", "block"],
+    "severity": "SEVERE",
+    "errorMessage": "bad",
+    "matchSpec": {
+      "action": "and",
+      "predicateMatchSpec": [
+        {"name": "acl", "action": "equals"},
+        {"name": "bucket", "action": "regexMatches", "value": "("}
+      ]
+    }
+  }]
+}`), 0600))
+	m := NewManager(dir)
+	assert.NoError(m.LoadRules(Tfsec))
+	err := m.ValidateRules()
+	assert.ErrorContains(err, "unknown requiredType block")
+	assert.ErrorContains(err, "must have a severity")
+	assert.ErrorContains(err, "matchSpec.predicateMatchSpec[0] must have a value for equals")
+	assert.ErrorContains(err, "matchSpec.predicateMatchSpec[1] has an invalid regex")
+	assert.NoError(os.WriteFile(filepath.Join(ruleDir, "terraform", "rule.json"),
+		[]byte(`{"checks": [{"code": "BAD", "unknown": true}]}`), 0600))
+	assert.ErrorContains(m.ValidateRules(), "is not legal")
+}
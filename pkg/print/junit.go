@@ -0,0 +1,101 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package print
+
+import (
+	"io"
+	"time"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/junit"
+)
+
+// The columns of a row that are used to make a JUnit testcase.  Rows
+// are grouped into suites by the Suite column.  A testcase has failed
+// if the Failure column is not empty, and has errored if the Error
+// column is not empty.  The Time column is in seconds.
+type JUnitColumns struct {
+	Suite     string
+	Name      string
+	ClassName string
+	File      string
+	Failure   string
+	Error     string
+	Time      string
+}
+
+type JUnitPrinter struct {
+	PathSupport
+	JUnitColumns
+}
+
+var _ Interface = &JUnitPrinter{}
+
+func (p *JUnitPrinter) PrintResult(w io.Writer, result *jnode.Node) int {
+	rows := p.GetRows(result)
+	var suites []*junit.TestSuite
+	suiteMap := map[string]*junit.TestSuite{}
+	suiteTimes := map[*junit.TestSuite]time.Duration{}
+	for _, row := range rows {
+		suiteName := p.column(row, p.Suite)
+		suite := suiteMap[suiteName]
+		if suite == nil {
+			suite = &junit.TestSuite{Name: suiteName}
+			suiteMap[suiteName] = suite
+			suites = append(suites, suite)
+		}
+		tc := &junit.TestCase{
+			Name:      p.column(row, p.Name),
+			ClassName: p.column(row, p.ClassName),
+			File:      p.column(row, p.File),
+		}
+		if tc.ClassName == "" {
+			tc.ClassName = suiteName
+		}
+		if p.Time != "" {
+			t := time.Duration(row.Path(p.Time).AsFloat() * float64(time.Second))
+			suiteTimes[suite] += t
+			tc.Time = junit.Duration(t)
+		}
+		if failure := p.column(row, p.Failure); failure != "" {
+			tc.Failure = &junit.Result{Message: failure}
+		}
+		if e := p.column(row, p.Error); e != "" {
+			tc.Error = &junit.Result{Message: e}
+		}
+		suite.AddTestCase(tc)
+	}
+	testSuites := &junit.TestSuites{}
+	var total time.Duration
+	for _, suite := range suites {
+		if p.Time != "" {
+			suite.Time = junit.Duration(suiteTimes[suite])
+			total += suiteTimes[suite]
+		}
+		testSuites.AddSuite(suite)
+	}
+	if p.Time != "" {
+		testSuites.Time = junit.Duration(total)
+	}
+	_ = testSuites.Write(w)
+	return len(rows)
+}
+
+func (p *JUnitPrinter) column(row *jnode.Node, name string) string {
+	if name == "" {
+		return ""
+	}
+	return row.Path(name).AsText()
+}
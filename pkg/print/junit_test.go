@@ -0,0 +1,56 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package print
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/soluble-ai/go-jnode"
+)
+
+func TestJUnit(t *testing.T) {
+	w := &bytes.Buffer{}
+	printer := &JUnitPrinter{
+		PathSupport: PathSupport{Path: []string{"results"}},
+		JUnitColumns: JUnitColumns{
+			Suite:   "suite",
+			Name:    "name",
+			Failure: "failure",
+			Time:    "time",
+		},
+	}
+	n := jnode.NewObjectNode()
+	a := n.PutArray("results")
+	a.AppendObject().Put("suite", "s1").Put("name", "one").Put("time", 0.5)
+	a.AppendObject().Put("suite", "s1").Put("name", "two").Put("time", 1.25).Put("failure", "oops")
+	a.AppendObject().Put("suite", "s2").Put("name", "three")
+	if count := printer.PrintResult(w, n); count != 3 {
+		t.Error(count)
+	}
+	s := w.String()
+	for _, expect := range []string{
+		`<testsuites tests="3" failures="1" errors="0" skipped="0" time="1.750">`,
+		`<testsuite name="s1" tests="2" failures="1" errors="0" skipped="0" time="1.750">`,
+		`<testcase name="two" classname="s1" time="1.250">`,
+		`<failure message="oops"></failure>`,
+		`<testsuite name="s2" tests="1" failures="0" errors="0" skipped="0" time="0.000">`,
+	} {
+		if !strings.Contains(s, expect) {
+			t.Error(expect, s)
+		}
+	}
+}
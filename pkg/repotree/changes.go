@@ -0,0 +1,175 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repotree
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Changes are the files and lines that have changed in a repository
+// since a git ref.  Paths are relative to the repository root and
+// use forward slashes.
+type Changes struct {
+	Ref   string
+	Files map[string][]LineRange
+}
+
+// An inclusive range of line numbers in the new version of a file
+type LineRange struct {
+	Start int
+	End   int
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// Returns the changes in the repository at root since ref.  The changes
+// are computed from the merge base of ref and HEAD, so that changes
+// made on ref after this branch diverged from it are not included.
+// Uncommitted changes to tracked files are included.
+func GetChanges(root, ref string) (*Changes, error) {
+	base := ref
+	mb := exec.Command("git", "merge-base", ref, "HEAD")
+	mb.Dir = root
+	if out, err := mb.Output(); err == nil {
+		base = strings.TrimSpace(string(out))
+	}
+	c := exec.Command("git", "diff", "--no-color", "--no-ext-diff", "--unified=0",
+		"--diff-filter=d", base, "--")
+	c.Dir = root
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not determine changes since %s: %w %s", ref, err,
+			strings.TrimSpace(stderr.String()))
+	}
+	changes, err := ParseDiff(bytes.NewReader(out))
+	if err != nil {
+		return nil, err
+	}
+	changes.Ref = ref
+	return changes, nil
+}
+
+// Parse the output of git diff --unified=0
+func ParseDiff(r io.Reader) (*Changes, error) {
+	changes := &Changes{
+		Files: map[string][]LineRange{},
+	}
+	var file string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			file = parseDiffFileName(line[4:])
+			if file != "" {
+				if _, ok := changes.Files[file]; !ok {
+					changes.Files[file] = nil
+				}
+			}
+		case strings.HasPrefix(line, "@@ ") && file != "":
+			m := hunkHeader.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			start, _ := strconv.Atoi(m[1])
+			count := 1
+			if m[2] != "" {
+				count, _ = strconv.Atoi(m[2])
+			}
+			r := LineRange{Start: start, End: start + count - 1}
+			if count == 0 {
+				// lines were removed after start, so count the lines on
+				// either side of the removal as changed
+				r.End = start + 1
+			}
+			changes.Files[file] = append(changes.Files[file], r)
+		}
+	}
+	return changes, sc.Err()
+}
+
+func parseDiffFileName(name string) string {
+	if name == "/dev/null" {
+		return ""
+	}
+	if unquoted, err := strconv.Unquote(name); err == nil {
+		name = unquoted
+	}
+	return strings.TrimPrefix(name, "b/")
+}
+
+func normalizePath(p string) string {
+	return path.Clean(filepath.ToSlash(p))
+}
+
+func (c *Changes) IsFileChanged(file string) bool {
+	_, ok := c.Files[normalizePath(file)]
+	return ok
+}
+
+// Returns true if line of file has changed.  If line is 0 then returns
+// true if any part of the file has changed.
+func (c *Changes) IsLineChanged(file string, line int) bool {
+	return c.IsRangeChanged(file, line, line)
+}
+
+// Returns true if any of the lines from start to end (inclusive) of file
+// have changed.  If start is 0 then returns true if any part of the file
+// has changed.
+func (c *Changes) IsRangeChanged(file string, start, end int) bool {
+	ranges, ok := c.Files[normalizePath(file)]
+	if !ok {
+		return false
+	}
+	if start <= 0 {
+		return true
+	}
+	if end < start {
+		end = start
+	}
+	for _, r := range ranges {
+		if start <= r.End && end >= r.Start {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if any file in dir or its sub-directories has changed
+func (c *Changes) HasChangesUnder(dir string) bool {
+	dir = normalizePath(dir)
+	if dir == "." {
+		return len(c.Files) > 0
+	}
+	prefix := dir + "/"
+	for file := range c.Files {
+		if file == dir || strings.HasPrefix(file, prefix) {
+			return true
+		}
+	}
+	return false
+}
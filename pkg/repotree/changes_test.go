@@ -0,0 +1,83 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repotree
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testDiff = `diff --git a/tf/main.tf b/tf/main.tf
+index 1111111..2222222 100644
+--- a/tf/main.tf
++++ b/tf/main.tf
+@@ -3 +3 @@ resource "aws_s3_bucket" "b" {
+-  acl = "private"
++  acl = "public-read"
+@@ -10,0 +11,3 @@
++  versioning {
++    enabled = true
++  }
+@@ -20,2 +23,0 @@
+-  foo = 1
+-  bar = 2
+diff --git a/k8s/new.yaml b/k8s/new.yaml
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/k8s/new.yaml
+@@ -0,0 +1,2 @@
++apiVersion: v1
++kind: Pod
+`
+
+func TestParseDiff(t *testing.T) {
+	assert := assert.New(t)
+	c, err := ParseDiff(strings.NewReader(testDiff))
+	assert.NoError(err)
+	assert.Len(c.Files, 2)
+	assert.Equal([]LineRange{{3, 3}, {11, 13}, {23, 24}}, c.Files["tf/main.tf"])
+	assert.True(c.IsFileChanged("tf/main.tf"))
+	assert.True(c.IsFileChanged("tf/../tf/main.tf"))
+	assert.False(c.IsFileChanged("tf/other.tf"))
+	assert.True(c.IsLineChanged("tf/main.tf", 3))
+	assert.False(c.IsLineChanged("tf/main.tf", 4))
+	assert.True(c.IsLineChanged("tf/main.tf", 12))
+	assert.True(c.IsLineChanged("tf/main.tf", 23))
+	assert.True(c.IsLineChanged("tf/main.tf", 0))
+	assert.True(c.IsRangeChanged("tf/main.tf", 1, 3))
+	assert.False(c.IsRangeChanged("tf/main.tf", 4, 4))
+	assert.True(c.IsRangeChanged("tf/main.tf", 4, 30))
+	assert.True(c.IsLineChanged("k8s/new.yaml", 2))
+	assert.False(c.IsLineChanged("k8s/new.yaml", 3))
+	assert.True(c.HasChangesUnder("k8s"))
+	assert.True(c.HasChangesUnder("."))
+	assert.False(c.HasChangesUnder("tf/modules"))
+	assert.False(c.HasChangesUnder("k"))
+}
+
+func TestGetChanges(t *testing.T) {
+	assert := assert.New(t)
+	root, err := FindRepoRoot(".")
+	assert.NoError(err)
+	c, err := GetChanges(root, "HEAD")
+	if assert.NoError(err) {
+		assert.Equal("HEAD", c.Ref)
+	}
+	_, err = GetChanges(root, "no-such-ref-xyzzy")
+	assert.Error(err)
+}
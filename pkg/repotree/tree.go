@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"sort"
 
+	"github.com/soluble-ai/soluble-cli/pkg/inventory"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/repotree/terraform"
 )
@@ -19,6 +20,11 @@ type Tree struct {
 	TerraformBackends            []string                              `json:"terraform_backends,omitempty"`
 	TerraformExternalModules     map[string]TerraformExternalModuleUse `json:"terraform_external_modules,omitempty"`
 	CDKDirectories               []string                              `json:"-"` // omit for now
+	ServerlessProjects           []string                              `json:"serverless_projects,omitempty"`
+	SAMTemplates                 []string                              `json:"sam_templates,omitempty"`
+	PulumiProjects               []string                              `json:"pulumi_projects,omitempty"`
+	AnsiblePlaybooks             []string                              `json:"ansible_playbooks,omitempty"`
+	AnsibleRoles                 []string                              `json:"ansible_roles,omitempty"`
 	Files                        map[string]*File                      `json:"files,omitempty"`
 }
 
@@ -54,9 +60,25 @@ func Do(dir string) (*Tree, error) {
 		}
 	}
 	tree.summarize()
+	tree.addInventory(dir)
 	return tree, nil
 }
 
+// Add the serverless, pulumi, and ansible inventory of the files
+func (tree *Tree) addInventory(dir string) {
+	paths := make([]string, 0, len(tree.Files))
+	for path := range tree.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	m := inventory.ScanProjectFiles(dir, paths)
+	tree.ServerlessProjects = m.ServerlessProjects.Values()
+	tree.SAMTemplates = m.SAMTemplates.Values()
+	tree.PulumiProjects = m.PulumiProjects.Values()
+	tree.AnsiblePlaybooks = m.AnsiblePlaybooks.Values()
+	tree.AnsibleRoles = m.AnsibleRoles.Values()
+}
+
 func (tree *Tree) addLsFiles(root string, fn func(f *File), args ...string) error {
 	c := exec.Command("git", "ls-files", "-z")
 	c.Args = append(c.Args, args...)
@@ -30,4 +30,7 @@ func TestInventory(t *testing.T) {
 	use := tree.TerraformExternalModules["terraform-aws-modules/security-group/aws"]
 	assert.Equal("4.9.0", use.Version)
 	assert.Equal(1, use.UsageCount)
+	assert.Contains(tree.SAMTemplates, "pkg/inventory/testdata/sam/template.yaml")
+	assert.Contains(tree.PulumiProjects, "pkg/inventory/testdata/pulumi/infra")
+	assert.Contains(tree.AnsibleRoles, "pkg/inventory/testdata/ansible/roles/web")
 }
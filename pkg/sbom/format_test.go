@@ -0,0 +1,79 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestSBOM() *SBOM {
+	s := &SBOM{}
+	s.add(goComponent("github.com/spf13/cobra", "v1.4.0"), "go.mod")
+	s.add(npmComponent("@babel/core", "7.18.2"), "web/package-lock.json")
+	s.add(npmComponent("@babel/core", "7.18.2"), "web/yarn.lock")
+	return s
+}
+
+func TestWriteCycloneDX(t *testing.T) {
+	assert := assert.New(t)
+	w := &bytes.Buffer{}
+	assert.NoError(getTestSBOM().WriteCycloneDX(w, "app"))
+	n, err := jnode.FromJSON(w.Bytes())
+	assert.NoError(err)
+	assert.Equal("CycloneDX", n.Path("bomFormat").AsText())
+	assert.Equal("1.4", n.Path("specVersion").AsText())
+	assert.Regexp(`^urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+		n.Path("serialNumber").AsText())
+	assert.Equal("app", n.Path("metadata").Path("component").Path("name").AsText())
+	components := n.Path("components")
+	assert.Equal(2, components.Size())
+	cobra := components.Get(0)
+	assert.Equal("github.com/spf13/cobra", cobra.Path("name").AsText())
+	assert.True(cobra.Path("group").IsMissing())
+	assert.Equal("pkg:golang/github.com/spf13/cobra@v1.4.0", cobra.Path("purl").AsText())
+	babel := components.Get(1)
+	assert.Equal("@babel", babel.Path("group").AsText())
+	assert.Equal("core", babel.Path("name").AsText())
+	assert.Equal("7.18.2", babel.Path("version").AsText())
+	assert.Equal("pkg:npm/%40babel/core@7.18.2", babel.Path("bom-ref").AsText())
+	assert.Equal(2, babel.Path("properties").Size())
+	assert.Equal("web/yarn.lock", babel.Path("properties").Get(1).Path("value").AsText())
+}
+
+func TestWriteSPDX(t *testing.T) {
+	assert := assert.New(t)
+	w := &bytes.Buffer{}
+	assert.NoError(getTestSBOM().WriteSPDX(w, "app"))
+	n, err := jnode.FromJSON(w.Bytes())
+	assert.NoError(err)
+	assert.Equal("SPDX-2.3", n.Path("spdxVersion").AsText())
+	assert.Regexp(`^https://app.soluble.cloud/spdx/app-`, n.Path("documentNamespace").AsText())
+	packages := n.Path("packages")
+	assert.Equal(3, packages.Size())
+	babel := packages.Get(2)
+	assert.Equal("@babel/core", babel.Path("name").AsText())
+	assert.Equal("SPDXRef-Package-2", babel.Path("SPDXID").AsText())
+	assert.Equal("found in web/package-lock.json, web/yarn.lock", babel.Path("sourceInfo").AsText())
+	assert.Equal("pkg:npm/%40babel/core@7.18.2",
+		babel.Path("externalRefs").Get(0).Path("referenceLocator").AsText())
+	relationships := n.Path("relationships")
+	assert.Equal(3, relationships.Size())
+	assert.Equal("DESCRIBES", relationships.Get(0).Path("relationshipType").AsText())
+	assert.Equal("SPDXRef-Package-2", relationships.Get(2).Path("relatedSpdxElement").AsText())
+}
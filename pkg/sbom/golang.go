@@ -0,0 +1,76 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// Returns the modules required by a go.mod file.  Since go 1.17 go.mod
+// lists all the modules that the build needs, not just the direct
+// dependencies.
+func parseGoMod(content []byte) ([]*Component, error) {
+	var components []*Component
+	inRequire := false
+	sc := bufio.NewScanner(bytes.NewReader(content))
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case inRequire:
+			if fields[0] == ")" {
+				inRequire = false
+				continue
+			}
+		case fields[0] == "require":
+			if len(fields) > 1 && fields[1] == "(" {
+				inRequire = true
+				continue
+			}
+			fields = fields[1:]
+		default:
+			continue
+		}
+		if len(fields) < 2 {
+			continue
+		}
+		components = append(components, goComponent(unquote(fields[0]), unquote(fields[1])))
+	}
+	return components, sc.Err()
+}
+
+func goComponent(path, version string) *Component {
+	c := &Component{Type: "golang", Name: path, Version: version}
+	if i := strings.LastIndexByte(path, '/'); i >= 0 {
+		c.Namespace = path[:i]
+		c.Name = path[i+1:]
+	}
+	return c
+}
+
+func unquote(s string) string {
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+	return s
+}
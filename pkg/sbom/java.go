@@ -0,0 +1,226 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/soluble-ai/soluble-cli/pkg/inventory"
+)
+
+type pom struct {
+	GroupID string `xml:"groupId"`
+	Version string `xml:"version"`
+	Parent  struct {
+		GroupID string `xml:"groupId"`
+		Version string `xml:"version"`
+	} `xml:"parent"`
+	Properties struct {
+		Entries []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"properties"`
+	Modules              []string        `xml:"modules>module"`
+	Dependencies         []pomDependency `xml:"dependencies>dependency"`
+	DependencyManagement []pomDependency `xml:"dependencyManagement>dependencies>dependency"`
+}
+
+type pomDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+}
+
+var pomProperty = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+func parsePOM(content []byte) (*pom, error) {
+	p := &pom{}
+	if err := xml.Unmarshal(content, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *pom) getProperties() map[string]string {
+	props := map[string]string{
+		"project.groupId": p.GroupID,
+		"project.version": p.Version,
+	}
+	if p.GroupID == "" {
+		props["project.groupId"] = p.Parent.GroupID
+	}
+	if p.Version == "" {
+		props["project.version"] = p.Parent.Version
+	}
+	props["project.parent.version"] = p.Parent.Version
+	for _, e := range p.Properties.Entries {
+		props[e.XMLName.Local] = strings.TrimSpace(e.Value)
+	}
+	return props
+}
+
+// Returns the dependencies of a pom.xml.  Versions that come from a
+// parent pom or a property that isn't defined in the file are left
+// empty.
+func parsePOMDependencies(content []byte) ([]*Component, error) {
+	p, err := parsePOM(content)
+	if err != nil {
+		return nil, err
+	}
+	props := p.getProperties()
+	resolve := func(s string) string {
+		s = pomProperty.ReplaceAllStringFunc(strings.TrimSpace(s), func(ref string) string {
+			if v, ok := props[ref[2:len(ref)-1]]; ok {
+				return v
+			}
+			return ref
+		})
+		if strings.Contains(s, "${") {
+			return ""
+		}
+		return s
+	}
+	managed := map[string]string{}
+	for _, d := range p.DependencyManagement {
+		managed[resolve(d.GroupID)+":"+resolve(d.ArtifactID)] = resolve(d.Version)
+	}
+	var components []*Component
+	for _, d := range p.Dependencies {
+		c := &Component{
+			Type:      "maven",
+			Namespace: resolve(d.GroupID),
+			Name:      resolve(d.ArtifactID),
+			Version:   resolve(d.Version),
+		}
+		if c.Version == "" {
+			c.Version = managed[c.Namespace+":"+c.Name]
+		}
+		if c.Name != "" {
+			components = append(components, c)
+		}
+	}
+	return components, nil
+}
+
+// The inventory collapses the modules of a maven project into the
+// project's directory, so look for the modules in the pom.xml
+func getJavaDirs(root string, m *inventory.Manifest) []string {
+	var dirs []string
+	seen := map[string]bool{}
+	var add func(dir string)
+	add = func(dir string) {
+		if seen[dir] {
+			return
+		}
+		seen[dir] = true
+		dirs = append(dirs, dir)
+		content, err := os.ReadFile(filepath.Join(root, dir, "pom.xml"))
+		if err != nil {
+			return
+		}
+		p, err := parsePOM(content)
+		if err != nil {
+			return
+		}
+		for _, module := range p.Modules {
+			module = filepath.FromSlash(strings.TrimSpace(module))
+			if strings.HasSuffix(module, ".xml") {
+				module = filepath.Dir(module)
+			}
+			add(filepath.Join(dir, module))
+		}
+	}
+	for _, dir := range m.JavaDirectories.Values() {
+		add(dir)
+	}
+	return dirs
+}
+
+// A gradle.lockfile has a line for each resolved dependency:
+//
+//	com.google.guava:guava:31.1-jre=compileClasspath,runtimeClasspath
+func parseGradleLockfile(content []byte) ([]*Component, error) {
+	var components []*Component
+	sc := bufio.NewScanner(bytes.NewReader(content))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' || strings.HasPrefix(line, "empty=") {
+			continue
+		}
+		if i := strings.IndexByte(line, '='); i >= 0 {
+			line = line[:i]
+		}
+		parts := strings.Split(line, ":")
+		if len(parts) != 3 {
+			continue
+		}
+		components = append(components, &Component{
+			Type: "maven", Namespace: parts[0], Name: parts[1], Version: parts[2],
+		})
+	}
+	return components, sc.Err()
+}
+
+var (
+	gradleDependency = regexp.MustCompile(`^\s*\w+\s*\(?\s*['"]([^:'"\s]+):([^:'"\s]+)(?::([^'"\s]+))?['"]`)
+	// a variable set to a string, e.g. ext.okhttpVersion = '4.9.3' or
+	// okhttpVersion = "4.9.3" in an ext block
+	gradleVariable  = regexp.MustCompile(`^\s*(?:ext\.|project\.ext\.|def\s+)?(\w+)\s*=\s*['"]([^'"$]+)['"]\s*$`)
+	gradleReference = regexp.MustCompile(`\$\{?(\w+)\}?`)
+)
+
+// Returns the dependencies declared in a build.gradle with the string
+// notation, e.g. implementation 'org.slf4j:slf4j-api:1.7.36'.  Versions
+// that refer to variables set in the file are resolved, and the other
+// variable versions are left empty.
+func parseBuildGradle(content []byte) ([]*Component, error) {
+	vars := map[string]string{}
+	var deps [][]string
+	sc := bufio.NewScanner(bytes.NewReader(content))
+	for sc.Scan() {
+		line := sc.Text()
+		if m := gradleVariable.FindStringSubmatch(line); m != nil {
+			vars[m[1]] = m[2]
+		} else if m := gradleDependency.FindStringSubmatch(line); m != nil {
+			deps = append(deps, m)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	components := make([]*Component, 0, len(deps))
+	for _, m := range deps {
+		version := gradleReference.ReplaceAllStringFunc(m[3], func(ref string) string {
+			if v, ok := vars[gradleReference.FindStringSubmatch(ref)[1]]; ok {
+				return v
+			}
+			return ref
+		})
+		if strings.Contains(version, "$") {
+			version = ""
+		}
+		components = append(components, &Component{
+			Type: "maven", Namespace: m[1], Name: m[2], Version: version,
+		})
+	}
+	return components, nil
+}
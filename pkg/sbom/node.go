@@ -0,0 +1,148 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+type packageLock struct {
+	// lockfileVersion 2 and 3 list every package by its path
+	Packages map[string]struct {
+		Version string `json:"version"`
+		Link    bool   `json:"link"`
+	} `json:"packages"`
+	// lockfileVersion 1 has a tree of dependencies
+	Dependencies packageLockDependencies `json:"dependencies"`
+}
+
+type packageLockDependencies map[string]struct {
+	Version      string                  `json:"version"`
+	Dependencies packageLockDependencies `json:"dependencies"`
+}
+
+func parsePackageLock(content []byte) ([]*Component, error) {
+	var lock packageLock
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+	var components []*Component
+	if lock.Packages != nil {
+		paths := make([]string, 0, len(lock.Packages))
+		for path := range lock.Packages {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			p := lock.Packages[path]
+			i := strings.LastIndex(path, "node_modules/")
+			if i < 0 || p.Link || p.Version == "" {
+				// the root package, a workspace, or a link to one
+				continue
+			}
+			components = append(components, npmComponent(path[i+len("node_modules/"):], p.Version))
+		}
+		return components, nil
+	}
+	var walk func(deps packageLockDependencies)
+	walk = func(deps packageLockDependencies) {
+		names := make([]string, 0, len(deps))
+		for name := range deps {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			dep := deps[name]
+			components = append(components, npmComponent(name, dep.Version))
+			walk(dep.Dependencies)
+		}
+	}
+	walk(lock.Dependencies)
+	return components, nil
+}
+
+// Parse a yarn.lock file, either the classic format or the YAML format
+// of yarn 2 and later.  Each entry starts with an unindented line of
+// the package specs that resolve to it, e.g.
+//
+//	"@babel/core@^7.0.0", "@babel/core@^7.1.0":
+//	  version "7.1.2"
+func parseYarnLock(content []byte) ([]*Component, error) {
+	var (
+		components []*Component
+		names      []string
+	)
+	sc := bufio.NewScanner(bytes.NewReader(content))
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if line[0] != ' ' {
+			names = parseYarnSpecs(strings.TrimSuffix(line, ":"))
+			continue
+		}
+		if names == nil {
+			continue
+		}
+		field := strings.TrimSpace(line)
+		if !strings.HasPrefix(field, "version ") && !strings.HasPrefix(field, "version:") {
+			continue
+		}
+		version := unquote(strings.TrimSpace(strings.TrimLeft(field[len("version"):], ":")))
+		if !strings.Contains(version, "use.local") {
+			// use.local is the version of workspace packages
+			for _, name := range names {
+				components = append(components, npmComponent(name, version))
+			}
+		}
+		names = nil
+	}
+	return components, sc.Err()
+}
+
+func parseYarnSpecs(line string) []string {
+	var names []string
+	// yarn 2 quotes the whole line rather than each spec
+	for _, spec := range strings.Split(line, ",") {
+		spec = strings.Trim(strings.TrimSpace(spec), `"`)
+		if spec == "" || spec == "__metadata" {
+			continue
+		}
+		name := spec
+		if i := strings.IndexByte(spec[1:], '@'); i >= 0 {
+			name = spec[:i+1]
+		}
+		if len(names) == 0 || names[len(names)-1] != name {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func npmComponent(name, version string) *Component {
+	c := &Component{Type: "npm", Name: name, Version: version}
+	if strings.HasPrefix(name, "@") {
+		if i := strings.IndexByte(name, '/'); i > 0 {
+			c.Namespace = name[:i]
+			c.Name = name[i+1:]
+		}
+	}
+	return c
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var requirement = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*(?:===?\s*([^\s,;#]+))?`)
+
+// Returns the packages in a requirements.txt file.  Packages that
+// aren't pinned to a version are included without one.
+func parseRequirements(content []byte) ([]*Component, error) {
+	var components []*Component
+	sc := bufio.NewScanner(bytes.NewReader(content))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' || line[0] == '-' || strings.Contains(line, "://") {
+			continue
+		}
+		m := requirement.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		components = append(components, pypiComponent(m[1], m[2]))
+	}
+	return components, sc.Err()
+}
+
+type pipfilePackages map[string]struct {
+	Version string `json:"version"`
+}
+
+type pipfileLock struct {
+	Default pipfilePackages `json:"default"`
+	Develop pipfilePackages `json:"develop"`
+}
+
+func parsePipfileLock(content []byte) ([]*Component, error) {
+	var lock pipfileLock
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+	var components []*Component
+	for _, packages := range []pipfilePackages{lock.Default, lock.Develop} {
+		names := make([]string, 0, len(packages))
+		for name := range packages {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			version := strings.TrimPrefix(packages[name].Version, "==")
+			components = append(components, pypiComponent(name, version))
+		}
+	}
+	return components, nil
+}
+
+// The purl spec normalizes pypi names to lower case with dashes
+func pypiComponent(name, version string) *Component {
+	return &Component{
+		Type:    "pypi",
+		Name:    strings.ReplaceAll(strings.ToLower(name), "_", "-"),
+		Version: version,
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+)
+
+// The gems in a Gemfile.lock are listed under "specs:" with 4 spaces of
+// indentation, and their dependencies with 6
+var gemSpec = regexp.MustCompile(`^    ([^\s(]+) \(([^)]+)\)$`)
+
+func parseGemfileLock(content []byte) ([]*Component, error) {
+	var components []*Component
+	sc := bufio.NewScanner(bytes.NewReader(content))
+	for sc.Scan() {
+		if m := gemSpec.FindStringSubmatch(sc.Text()); m != nil {
+			components = append(components, &Component{Type: "gem", Name: m[1], Version: m[2]})
+		}
+	}
+	return components, sc.Err()
+}
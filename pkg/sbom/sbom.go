@@ -0,0 +1,227 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sbom builds a software bill of materials from the lockfiles
+// in the language directories found by the inventory.
+package sbom
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/soluble-ai/soluble-cli/pkg/inventory"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+// A Component is a package that the code depends on
+type Component struct {
+	// The package URL type e.g. npm or maven
+	Type      string
+	Namespace string
+	Name      string
+	Version   string
+	// The manifest files the component was found in, relative to the
+	// directory the SBOM was generated for
+	Files []string
+}
+
+// An SBOM is the deduplicated list of components found in a directory
+type SBOM struct {
+	Components []*Component
+
+	purls map[string]*Component
+}
+
+type lockfile struct {
+	name  string
+	parse func(content []byte) ([]*Component, error)
+	// if set, don't read this file if there's a file with this name
+	// in the same directory
+	unless string
+}
+
+type ecosystem struct {
+	getDirs func(root string, m *inventory.Manifest) []string
+	files   []lockfile
+}
+
+var ecosystems = []*ecosystem{
+	{
+		getDirs: func(root string, m *inventory.Manifest) []string { return m.GODirectories.Values() },
+		files:   []lockfile{{name: "go.mod", parse: parseGoMod}},
+	},
+	{
+		getDirs: func(root string, m *inventory.Manifest) []string { return m.PythonDirectories.Values() },
+		files: []lockfile{
+			{name: "requirements.txt", parse: parseRequirements},
+			{name: "Pipfile.lock", parse: parsePipfileLock},
+		},
+	},
+	{
+		getDirs: func(root string, m *inventory.Manifest) []string { return m.NodeDirectories.Values() },
+		files: []lockfile{
+			{name: "package-lock.json", parse: parsePackageLock},
+			{name: "yarn.lock", parse: parseYarnLock},
+		},
+	},
+	{
+		getDirs: getJavaDirs,
+		files: []lockfile{
+			{name: "pom.xml", parse: parsePOMDependencies},
+			{name: "gradle.lockfile", parse: parseGradleLockfile},
+			{name: "build.gradle", parse: parseBuildGradle, unless: "gradle.lockfile"},
+		},
+	},
+	{
+		getDirs: func(root string, m *inventory.Manifest) []string { return m.RubyDirectories.Values() },
+		files:   []lockfile{{name: "Gemfile.lock", parse: parseGemfileLock}},
+	},
+}
+
+// Generate an SBOM from the lockfiles in the language directories of
+// the inventory of root.  Files that can't be parsed are skipped with a
+// warning.
+func Generate(root string, m *inventory.Manifest) *SBOM {
+	s := &SBOM{}
+	for _, e := range ecosystems {
+		dirs := e.getDirs(root, m)
+		sort.Strings(dirs)
+		for _, dir := range dirs {
+			for _, lf := range e.files {
+				if lf.unless != "" && fileExists(filepath.Join(root, dir, lf.unless)) {
+					continue
+				}
+				file := filepath.Join(dir, lf.name)
+				content, err := os.ReadFile(filepath.Join(root, file))
+				if err != nil {
+					if !os.IsNotExist(err) {
+						log.Warnf("Could not read {info:%s} - {warning:%s}", file, err)
+					}
+					continue
+				}
+				components, err := lf.parse(content)
+				if err != nil {
+					log.Warnf("Could not parse {info:%s} - {warning:%s}", file, err)
+					continue
+				}
+				for _, c := range components {
+					s.add(c, filepath.ToSlash(file))
+				}
+			}
+		}
+	}
+	s.removeUnversioned()
+	sort.SliceStable(s.Components, func(i, j int) bool {
+		return s.Components[i].PURL() < s.Components[j].PURL()
+	})
+	return s
+}
+
+// Remove the components without a version, e.g. from a version range or
+// an unresolved variable, when the same package is also found with a
+// version.  The versioned component is the one that can be matched
+// against vulnerabilities.
+func (s *SBOM) removeUnversioned() {
+	versioned := map[string]bool{}
+	for _, c := range s.Components {
+		if c.Version != "" {
+			unversioned := Component{Type: c.Type, Namespace: c.Namespace, Name: c.Name}
+			versioned[unversioned.PURL()] = true
+		}
+	}
+	components := s.Components[:0]
+	for _, c := range s.Components {
+		if c.Version == "" && versioned[c.PURL()] {
+			delete(s.purls, c.PURL())
+			continue
+		}
+		components = append(components, c)
+	}
+	s.Components = components
+}
+
+func (s *SBOM) add(c *Component, file string) {
+	if s.purls == nil {
+		s.purls = map[string]*Component{}
+	}
+	purl := c.PURL()
+	existing := s.purls[purl]
+	if existing == nil {
+		existing = c
+		s.purls[purl] = c
+		s.Components = append(s.Components, c)
+	}
+	for _, f := range existing.Files {
+		if f == file {
+			return
+		}
+	}
+	existing.Files = append(existing.Files, file)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Returns the package URL of the component, see
+// https://github.com/package-url/purl-spec
+func (c *Component) PURL() string {
+	b := &strings.Builder{}
+	b.WriteString("pkg:")
+	b.WriteString(c.Type)
+	b.WriteByte('/')
+	if c.Namespace != "" {
+		for _, segment := range strings.Split(c.Namespace, "/") {
+			b.WriteString(purlEscape(segment))
+			b.WriteByte('/')
+		}
+	}
+	b.WriteString(purlEscape(c.Name))
+	if c.Version != "" {
+		b.WriteByte('@')
+		b.WriteString(purlEscape(c.Version))
+	}
+	return b.String()
+}
+
+// Returns the name of the component the way its ecosystem writes it
+// e.g. @babel/core or org.slf4j:slf4j-api
+func (c *Component) FullName() string {
+	switch {
+	case c.Namespace == "":
+		return c.Name
+	case c.Type == "maven":
+		return c.Namespace + ":" + c.Name
+	default:
+		return c.Namespace + "/" + c.Name
+	}
+}
+
+func purlEscape(s string) string {
+	b := &strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ('0' <= ch && ch <= '9') ||
+			ch == '.' || ch == '-' || ch == '_' || ch == '~' {
+			b.WriteByte(ch)
+		} else {
+			fmt.Fprintf(b, "%%%02X", ch)
+		}
+	}
+	return b.String()
+}
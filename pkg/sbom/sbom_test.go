@@ -0,0 +1,120 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/inventory"
+	"github.com/stretchr/testify/assert"
+)
+
+func getPURLs(components []*Component) map[string][]string {
+	purls := map[string][]string{}
+	for _, c := range components {
+		purls[c.PURL()] = c.Files
+	}
+	return purls
+}
+
+func getPURLList(components []*Component) []string {
+	purls := make([]string, 0, len(components))
+	for _, c := range components {
+		purls = append(purls, c.PURL())
+	}
+	return purls
+}
+
+func TestGenerate(t *testing.T) {
+	assert := assert.New(t)
+	m := inventory.Do("testdata/app")
+	s := Generate("testdata/app", m)
+	assert.Equal(map[string][]string{
+		"pkg:golang/github.com/spf13/cobra@v1.4.0":                                       {"go/go.mod"},
+		"pkg:golang/github.com/inconshreveable/mousetrap@v1.0.0":                         {"go/go.mod"},
+		"pkg:golang/github.com/spf13/pflag@v1.0.5":                                       {"go/go.mod"},
+		"pkg:golang/gopkg.in/check.v1@v0.0.0-20161208181325-20d25e280405%2Bincompatible": {"go/go.mod"},
+		"pkg:pypi/flask@2.1.2":                                                           {"python/requirements.txt", "python/Pipfile.lock"},
+		"pkg:pypi/requests@2.27.1":                                                       {"python/requirements.txt"},
+		"pkg:pypi/pyyaml-extra":                                                          {"python/requirements.txt"},
+		"pkg:pypi/click@8.1.3":                                                           {"python/Pipfile.lock"},
+		"pkg:pypi/pytest@7.1.2":                                                          {"python/Pipfile.lock"},
+		"pkg:npm/%40babel/core@7.18.2":                                                   {"node/package-lock.json", "yarn/yarn.lock"},
+		"pkg:npm/lodash@4.17.21":                                                         {"node/package-lock.json", "yarn/yarn.lock"},
+		"pkg:npm/ms@2.0.0":                                                               {"node/package-lock.json"},
+		"pkg:maven/org.slf4j/slf4j-api@1.7.36":                                           {"java/pom.xml", "java/core/pom.xml"},
+		"pkg:maven/com.google.guava/guava@31.1-jre":                                      {"gradle/build.gradle", "java/pom.xml"},
+		"pkg:maven/com.example/core@1.2.0":                                               {"java/web/pom.xml"},
+		"pkg:maven/org.apache.commons/commons-lang3@3.12.0":                              {"gradle/build.gradle"},
+		"pkg:maven/com.squareup.okhttp3/okhttp@4.9.3":                                    {"gradle/build.gradle"},
+		"pkg:maven/org.slf4j/slf4j-simple":                                               {"gradle/build.gradle"},
+		"pkg:maven/junit/junit@4.13.2":                                                   {"gradle/build.gradle"},
+		"pkg:gem/actionpack@7.0.3":                                                       {"ruby/Gemfile.lock"},
+		"pkg:gem/nokogiri@1.13.6-x86_64-linux":                                           {"ruby/Gemfile.lock"},
+		"pkg:gem/rack@2.2.3.1":                                                           {"ruby/Gemfile.lock"},
+	}, getPURLs(s.Components))
+	for i := 1; i < len(s.Components); i++ {
+		assert.Less(s.Components[i-1].PURL(), s.Components[i].PURL())
+	}
+}
+
+func TestRemoveUnversioned(t *testing.T) {
+	assert := assert.New(t)
+	s := &SBOM{}
+	s.add(pypiComponent("PyYAML", ""), "requirements.txt")
+	s.add(pypiComponent("pyyaml", "6.0"), "Pipfile.lock")
+	s.add(pypiComponent("flask", ""), "requirements.txt")
+	s.add(&Component{Type: "maven", Namespace: "junit", Name: "junit"}, "pom.xml")
+	s.add(&Component{Type: "maven", Namespace: "junit", Name: "junit", Version: "4.13.2"}, "build.gradle")
+	s.add(&Component{Type: "maven", Namespace: "other", Name: "junit", Version: "1.0"}, "build.gradle")
+	s.removeUnversioned()
+	assert.ElementsMatch([]string{
+		"pkg:pypi/pyyaml@6.0", "pkg:pypi/flask", "pkg:maven/junit/junit@4.13.2", "pkg:maven/other/junit@1.0",
+	}, getPURLList(s.Components))
+}
+
+func TestParsePackageLockV1(t *testing.T) {
+	assert := assert.New(t)
+	content, err := os.ReadFile(filepath.Join("testdata", "lockv1", "package-lock.json"))
+	assert.NoError(err)
+	components, err := parsePackageLock(content)
+	assert.NoError(err)
+	assert.Equal(map[string][]string{
+		"pkg:npm/debug@2.6.9": nil,
+		"pkg:npm/ms@2.0.0":    nil,
+		"pkg:npm/ms@2.1.3":    nil,
+	}, getPURLs(components))
+}
+
+func TestParseYarnBerry(t *testing.T) {
+	assert := assert.New(t)
+	content, err := os.ReadFile(filepath.Join("testdata", "yarn-berry.lock"))
+	assert.NoError(err)
+	components, err := parseYarnLock(content)
+	assert.NoError(err)
+	if assert.Len(components, 1) {
+		assert.Equal("pkg:npm/chalk@4.1.2", components[0].PURL())
+	}
+}
+
+func TestFullName(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("@babel/core", npmComponent("@babel/core", "7.18.2").FullName())
+	assert.Equal("lodash", npmComponent("lodash", "4.17.21").FullName())
+	assert.Equal("github.com/spf13/cobra", goComponent("github.com/spf13/cobra", "v1.4.0").FullName())
+	assert.Equal("org.slf4j:slf4j-api", (&Component{Type: "maven", Namespace: "org.slf4j", Name: "slf4j-api"}).FullName())
+}
@@ -0,0 +1,13 @@
+module example.com/app
+
+go 1.17
+
+require github.com/spf13/cobra v1.4.0
+
+require (
+	github.com/inconshreveable/mousetrap v1.0.0 // indirect
+	github.com/spf13/pflag v1.0.5 // indirect
+	gopkg.in/check.v1 v0.0.0-20161208181325-20d25e280405+incompatible // indirect
+)
+
+replace github.com/spf13/pflag => ../pflag
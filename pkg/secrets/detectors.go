@@ -0,0 +1,133 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// A Detector finds secrets in a line of text
+type Detector interface {
+	// The name of the detector, as reported in plugins_used
+	Name() string
+	// Returns the secrets in line
+	Find(line string) []*Match
+}
+
+// A Match is a secret found by a detector
+type Match struct {
+	Type  string
+	Value string
+}
+
+type regexDetector struct {
+	name     string
+	typ      string
+	patterns []*regexp.Regexp
+	// if set, a candidate value is only a secret if valid returns true
+	valid func(value string) bool
+}
+
+func (d *regexDetector) Name() string {
+	return d.name
+}
+
+// The secret is the first sub-match of the pattern, or the whole match
+// if the pattern has no groups
+func (d *regexDetector) Find(line string) []*Match {
+	var matches []*Match
+	for _, p := range d.patterns {
+		for _, m := range p.FindAllStringSubmatch(line, -1) {
+			value := m[0]
+			if len(m) > 1 {
+				value = m[1]
+			}
+			if d.valid == nil || d.valid(value) {
+				matches = append(matches, &Match{Type: d.typ, Value: value})
+			}
+		}
+	}
+	return matches
+}
+
+func isJWT(value string) bool {
+	parts := strings.Split(value, ".")
+	if len(parts) < 2 {
+		return false
+	}
+	for _, part := range parts[:2] {
+		d, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(part, "="))
+		if err != nil || !json.Valid(d) {
+			return false
+		}
+	}
+	return true
+}
+
+// The detectors that look for specific kinds of credentials.  The type
+// names match the ones that detect-secrets uses.
+var keywordDetectors = []Detector{
+	&regexDetector{
+		name: "AWSKeyDetector",
+		typ:  "AWS Access Key",
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`\b((?:AKIA|ASIA|ABIA|ACCA|A3T[A-Z0-9])[0-9A-Z]{16})\b`),
+			regexp.MustCompile(`(?i)aws.{0,20}?(?:secret|key).{0,20}?['"]([0-9a-zA-Z/+]{40})['"]`),
+		},
+	},
+	&regexDetector{
+		name: "GCPKeyDetector",
+		typ:  "GCP API Key",
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`\b(AIza[0-9A-Za-z_\-]{35})(?:[^0-9A-Za-z_\-]|$)`),
+			regexp.MustCompile(`\b(GOCSPX-[0-9A-Za-z_\-]{28})(?:[^0-9A-Za-z_\-]|$)`),
+		},
+	},
+	&regexDetector{
+		name: "GitHubTokenDetector",
+		typ:  "GitHub Token",
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`\b(gh[pousr]_[0-9A-Za-z]{36,255})\b`),
+			regexp.MustCompile(`\b(github_pat_[0-9A-Za-z_]{82})\b`),
+		},
+	},
+	&regexDetector{
+		name: "SlackDetector",
+		typ:  "Slack Token",
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`\b(xox[aboprs]-(?:\d+-)+[0-9a-zA-Z]+)`),
+			regexp.MustCompile(`(https://hooks\.slack\.com/services/T[0-9A-Za-z_]+/B[0-9A-Za-z_]+/[0-9A-Za-z_]+)`),
+		},
+	},
+	&regexDetector{
+		name: "PrivateKeyDetector",
+		typ:  "Private Key",
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`-----BEGIN (?:(?:RSA|DSA|EC|OPENSSH|PGP|ENCRYPTED) )?PRIVATE KEY(?: BLOCK)?-----`),
+			regexp.MustCompile(`PuTTY-User-Key-File-\d+`),
+		},
+	},
+	&regexDetector{
+		name: "JwtTokenDetector",
+		typ:  "JSON Web Token",
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`\b(eyJ[A-Za-z0-9_=-]+\.eyJ[A-Za-z0-9_=-]+\.?[A-Za-z0-9_.+/=-]*)`),
+		},
+		valid: isJWT,
+	},
+}
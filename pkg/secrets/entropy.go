@@ -0,0 +1,125 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// The characters of the strings that the detectors measure
+const (
+	base64Charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/=_-" // pragma: allowlist secret
+	hexCharset    = "0123456789abcdefABCDEF"                                              // pragma: allowlist secret
+)
+
+const (
+	// The default limits of detect-secrets
+	DefaultBase64Limit = 4.5
+	DefaultHexLimit    = 3.0
+	// Strings shorter than this are never reported
+	minEntropyStringLength = 20
+)
+
+// Finds quoted strings whose Shannon entropy is higher than a limit
+type entropyDetector struct {
+	name    string
+	typ     string
+	charset string
+	limit   float64
+	pattern *regexp.Regexp
+}
+
+func newEntropyDetector(name, typ, charset string, limit float64) *entropyDetector {
+	cs := regexp.QuoteMeta(charset)
+	return &entropyDetector{
+		name:    name,
+		typ:     typ,
+		charset: charset,
+		limit:   limit,
+		pattern: regexp.MustCompile(fmt.Sprintf(`["']([%s]{%d,})["']`, cs, minEntropyStringLength)),
+	}
+}
+
+func (d *entropyDetector) Name() string {
+	return d.name
+}
+
+func (d *entropyDetector) Find(line string) []*Match {
+	var matches []*Match
+	for _, m := range d.pattern.FindAllStringSubmatch(line, -1) {
+		if !isSequential(m[1]) && d.entropy(m[1]) > d.limit {
+			matches = append(matches, &Match{Type: d.typ, Value: m[1]})
+		}
+	}
+	return matches
+}
+
+func (d *entropyDetector) entropy(s string) float64 {
+	e := ShannonEntropy(s, d.charset)
+	if d.charset == hexCharset && strings.Trim(s, "0123456789") == "" {
+		// numbers have low entropy in practice, so reduce the entropy of
+		// hex strings that are all digits the same way detect-secrets does
+		e -= 1.2 / math.Log2(float64(len(s)))
+	}
+	return e
+}
+
+var sequences = func() []string {
+	const (
+		upper  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+		digits = "0123456789"
+	)
+	return []string{
+		upper + upper + digits + "+/",
+		digits + upper + upper + "+/",
+		strings.Repeat(digits+"ABCDEF", 2),
+		strings.Repeat(digits, 2),
+	}
+}()
+
+// Returns true if s is part of a sequence of characters like an
+// alphabet, which have high entropy but aren't secrets
+func isSequential(s string) bool {
+	u := strings.ToUpper(s)
+	for _, seq := range sequences {
+		if strings.Contains(seq, u) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the Shannon entropy of the characters of s that are in charset
+func ShannonEntropy(s, charset string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+	var e float64
+	n := float64(len(s))
+	for _, r := range charset {
+		if c := counts[r]; c > 0 {
+			p := float64(c) / n
+			e -= p * math.Log2(p)
+		}
+	}
+	return e
+}
@@ -0,0 +1,253 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Blobs larger than this aren't scanned
+const maxBlobSize = 10 * 1024 * 1024
+
+// A HistorySecret is a secret found in a file in the git history.  The
+// commit is the first commit that added the secret to the file.
+type HistorySecret struct {
+	Secret
+	Path   string
+	Commit string
+	Author string
+	Date   string
+	// The number of versions of files that contain the secret
+	Occurrences int
+}
+
+// A HistoryScan scans the files added or changed by a range of commits
+type HistoryScan struct {
+	Scanner *Scanner
+	// The directory to run git in.  Only files in this directory are
+	// scanned, and paths are relative to it.
+	Dir string
+	// The arguments to git log that select the commits, e.g. HEAD
+	// or main..HEAD
+	Revisions []string
+	// If set, only the files for which this returns true are scanned
+	Include     func(path string) bool
+	Concurrency int
+
+	// The number of commits and file versions scanned
+	CommitCount int
+	BlobCount   int
+}
+
+type historyBlob struct {
+	sha    string
+	path   string
+	commit *historyCommit
+}
+
+type historyCommit struct {
+	sha    string
+	author string
+	date   string
+}
+
+// Scan the history, returning each secret once, from the earliest
+// commit that has it
+func (h *HistoryScan) Run() ([]*HistorySecret, error) {
+	blobs, err := h.listBlobs()
+	if err != nil {
+		return nil, err
+	}
+	found, err := h.scanBlobs(blobs)
+	if err != nil {
+		return nil, err
+	}
+	var result []*HistorySecret
+	seen := map[string]*HistorySecret{}
+	for i, secrets := range found {
+		for _, s := range secrets {
+			key := s.Type + ":" + s.HashedSecret
+			if hs := seen[key]; hs != nil {
+				hs.Occurrences++
+				continue
+			}
+			hs := &HistorySecret{
+				Secret:      *s,
+				Path:        blobs[i].path,
+				Commit:      blobs[i].commit.sha,
+				Author:      blobs[i].commit.author,
+				Date:        blobs[i].commit.date,
+				Occurrences: 1,
+			}
+			seen[key] = hs
+			result = append(result, hs)
+		}
+	}
+	return result, nil
+}
+
+// Returns the blobs added by the commits, oldest first.  Each blob is
+// returned once.
+func (h *HistoryScan) listBlobs() ([]*historyBlob, error) {
+	args := []string{"log", "--reverse", "--root", "--raw", "--no-renames", "--no-abbrev",
+		"--relative", "-z", "--format=%x01%H%x00%an <%ae>%x00%aI"}
+	args = append(args, h.Revisions...)
+	args = append(args, "--")
+	c := exec.Command("git", args...)
+	c.Dir = h.Dir
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not list the commits of %s: %w %s", strings.Join(h.Revisions, " "),
+			err, strings.TrimSpace(stderr.String()))
+	}
+	var (
+		blobs  []*historyBlob
+		commit *historyCommit
+	)
+	seen := map[string]bool{}
+	fields := strings.Split(string(out), "\x00")
+	for i := 0; i < len(fields); i++ {
+		field := strings.TrimLeft(fields[i], "\n")
+		switch {
+		case strings.HasPrefix(field, "\x01") && i+2 < len(fields):
+			commit = &historyCommit{sha: field[1:], author: fields[i+1], date: fields[i+2]}
+			h.CommitCount++
+			i += 2
+		case strings.HasPrefix(field, ":") && i+1 < len(fields) && commit != nil:
+			// :old-mode new-mode old-sha new-sha status
+			raw := strings.Fields(field)
+			path := fields[i+1]
+			i++
+			if len(raw) != 5 || raw[4] == "D" || raw[1] != "100644" && raw[1] != "100755" {
+				continue
+			}
+			sha := raw[3]
+			if seen[sha] || (h.Include != nil && !h.Include(path)) {
+				continue
+			}
+			seen[sha] = true
+			blobs = append(blobs, &historyBlob{sha: sha, path: path, commit: commit})
+		}
+	}
+	return blobs, nil
+}
+
+// Scan the blobs, reading them with git cat-file and scanning them
+// concurrently
+func (h *HistoryScan) scanBlobs(blobs []*historyBlob) ([][]*Secret, error) {
+	found := make([][]*Secret, len(blobs))
+	if len(blobs) == 0 {
+		return found, nil
+	}
+	c := exec.Command("git", "cat-file", "--batch")
+	c.Dir = h.Dir
+	c.Stderr = os.Stderr
+	stdin, err := c.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := c.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Start(); err != nil {
+		return nil, err
+	}
+	go func() {
+		w := bufio.NewWriter(stdin)
+		for _, b := range blobs {
+			fmt.Fprintln(w, b.sha)
+		}
+		_ = w.Flush()
+		_ = stdin.Close()
+	}()
+	concurrency := h.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	r := bufio.NewReader(stdout)
+	for i := range blobs {
+		content, err := readBlob(r)
+		if err != nil {
+			wg.Wait()
+			_ = c.Process.Kill()
+			_ = c.Wait()
+			return nil, err
+		}
+		h.BlobCount++
+		if content == nil || IsBinary(content) {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, content []byte) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			found[i], _ = h.Scanner.Scan(bytes.NewReader(content))
+		}(i, content)
+	}
+	wg.Wait()
+	if err := c.Wait(); err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// Read the next blob from the output of git cat-file --batch.  Returns
+// nil content if the blob is missing or too big.
+func readBlob(r *bufio.Reader) ([]byte, error) {
+	header, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	// <sha> blob <size>, or <sha> missing
+	parts := strings.Fields(header)
+	if len(parts) != 3 {
+		return nil, nil
+	}
+	size, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected output from git cat-file: %s", strings.TrimSpace(header))
+	}
+	var content []byte
+	if size > maxBlobSize {
+		_, err = io.CopyN(io.Discard, r, size)
+	} else {
+		content = make([]byte, size)
+		_, err = io.ReadFull(r, content)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// the content is followed by a newline
+	if _, err := r.ReadByte(); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
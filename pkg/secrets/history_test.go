@@ -0,0 +1,105 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	c := exec.Command("git", args...)
+	c.Dir = dir
+	c.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Dev", "GIT_AUTHOR_EMAIL=dev@example.com",
+		"GIT_COMMITTER_NAME=Dev", "GIT_COMMITTER_EMAIL=dev@example.com")
+	out, err := c.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s failed: %s %s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func commitFile(t *testing.T, dir, name, content, message string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "-m", message)
+	return git(t, dir, "rev-parse", "HEAD")
+}
+
+func TestHistoryScan(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	assert := assert.New(t)
+	dir := t.TempDir()
+	git(t, dir, "init", "-q")
+	first := commitFile(t, dir, "main.tf", "# config\nkey = \""+awsKey+"\"\n", "add key")
+	commitFile(t, dir, "main.tf", "# more config\n# config\nkey = \""+awsKey+"\"\n", "change file")
+	second := commitFile(t, dir, "main.tf", "# config\n", "remove key")
+	third := commitFile(t, dir, "app/slack.txt", "token: "+slackToken+"\n", "add token")
+	commitFile(t, dir, "vendor/key.txt", githubKey, "add vendored key")
+	hs := &HistoryScan{
+		Scanner:   NewScanner(),
+		Dir:       dir,
+		Revisions: []string{"HEAD"},
+		Include: func(path string) bool {
+			return !strings.HasPrefix(path, "vendor/")
+		},
+		Concurrency: 2,
+	}
+	found, err := hs.Run()
+	assert.NoError(err)
+	assert.Equal(5, hs.CommitCount)
+	assert.Equal(4, hs.BlobCount)
+	if assert.Len(found, 2) {
+		s := found[0]
+		assert.Equal("AWS Access Key", s.Type)
+		assert.Equal("main.tf", s.Path)
+		assert.Equal(2, s.Line)
+		assert.Equal(first, s.Commit)
+		assert.Equal("Dev <dev@example.com>", s.Author)
+		assert.NotEmpty(s.Date)
+		assert.Equal(2, s.Occurrences)
+		assert.Equal("Slack Token", found[1].Type)
+		assert.Equal("app/slack.txt", found[1].Path)
+		assert.Equal(third, found[1].Commit)
+	}
+	hs = &HistoryScan{
+		Scanner:   NewScanner(),
+		Dir:       filepath.Join(dir, "app"),
+		Revisions: []string{second + "..HEAD"},
+	}
+	found, err = hs.Run()
+	assert.NoError(err)
+	if assert.Len(found, 1) {
+		assert.Equal("slack.txt", found[0].Path)
+	}
+	hs.Revisions = []string{"no-such-ref"}
+	_, err = hs.Run()
+	assert.Error(err)
+}
@@ -0,0 +1,189 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1" //nolint:gosec // the hash is an identifier, as in detect-secrets
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Lines with this comment are not scanned, as in detect-secrets
+const allowlistComment = "pragma: allowlist secret"
+
+// A Secret is a secret found in a file
+type Secret struct {
+	Type         string
+	Line         int
+	HashedSecret string
+	// The detector that found the secret
+	Detector string
+}
+
+// A Scanner finds secrets with a set of detectors.  A Scanner can be
+// used concurrently.
+type Scanner struct {
+	Detectors   []Detector
+	Base64Limit float64
+	HexLimit    float64
+
+	once      sync.Once
+	detectors []Detector
+}
+
+// Returns a scanner with all the detectors and the default entropy limits
+func NewScanner() *Scanner {
+	s := &Scanner{
+		Base64Limit: DefaultBase64Limit,
+		HexLimit:    DefaultHexLimit,
+	}
+	s.Detectors = append(s.Detectors, keywordDetectors...)
+	return s
+}
+
+func (s *Scanner) getDetectors() []Detector {
+	detectors := append([]Detector{}, s.Detectors...)
+	if s.Base64Limit > 0 {
+		detectors = append(detectors, newEntropyDetector("Base64HighEntropyString",
+			"Base64 High Entropy String", base64Charset, s.Base64Limit))
+	}
+	if s.HexLimit > 0 {
+		detectors = append(detectors, newEntropyDetector("HexHighEntropyString",
+			"Hex High Entropy String", hexCharset, s.HexLimit))
+	}
+	return detectors
+}
+
+// Returns the detectors that the scanner uses, in the form of the
+// plugins_used list of detect-secrets
+func (s *Scanner) GetPluginsUsed() []map[string]interface{} {
+	var plugins []map[string]interface{}
+	for _, d := range s.getDetectors() {
+		p := map[string]interface{}{"name": d.Name()}
+		if ed, ok := d.(*entropyDetector); ok {
+			if ed.charset == hexCharset {
+				p["hex_limit"] = ed.limit
+			} else {
+				p["base64_limit"] = ed.limit
+			}
+		}
+		plugins = append(plugins, p)
+	}
+	return plugins
+}
+
+func HashSecret(value string) string {
+	h := sha1.Sum([]byte(value)) //nolint:gosec
+	return hex.EncodeToString(h[:])
+}
+
+// Returns the secrets in a line.  A value that's found by one of the
+// keyword detectors isn't also reported as a high entropy string.
+func (s *Scanner) ScanLine(line string) []*Secret {
+	if strings.Contains(line, allowlistComment) {
+		return nil
+	}
+	s.once.Do(func() {
+		s.detectors = s.getDetectors()
+	})
+	var (
+		secrets  []*Secret
+		keywords []string
+	)
+	seen := map[Match]bool{}
+	for _, d := range s.detectors {
+		_, isEntropy := d.(*entropyDetector)
+		for _, m := range d.Find(line) {
+			if seen[*m] || (isEntropy && overlaps(keywords, m.Value)) {
+				continue
+			}
+			seen[*m] = true
+			if !isEntropy {
+				keywords = append(keywords, m.Value)
+			}
+			secrets = append(secrets, &Secret{
+				Type:         m.Type,
+				HashedSecret: HashSecret(m.Value),
+				Detector:     d.Name(),
+			})
+		}
+	}
+	return secrets
+}
+
+func overlaps(values []string, value string) bool {
+	for _, v := range values {
+		if strings.Contains(v, value) || strings.Contains(value, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan r line by line for secrets.  Lines longer than 1MB are skipped.
+func (s *Scanner) Scan(r io.Reader) ([]*Secret, error) {
+	var secrets []*Secret
+	br := bufio.NewReaderSize(r, 64*1024)
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := readLine(br, 1024*1024)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return secrets, nil
+			}
+			return secrets, err
+		}
+		for _, secret := range s.ScanLine(line) {
+			secret.Line = lineNumber
+			secrets = append(secrets, secret)
+		}
+	}
+}
+
+// Read a line, returning "" for lines longer than max
+func readLine(br *bufio.Reader, max int) (string, error) {
+	var buf []byte
+	tooLong := false
+	for {
+		chunk, isPrefix, err := br.ReadLine()
+		if err != nil {
+			return "", err
+		}
+		if !tooLong {
+			buf = append(buf, chunk...)
+			if len(buf) > max {
+				tooLong = true
+				buf = nil
+			}
+		}
+		if !isPrefix {
+			break
+		}
+	}
+	return string(buf), nil
+}
+
+// Returns true if the start of a file looks like binary content, using
+// the same heuristic as git
+func IsBinary(head []byte) bool {
+	if len(head) > 8000 {
+		head = head[:8000]
+	}
+	return bytes.IndexByte(head, 0) >= 0
+}
@@ -0,0 +1,97 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the secrets are assembled or allowlisted so that this file doesn't
+// look like it has secrets in it
+var (
+	awsKey     = "AKIA" + "IOSFODNN7EXAMPLE"
+	githubKey  = "ghp_" + strings.Repeat("aB3x", 9)
+	slackToken = "xoxb-" + "1234567890-1234567890-abcdefghijklmnopqrstuvwx" // pragma: allowlist secret
+	gcpKey     = "AIza" + "SyA1b2C3d4E5f6G7h8I9j0K1l2M3n4O5p6Q"             // pragma: allowlist secret
+	privateKey = "-----BEGIN RSA " + "PRIVATE KEY-----"
+	jwt        = "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9" + ".eyJzdWIiOiIxMjM0NTY3ODkwIn0.c2lnbmF0dXJl" // pragma: allowlist secret
+	base64Str  = "Zm9vYmFyYmF6cXV4MTIzNDU2Nzg5MEFCQ0RFRkdISUpL"                                       // pragma: allowlist secret
+	hexStr     = "3f2a9b7c1d8e4f6a0b5c9d2e7f1a3b8c"                                                   // pragma: allowlist secret
+)
+
+func TestScanLine(t *testing.T) {
+	assert := assert.New(t)
+	s := NewScanner()
+	types := func(line string) []string {
+		var types []string
+		for _, secret := range s.ScanLine(line) {
+			types = append(types, secret.Type)
+		}
+		return types
+	}
+	assert.Equal([]string{"AWS Access Key"}, types(`key = "`+awsKey+`"`))
+	assert.Equal([]string{"GitHub Token"}, types("token: "+githubKey))
+	assert.Equal([]string{"Slack Token"}, types("SLACK="+slackToken))
+	assert.Equal([]string{"GCP API Key"}, types(`"api_key": "`+gcpKey+`"`))
+	assert.Equal([]string{"Private Key"}, types(privateKey))
+	assert.Equal([]string{"JSON Web Token"}, types("Authorization: Bearer "+jwt))
+	assert.Equal([]string{"Base64 High Entropy String"}, types(`password = "`+base64Str+`"`))
+	assert.Equal([]string{"Hex High Entropy String"}, types(`secret = '`+hexStr+`'`))
+	assert.Empty(types(`name = "this_is_not_a_secret_value_at_all"`))
+	assert.Empty(types(`id = "11112222333344445555"`))
+	assert.Empty(types(`letters = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"`))
+	assert.Empty(types("eyJub3Rqc29u.eyJub3Rqc29u.x"))
+	assert.Empty(types(`key = "` + awsKey + `" # pragma: allowlist secret`))
+	secrets := s.ScanLine(awsKey + " " + awsKey)
+	if assert.Len(secrets, 1) {
+		assert.Equal(HashSecret(awsKey), secrets[0].HashedSecret)
+		assert.Equal("AWSKeyDetector", secrets[0].Detector)
+	}
+}
+
+func TestScan(t *testing.T) {
+	assert := assert.New(t)
+	s := NewScanner()
+	text := strings.Join([]string{
+		"first line",
+		strings.Repeat("x", 2*1024*1024) + awsKey,
+		"",
+		"token: " + githubKey,
+	}, "\n")
+	secrets, err := s.Scan(strings.NewReader(text))
+	assert.NoError(err)
+	if assert.Len(secrets, 1) {
+		assert.Equal(4, secrets[0].Line)
+		assert.Equal("GitHub Token", secrets[0].Type)
+	}
+	s = &Scanner{}
+	secrets, err = s.Scan(strings.NewReader(`password = "` + base64Str + `"`))
+	assert.NoError(err)
+	assert.Empty(secrets)
+	assert.Empty(s.GetPluginsUsed())
+	assert.Len(NewScanner().GetPluginsUsed(), len(keywordDetectors)+2)
+}
+
+func TestEntropy(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(0.0, ShannonEntropy("aaaa", base64Charset))
+	assert.Equal(1.0, ShannonEntropy("abab", base64Charset))
+	assert.Equal(2.0, ShannonEntropy("abcd", base64Charset))
+	assert.True(IsBinary([]byte{'a', 0, 'b'}))
+	assert.False(IsBinary([]byte("text")))
+}
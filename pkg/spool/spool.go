@@ -0,0 +1,238 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package spool keeps uploads that couldn't be sent so that they can
+// be sent later.  Everything needed to reproduce the upload, including
+// the CI environment and git metadata at the time of the run, is saved.
+package spool
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/api"
+	"github.com/soluble-ai/soluble-cli/pkg/config"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/xcp"
+)
+
+const uploadFile = "upload.json"
+
+type Spool struct {
+	Dir string
+}
+
+// An Upload is a request to the XCP endpoint
+type Upload struct {
+	ID           string            `json:"id"`
+	CreateTime   time.Time         `json:"createTime"`
+	Organization string            `json:"organization,omitempty"`
+	Module       string            `json:"module"`
+	Values       map[string]string `json:"values,omitempty"`
+	CIEnv        map[string]string `json:"ciEnv,omitempty"`
+	Files        []*File           `json:"files,omitempty"`
+	Attempts     int               `json:"attempts"`
+	LastError    string            `json:"lastError,omitempty"`
+
+	dir string
+}
+
+// A file attached to an upload.  The data of the file is saved
+// separately from the upload metadata.
+type File struct {
+	Param string `json:"param"`
+	Name  string `json:"name"`
+	Size  int    `json:"size"`
+
+	data []byte
+}
+
+func NewSpool() *Spool {
+	return &Spool{
+		Dir: filepath.Join(config.ConfigDir, "upload-spool"),
+	}
+}
+
+// Create an upload, capturing the CI environment of dir
+func NewUpload(org, module, dir string, values map[string]string) *Upload {
+	return &Upload{
+		CreateTime:   time.Now(),
+		Organization: org,
+		Module:       module,
+		Values:       values,
+		CIEnv:        xcp.GetCIEnv(dir),
+	}
+}
+
+func (u *Upload) AddFile(param, name string, data []byte) {
+	u.Files = append(u.Files, &File{
+		Param: param,
+		Name:  name,
+		Size:  len(data),
+		data:  data,
+	})
+}
+
+// Send the upload to the XCP endpoint
+func (u *Upload) Post(client *api.Client, options ...api.Option) (*jnode.Node, error) {
+	u.Attempts++
+	options = append(options, api.OptionFunc(func(req *resty.Request) {
+		req.SetMultipartFormData(u.CIEnv)
+	}))
+	for _, f := range u.Files {
+		options = append(options, xcp.WithFileFromReader(f.Param, f.Name, bytes.NewReader(f.data)))
+	}
+	org := u.Organization
+	if org == "" {
+		org = client.Organization
+	}
+	n, err := client.XCPPost(org, u.Module, nil, u.Values, options...)
+	if err != nil {
+		u.LastError = err.Error()
+	}
+	return n, err
+}
+
+// Save an upload in the spool
+func (s *Spool) Save(u *Upload) error {
+	if u.ID == "" {
+		u.ID = newUploadID(u.CreateTime)
+	}
+	u.dir = filepath.Join(s.Dir, u.ID)
+	if err := os.MkdirAll(u.dir, 0700); err != nil {
+		return err
+	}
+	for _, f := range u.Files {
+		if f.data == nil {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(u.dir, f.Param), f.data, 0600); err != nil {
+			return err
+		}
+	}
+	return u.saveMetadata()
+}
+
+func (u *Upload) saveMetadata() error {
+	d, err := json.MarshalIndent(u, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(u.dir, uploadFile), d, 0600)
+}
+
+func newUploadID(t time.Time) string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%s-%s", t.UTC().Format("20060102T150405"), hex.EncodeToString(b))
+}
+
+// Returns the uploads in the spool, oldest first.  The file data of
+// the uploads isn't read until the upload is replayed.
+func (s *Spool) List() ([]*Upload, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var uploads []*Upload
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		u, err := s.read(e.Name())
+		if err != nil {
+			log.Warnf("Ignoring invalid spooled upload {warning:%s} - {warning:%s}", e.Name(), err)
+			continue
+		}
+		uploads = append(uploads, u)
+	}
+	sort.Slice(uploads, func(i, j int) bool {
+		return uploads[i].CreateTime.Before(uploads[j].CreateTime)
+	})
+	return uploads, nil
+}
+
+func (s *Spool) read(id string) (*Upload, error) {
+	dir := filepath.Join(s.Dir, id)
+	d, err := os.ReadFile(filepath.Join(dir, uploadFile))
+	if err != nil {
+		return nil, err
+	}
+	u := &Upload{}
+	if err := json.Unmarshal(d, u); err != nil {
+		return nil, err
+	}
+	u.dir = dir
+	return u, nil
+}
+
+func (u *Upload) readFiles() error {
+	for _, f := range u.Files {
+		if f.data != nil {
+			continue
+		}
+		d, err := os.ReadFile(filepath.Join(u.dir, f.Param))
+		if err != nil {
+			return err
+		}
+		f.data = d
+	}
+	return nil
+}
+
+// Send a spooled upload, removing it from the spool if it succeeds
+func (u *Upload) Replay(client *api.Client) (*jnode.Node, error) {
+	if err := u.readFiles(); err != nil {
+		return nil, err
+	}
+	n, err := u.Post(client)
+	if err != nil {
+		if serr := u.saveMetadata(); serr != nil {
+			log.Warnf("Could not update spooled upload {warning:%s} - {warning:%s}", u.ID, serr)
+		}
+		return nil, err
+	}
+	return n, u.Remove()
+}
+
+func (u *Upload) Remove() error {
+	return os.RemoveAll(u.dir)
+}
+
+// Remove all the uploads from the spool, returning the number removed
+func (s *Spool) Purge() (int, error) {
+	uploads, err := s.List()
+	if err != nil {
+		return 0, err
+	}
+	for i, u := range uploads {
+		if err := u.Remove(); err != nil {
+			return i, err
+		}
+	}
+	return len(uploads), nil
+}
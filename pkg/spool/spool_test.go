@@ -0,0 +1,82 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spool
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpool(t *testing.T) {
+	assert := assert.New(t)
+	s := &Spool{Dir: t.TempDir()}
+	client := api.NewClient(&api.Config{
+		APIServer:    "https://api.example.com",
+		Organization: "9999",
+	})
+	httpmock.ActivateNonDefault(client.Client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	status := http.StatusBadGateway
+	httpmock.RegisterResponder("POST", "https://api.example.com/api/v1/xcp/test/data",
+		func(h *http.Request) (*http.Response, error) {
+			assert.Nil(h.ParseMultipartForm(1 << 20))
+			assert.Equal("hello", h.FormValue("FOO"))
+			assert.Equal("9999", h.Header.Get("X-SOLUBLE-ORG-ID"))
+			f, _, err := h.FormFile("results_json")
+			if assert.NoError(err) {
+				d, _ := io.ReadAll(f)
+				assert.Equal(`{"results":[]}`, string(d))
+			}
+			return httpmock.NewJsonResponse(status,
+				jnode.NewObjectNode().Put("assessment", jnode.NewObjectNode()))
+		})
+	u := NewUpload("", "test", ".", map[string]string{"FOO": "hello"})
+	u.AddFile("results_json", "results.json", []byte(`{"results":[]}`))
+	_, err := u.Post(client)
+	assert.True(api.IsTemporaryError(err))
+	assert.NoError(s.Save(u))
+
+	uploads, err := s.List()
+	assert.NoError(err)
+	if assert.Len(uploads, 1) {
+		u = uploads[0]
+		assert.Equal("test", u.Module)
+		assert.Equal(1, u.Attempts)
+		assert.Contains(u.LastError, "502")
+		assert.Equal(14, u.Files[0].Size)
+		_, err = u.Replay(client)
+		assert.Error(err)
+	}
+	uploads, _ = s.List()
+	if assert.Len(uploads, 1) {
+		assert.Equal(2, uploads[0].Attempts)
+		status = http.StatusOK
+		_, err = uploads[0].Replay(client)
+		assert.NoError(err)
+	}
+	uploads, _ = s.List()
+	assert.Len(uploads, 0)
+
+	assert.NoError(s.Save(NewUpload("", "test", ".", nil)))
+	n, err := s.Purge()
+	assert.NoError(err)
+	assert.Equal(1, n)
+}
@@ -3,10 +3,11 @@ package tools
 import (
 	"fmt"
 	"io/ioutil"
+	"strings"
 
 	"github.com/soluble-ai/soluble-cli/pkg/assessments"
-	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/options"
+	"github.com/soluble-ai/soluble-cli/pkg/repotree"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -18,15 +19,22 @@ type AssessmentOpts struct {
 	SaveResult            string
 	PrintResultValues     bool
 	SaveResultValues      string
+	SaveJUnit             string
 	DisableCustomPolicies bool
 	PrintFingerprints     bool
 	SaveFingerprints      string
 	CustomPoliciesDir     string
 	TestCustomPolicies    bool
 	FailThresholds        []string
+	SeverityCatalogFile   string
+	BaselineFile          string
+	WriteBaselineFile     string
 
 	parsedFailThresholds map[string]int
 	customPoliciesDir    *string
+	severityCatalog      assessments.SeverityCatalog
+	baseline             *assessments.Baseline
+	changes              *repotree.Changes
 }
 
 func (o *AssessmentOpts) GetAssessmentOptions() *AssessmentOpts {
@@ -44,11 +52,16 @@ func (o *AssessmentOpts) Register(c *cobra.Command) {
 	uploadFlag := flags.Lookup("upload")
 	_ = uploadFlag.Value.Set("true")
 	uploadFlag.Usage = "Upload report to Soluble.  Use --upload=false to disable."
+	if formatFlag := flags.Lookup("format"); formatFlag != nil {
+		formatFlag.Usage = fmt.Sprintf("%s  Assessments can also be printed as a report in these formats: %s.",
+			formatFlag.Usage, strings.Join(getReportFormatNames(), ", "))
+	}
 	o.GetAssessmentHiddenOptions().Register(c)
 	o.Path = []string{}
 	o.Columns = []string{
 		"sid", "severity", "pass", "title", "filePath", "line",
 	}
+	o.WideColumns = []string{"suppressionReason"}
 }
 
 func (o *AssessmentOpts) GetAssessmentHiddenOptions() *options.HiddenOptionsGroup {
@@ -71,12 +84,16 @@ The severity levels are critical, high, medium, low, and info in that order.`,
 			flags.BoolVar(&o.TestCustomPolicies, "test-custom-policies", false, "Test custom polices")
 			flags.BoolVar(&o.PrintResultOpt, "print-result", false, "Print the JSON result from the tool on stderr")
 			flags.StringVar(&o.SaveResult, "save-result", "", "Save the JSON reesult from the tool to `file`")
+			flags.StringVar(&o.SaveJUnit, "save-junit", "", "Save the findings as a JUnit XML report to `file`")
 			flags.BoolVar(&o.PrintResultValues, "print-result-values", false, "Print the result values from the tool on stderr")
 			flags.StringVar(&o.SaveResultValues, "save-result-values", "", "Save the result values from the tool to `file`")
 			flags.BoolVar(&o.PrintFingerprints, "print-fingerprints", false, "Print fingerprints on stderr before uploading results")
 			flags.StringVar(&o.SaveFingerprints, "save-fingerprints", "", "Save finding fingerprints to `file`")
 			flags.StringSliceVar(&o.FailThresholds, "fail", nil,
 				`Set failure thresholds in the form 'severity=count'.  The command will exit with exit code 2 if the assessments generated during this build have count or more failed findings of the specified severity.`)
+			flags.StringVar(&o.SeverityCatalogFile, "severity-catalog", "", "Read check severities for locally evaluated findings from `file`")
+			flags.StringVar(&o.BaselineFile, "baseline", "", "Suppress failed findings that are in the baseline `file`")
+			flags.StringVar(&o.WriteBaselineFile, "write-baseline", "", "Write the failed findings to the baseline `file`")
 		},
 	}
 }
@@ -90,8 +107,19 @@ func (o *AssessmentOpts) Validate() error {
 			return err
 		}
 	}
-	if len(o.FailThresholds) > 0 && !o.UploadEnabled {
-		return fmt.Errorf("using --fail requires --upload=true")
+	if o.SeverityCatalogFile != "" {
+		c, err := assessments.ReadSeverityCatalog(o.SeverityCatalogFile)
+		if err != nil {
+			return err
+		}
+		o.severityCatalog = assessments.GetDefaultSeverityCatalog().Merge(c)
+	}
+	if o.BaselineFile != "" {
+		b, err := assessments.ReadBaseline(o.BaselineFile)
+		if err != nil {
+			return err
+		}
+		o.baseline = b
 	}
 	parsedFailThresholds, err := assessments.ParseFailThresholds(o.FailThresholds)
 	if err != nil {
@@ -101,6 +129,15 @@ func (o *AssessmentOpts) Validate() error {
 	return nil
 }
 
+// Returns the catalog used to determine the severity of findings
+// that aren't assessed by the server
+func (o *AssessmentOpts) GetSeverityCatalog() assessments.SeverityCatalog {
+	if o.severityCatalog == nil {
+		o.severityCatalog = assessments.GetDefaultSeverityCatalog()
+	}
+	return o.severityCatalog
+}
+
 func (o *AssessmentOpts) GetCustomPoliciesDir() (string, error) {
 	if o.DisableCustomPolicies {
 		return "", nil
@@ -127,7 +164,7 @@ func (o *AssessmentOpts) GetCustomPoliciesDir() (string, error) {
 	if len(fs) == 0 {
 		var zero string
 		o.customPoliciesDir = &zero
-		log.Infof("{primary:%s} has no custom policies", o.Tool.Name())
+		o.Logger.Infof("{primary:%s} has no custom policies", o.Tool.Name())
 	} else {
 		o.customPoliciesDir = &d.Dir
 	}
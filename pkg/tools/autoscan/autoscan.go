@@ -15,10 +15,13 @@
 package autoscan
 
 import (
+	"bytes"
 	"fmt"
+	"os"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
-	"github.com/soluble-ai/soluble-cli/pkg/inventory"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	cfnpythonlint "github.com/soluble-ai/soluble-cli/pkg/tools/cfn-python-lint"
@@ -36,6 +39,7 @@ type Tool struct {
 	Skip             []string
 	ToolPaths        map[string]string
 	Images           []string
+	Concurrency      int
 }
 
 var _ tools.Consolidated = &Tool{}
@@ -43,6 +47,8 @@ var _ tools.Consolidated = &Tool{}
 type SubordinateTool struct {
 	tools.Single
 	Skip bool
+	// Identifies the tool in log output, if different from the name
+	Label string
 }
 
 func (*Tool) Name() string {
@@ -58,6 +64,14 @@ func (t *Tool) Register(cmd *cobra.Command) {
 	flags.StringToStringVar(&t.ToolPaths, "tool-paths", nil, "Explicitly specify the path to each tool in the form `tool=path`.")
 	flags.StringSliceVar(&t.Images, "image", nil, "Scan these docker images, as in the image-scan command.")
 	flags.BoolVar(&t.NoDocker, "no-docker", false, "Run all docker-based tools locally")
+	flags.IntVar(&t.Concurrency, "concurrency", 1, "Run up to `N` tools at the same time.  The output of each tool is prefixed with its name.")
+}
+
+func (t *Tool) Validate() error {
+	if t.Concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	return t.DirectoryBasedToolOpts.Validate()
 }
 
 func (t *Tool) CommandTemplate() *cobra.Command {
@@ -74,13 +88,15 @@ Everything               - secrets
 In addition, images can be scanned with trivy.
 `,
 		Example: `# To run a tool locally w/o using docker explicitly specify the tool path
-... auto-scan --tool-paths checkov=checkov,cfn-python-lint=cfn-lint`,
+... auto-scan --tool-paths checkov=checkov,cfn-python-lint=cfn-lint
+# To run up to 4 tools at the same time
+... auto-scan --concurrency 4`,
 		Hidden: true,
 	}
 }
 
 func (t *Tool) RunAll() (tools.Results, error) {
-	m := inventory.Do(t.GetDirectory())
+	m := t.GetInventory()
 	subTools := []SubordinateTool{
 		{
 			Single: &iacinventory.Local{
@@ -96,9 +112,9 @@ func (t *Tool) RunAll() (tools.Results, error) {
 		{
 			Single: &cfnpythonlint.Tool{
 				DirectoryBasedToolOpts: t.getDirectoryOpts(),
-				Templates:              m.CloudformationFiles.Values(),
+				Templates:              append(m.CloudformationFiles.Values(), m.SAMTemplates.Values()...),
 			},
-			Skip: m.CloudformationFiles.Len() == 0,
+			Skip: m.CloudformationFiles.Len() == 0 && m.SAMTemplates.Len() == 0,
 		},
 		{
 			Single: &secrets.Tool{
@@ -111,18 +127,18 @@ func (t *Tool) RunAll() (tools.Results, error) {
 			Single: &trivy.Tool{
 				Image: image,
 			},
+			Label: fmt.Sprintf("trivy %s", image),
 		})
 	}
-	count := 0
-	var (
-		errs    error
-		results []*tools.Result
-	)
+	return t.runSubordinateTools(subTools)
+}
+
+func (t *Tool) runSubordinateTools(subTools []SubordinateTool) (tools.Results, error) {
+	var runTools []SubordinateTool
 	for _, st := range subTools {
 		if st.Skip || util.StringSliceContains(t.Skip, st.Name()) {
 			continue
 		}
-		count++
 		opts := st.GetAssessmentOptions()
 		opts.Tool = st
 		opts.UploadEnabled = t.UploadEnabled
@@ -130,21 +146,83 @@ func (t *Tool) RunAll() (tools.Results, error) {
 		opts.NoDocker = t.NoDocker
 		// Note - we don't propagate --exclude down, consider instead
 		// removing the --exclude flag since that should be done server-side
-		log.Infof("Running {info:%s}", opts.Tool.Name())
-		toolResult, toolErr := tools.RunSingleAssessment(st)
-		if toolResult != nil {
-			results = append(results, toolResult)
+		runTools = append(runTools, st)
+	}
+	// Results and errors are kept in the order the tools are listed
+	// regardless of the order that they finish in
+	results := make([]*tools.Result, len(runTools))
+	errs := make([]error, len(runTools))
+	sem := make(chan struct{}, t.Concurrency)
+	var wg sync.WaitGroup
+	for i := range runTools {
+		i := i
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i], errs[i] = t.runSubordinateTool(runTools[i])
+		}()
+	}
+	wg.Wait()
+	var (
+		err        error
+		allResults []*tools.Result
+	)
+	for i, st := range runTools {
+		if results[i] != nil {
+			allResults = append(allResults, results[i])
 		}
-		if toolErr != nil {
-			errs = multierror.Append(errs, fmt.Errorf("%s failed - %w", st.Name(), toolErr))
+		if errs[i] != nil {
+			err = multierror.Append(err, fmt.Errorf("%s failed - %w", st.getLabel(), errs[i]))
 		}
 	}
-	log.Infof("Finished running {primary:%d} tools", count)
-	return results, errs
+	log.Infof("Finished running {primary:%d} tools", len(runTools))
+	return allResults, err
+}
+
+func (t *Tool) runSubordinateTool(st SubordinateTool) (*tools.Result, error) {
+	if t.Concurrency == 1 {
+		log.Infof("Running {info:%s}", st.Name())
+		return tools.RunSingleAssessment(st)
+	}
+	// When tools run concurrently each tool's output and log messages
+	// are prefixed with its name and buffered until the tool finishes,
+	// so that the output of different tools isn't interleaved
+	label := st.getLabel()
+	opts := st.GetAssessmentOptions()
+	// (the prefix writers serialize their writes to the buffer)
+	output := &bytes.Buffer{}
+	stdout := tools.NewPrefixWriter(output, fmt.Sprintf("[%s] ", label))
+	stderr := tools.NewPrefixWriter(output, fmt.Sprintf("[%s] ", label))
+	opts.Stdout = stdout
+	opts.Stderr = stderr
+	opts.Logger = log.NewLogger(stderr)
+	defer func() {
+		_ = stdout.Flush()
+		_ = stderr.Flush()
+		_, _ = os.Stderr.Write(output.Bytes())
+	}()
+	log.Infof("Starting {info:%s}", label)
+	start := time.Now()
+	result, err := tools.RunSingleAssessment(st)
+	log.Infof("Finished {info:%s} in {secondary:%s}", label, time.Since(start).Truncate(time.Millisecond))
+	return result, err
+}
+
+func (st SubordinateTool) getLabel() string {
+	if st.Label != "" {
+		return st.Label
+	}
+	return st.Name()
 }
 
 func (t *Tool) getDirectoryOpts() tools.DirectoryBasedToolOpts {
 	return tools.DirectoryBasedToolOpts{
-		DirectoryOpt: tools.DirectoryOpt{Directory: t.GetDirectory()},
+		DirectoryOpt:    tools.DirectoryOpt{Directory: t.GetDirectory()},
+		ChangedSince:    t.ChangedSince,
+		ScanChangedOnly: t.ScanChangedOnly,
 	}
 }
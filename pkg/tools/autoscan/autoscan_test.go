@@ -0,0 +1,133 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autoscan
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/exit"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/stretchr/testify/assert"
+)
+
+type sleepyTool struct {
+	tools.AssessmentOpts
+	name    string
+	sleep   time.Duration
+	fail    bool
+	finding *assessments.Finding
+	running *int32
+	max     *int32
+}
+
+func (t *sleepyTool) Name() string {
+	return t.name
+}
+
+func (t *sleepyTool) Validate() error {
+	if len(t.FailThresholds) > 0 {
+		return t.AssessmentOpts.Validate()
+	}
+	return nil
+}
+
+func (t *sleepyTool) Run() (*tools.Result, error) {
+	n := atomic.AddInt32(t.running, 1)
+	defer atomic.AddInt32(t.running, -1)
+	for {
+		m := atomic.LoadInt32(t.max)
+		if n <= m || atomic.CompareAndSwapInt32(t.max, m, n) {
+			break
+		}
+	}
+	fmt.Fprintf(t.GetStderr(), "output from %s\n", t.name)
+	t.Logger.Infof("log from %s", t.name)
+	time.Sleep(t.sleep)
+	if t.fail {
+		return nil, fmt.Errorf("%s failed", t.name)
+	}
+	result := &tools.Result{Data: jnode.NewObjectNode()}
+	if t.finding != nil {
+		result.Findings = assessments.Findings{t.finding}
+	}
+	return result, nil
+}
+
+func TestRunSubordinateTools(t *testing.T) {
+	assert := assert.New(t)
+	var running, max int32
+	newTool := func(name string, sleep time.Duration, fail bool) SubordinateTool {
+		return SubordinateTool{Single: &sleepyTool{
+			name: name, sleep: sleep, fail: fail, running: &running, max: &max,
+		}}
+	}
+	logOutput := &bytes.Buffer{}
+	defer func(w io.Writer) { color.Output = w }(color.Output)
+	color.Output = logOutput
+	at := &Tool{Concurrency: 2}
+	results, err := at.runSubordinateTools([]SubordinateTool{
+		newTool("a", 30*time.Millisecond, false),
+		newTool("b", 10*time.Millisecond, true),
+		newTool("c", 0, false),
+		{Single: &sleepyTool{name: "d"}, Skip: true},
+		newTool("e", 10*time.Millisecond, true),
+	})
+	assert.Equal(int32(2), max)
+	if assert.Len(results, 2) {
+		assert.Equal("a", results[0].Tool.Name())
+		assert.Equal("c", results[1].Tool.Name())
+	}
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "2 errors occurred")
+		assert.Regexp("(?s)b failed.*e failed", err.Error())
+	}
+	// the tools log to their own buffered output
+	assert.Contains(logOutput.String(), "Starting")
+	assert.NotContains(logOutput.String(), "log from")
+}
+
+func TestRunSubordinateToolsFail(t *testing.T) {
+	assert := assert.New(t)
+	defer func() {
+		exit.Code = 0
+		exit.Func = nil
+	}()
+	var running, max int32
+	newTool := func(name string) SubordinateTool {
+		st := &sleepyTool{
+			name: name, sleep: 10 * time.Millisecond, running: &running, max: &max,
+			finding: &assessments.Finding{
+				FilePath: "main.tf",
+				Tool:     map[string]string{"rule_id": "X1", "severity": "high"},
+			},
+		}
+		st.FailThresholds = []string{"high=1"}
+		return SubordinateTool{Single: st}
+	}
+	at := &Tool{Concurrency: 2}
+	results, err := at.runSubordinateTools([]SubordinateTool{newTool("a"), newTool("b")})
+	assert.NoError(err)
+	assert.Len(results, 2)
+	assert.Equal(2, exit.Code)
+	assert.NotNil(exit.Func)
+}
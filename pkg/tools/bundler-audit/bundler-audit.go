@@ -33,7 +33,7 @@ func (t *Tool) Name() string { return "bundler-audit" }
 
 func (t *Tool) Run() (*tools.Result, error) {
 	args := []string{
-		"check", "--quiet", "--format", "json", ".",
+		"check", "--quiet", "--format", "json",
 	}
 	d, err := t.RunDocker(&tools.DockerTool{
 		Name:                "bundler-audit",
@@ -41,6 +41,7 @@ func (t *Tool) Run() (*tools.Result, error) {
 		DefaultNoDockerName: "bundler-audit",
 		Directory:           t.GetDirectory(),
 		Args:                args,
+		PositionalArgs:      []string{"."},
 	})
 	if err != nil && tools.IsDockerError(err) {
 		return nil, err
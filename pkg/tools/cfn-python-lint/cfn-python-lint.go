@@ -16,7 +16,6 @@ package cfnpythonlint
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/soluble-ai/go-jnode"
 	"github.com/soluble-ai/soluble-cli/pkg/assessments"
@@ -59,7 +58,8 @@ func (t *Tool) Run() (*tools.Result, error) {
 		DefaultNoDockerName: "cfn-lint",
 		Image:               "gcr.io/soluble-repo/soluble-cfn-lint:latest",
 		Directory:           t.GetDirectory(),
-		Args:                append([]string{"-f", "json"}, files...),
+		Args:                []string{"-f", "json"},
+		PositionalArgs:      files,
 	})
 	if err != nil && tools.IsDockerError(err) {
 		return nil, err
@@ -67,7 +67,7 @@ func (t *Tool) Run() (*tools.Result, error) {
 	results, err := jnode.FromJSON(d)
 	if err != nil {
 		if d != nil {
-			os.Stderr.Write(d)
+			_, _ = t.GetStderr().Write(d)
 		}
 		return nil, err
 	}
@@ -100,5 +100,6 @@ func (t *Tool) findCloudformationFiles() ([]string, error) {
 	if len(t.Templates) > 0 {
 		return t.GetFilesInDirectory(t.Templates)
 	}
-	return t.GetInventory().CloudformationFiles.Values(), nil
+	m := t.GetInventory()
+	return append(m.CloudformationFiles.Values(), m.SAMTemplates.Values()...), nil
 }
@@ -57,10 +57,11 @@ func (t *Tool) Run() (*tools.Result, error) {
 		return nil, fmt.Errorf("no cloudformation templates found")
 	}
 	d, err := t.RunDocker(&tools.DockerTool{
-		Name:      "cfn_nag",
-		Image:     "stelligent/cfn_nag:latest",
-		Directory: t.GetDirectory(),
-		Args:      append([]string{"--output-format=json"}, files...),
+		Name:           "cfn_nag",
+		Image:          "stelligent/cfn_nag:latest",
+		Directory:      t.GetDirectory(),
+		Args:           []string{"--output-format=json"},
+		PositionalArgs: files,
 	})
 	if err != nil && tools.IsDockerError(err) {
 		return nil, err
@@ -106,5 +107,6 @@ func (t *Tool) findCloudformationFiles() ([]string, error) {
 	if len(t.Templates) > 0 {
 		return t.GetFilesInDirectory(t.Templates)
 	}
-	return t.GetInventory().CloudformationFiles.Values(), nil
+	m := t.GetInventory()
+	return append(m.CloudformationFiles.Values(), m.SAMTemplates.Values()...), nil
 }
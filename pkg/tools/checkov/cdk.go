@@ -7,7 +7,6 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/spf13/cobra"
@@ -75,7 +74,7 @@ func (cdk *CDK) Run() (*tools.Result, error) {
 		synth.Stdout = os.Stderr
 		cdk.LogCommand(synth)
 		if err := synth.Run(); err != nil {
-			log.Errorf("{primary:cdk synth} failed.  Run cdk synth manually and use {primary:--cdk-synth=false}.")
+			cdk.Logger.Errorf("{primary:cdk synth} failed.  Run cdk synth manually and use {primary:--cdk-synth=false}.")
 			return nil, err
 		}
 	}
@@ -24,7 +24,6 @@ import (
 	"github.com/soluble-ai/go-jnode"
 	"github.com/soluble-ai/soluble-cli/pkg/assessments"
 	"github.com/soluble-ai/soluble-cli/pkg/download"
-	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/spf13/cobra"
@@ -37,6 +36,9 @@ type Tool struct {
 	VarFiles             []string
 
 	extraArgs tools.ExtraArgs
+	// if set, the directory contains the output of kustomize build for
+	// this kustomization file
+	kustomization string
 }
 
 var _ tools.Single = &Tool{}
@@ -139,13 +141,13 @@ func (t *Tool) Run() (*tools.Result, error) {
 	dat, err := t.RunDocker(dt)
 	if err != nil {
 		if dat != nil {
-			_, _ = os.Stderr.Write(dat)
+			_, _ = t.GetStderr().Write(dat)
 		}
 		return nil, err
 	}
 	n, err := jnode.FromJSON(dat)
 	if err != nil {
-		_, _ = os.Stderr.Write(dat)
+		_, _ = t.GetStderr().Write(dat)
 		return nil, err
 	}
 	result := t.processResults(n)
@@ -171,7 +173,7 @@ func (t *Tool) makeHelmAvailable() error {
 		} else {
 			path = fmt.Sprintf("%s%c%s", path, os.PathListSeparator, dir)
 		}
-		log.Infof("Adding {info:%s} to PATH", dir)
+		t.Logger.Infof("Adding {info:%s} to PATH", dir)
 		os.Setenv("PATH", path)
 	}
 	return nil
@@ -233,6 +235,11 @@ func (t *Tool) processChecks(result *tools.Result, checks *jnode.Node, checkType
 				n.Put("file_path", filePath)
 			}
 		}
+		if t.kustomization != "" {
+			// the rendered objects don't exist in the repo, so report
+			// them against the kustomization that generates them
+			n.Put("file_path", t.kustomization)
+		}
 	}
 	checks = util.RemoveJNodeElementsIf(checks, func(e *jnode.Node) bool {
 		return t.IsExcluded(e.Path("file_path").AsText())
@@ -243,6 +250,7 @@ func (t *Tool) processChecks(result *tools.Result, checks *jnode.Node, checkType
 			Tool: map[string]string{
 				"check_id":   n.Path("check_id").AsText(),
 				"check_type": checkType,
+				"end_line":   n.Path("file_line_range").Get(1).AsText(),
 			},
 			FilePath:      path,
 			Line:          n.Path("file_line_range").Get(0).AsInt(),
@@ -250,6 +258,11 @@ func (t *Tool) processChecks(result *tools.Result, checks *jnode.Node, checkType
 			Title:         n.Path("check_name").AsText(),
 			GeneratedFile: t.isGeneratedFile(path),
 		}
+		if t.kustomization != "" {
+			finding.Line = 0
+			delete(finding.Tool, "end_line")
+			finding.SetAttribute("resource", n.Path("resource").AsText())
+		}
 		if t.RepoRoot != "" {
 			// we run checkov in the repo root with the -d argument
 			// pointing to the actual directory, so in this case
@@ -0,0 +1,149 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checkov
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/soluble-ai/soluble-cli/pkg/download"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/spf13/cobra"
+)
+
+// Kustomize builds each kustomization with kustomize and scans the
+// rendered objects with checkov.  Findings are reported against the
+// kustomization file of the overlay.
+type Kustomize struct {
+	tools.DirectoryBasedToolOpts
+}
+
+var _ tools.Consolidated = (*Kustomize)(nil)
+
+var kustomizationFileNames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+func (k *Kustomize) Name() string {
+	return "checkov-kustomize"
+}
+
+func (k *Kustomize) CommandTemplate() *cobra.Command {
+	return &cobra.Command{
+		Use:   "kustomize",
+		Short: "Build kustomize overlays and scan the rendered manifests with checkov",
+	}
+}
+
+func (k *Kustomize) RunAll() (tools.Results, error) {
+	var (
+		results tools.Results
+		errs    error
+	)
+	targets := k.GetInventory().GetKustomizeTargets()
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no kustomizations found under %s", k.GetDirectory())
+	}
+	kustomize, err := getKustomize()
+	if err != nil {
+		return nil, err
+	}
+	for _, target := range targets {
+		result, err := k.scanKustomization(kustomize, target)
+		if err != nil {
+			// keep going with the other kustomizations
+			errs = multierror.Append(errs, fmt.Errorf("could not scan %s - %w", target, err))
+		} else {
+			results = append(results, result)
+		}
+	}
+	return results, errs
+}
+
+func (k *Kustomize) scanKustomization(kustomize, target string) (*tools.Result, error) {
+	dir := filepath.Join(k.GetDirectory(), target)
+	k.Logger.Infof("Building kustomization {primary:%s}", target)
+	c := exec.Command(kustomize, "build", dir)
+	stderr := &bytes.Buffer{}
+	c.Stderr = stderr
+	out, err := c.Output()
+	if err != nil {
+		return nil, fmt.Errorf("kustomize build failed: %w %s", err, strings.TrimSpace(stderr.String()))
+	}
+	renderDir, err := os.MkdirTemp("", "kustomize*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(renderDir)
+	if err := os.WriteFile(filepath.Join(renderDir, "rendered.yaml"), out, 0600); err != nil {
+		return nil, err
+	}
+	resultDir := k.RepoRoot
+	if resultDir == "" {
+		resultDir = k.GetDirectory()
+	}
+	checkov := &Tool{
+		DirectoryBasedToolOpts: k.DirectoryBasedToolOpts,
+		Framework:              "kubernetes",
+		kustomization:          filepath.ToSlash(tools.MustRel(resultDir, findKustomizationFile(dir))),
+	}
+	checkov.RepoRoot = ""
+	checkov.SetDirectory(renderDir)
+	checkov.Tool = checkov
+	result, err := checkov.Run()
+	if err != nil {
+		return nil, err
+	}
+	// the result is for the kustomization in the repo, not the
+	// temporary directory
+	checkov.RepoRoot = k.RepoRoot
+	checkov.SetDirectory(dir)
+	result.Directory = resultDir
+	result.Tool = checkov
+	if k.RepoRoot != "" {
+		for _, f := range result.Findings {
+			f.RepoPath = f.FilePath
+		}
+	}
+	return result, nil
+}
+
+func findKustomizationFile(dir string) string {
+	for _, name := range kustomizationFileNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join(dir, kustomizationFileNames[0])
+}
+
+func getKustomize() (string, error) {
+	if path, err := exec.LookPath("kustomize"); err == nil {
+		return path, nil
+	}
+	// kustomize is not installed, so install it from github
+	installer := &tools.RunOpts{}
+	d, err := installer.InstallTool(&download.Spec{
+		URL: "github.com/kubernetes-sigs/kustomize",
+	})
+	if err != nil {
+		return "", err
+	}
+	return d.GetExePath("kustomize"), nil
+}
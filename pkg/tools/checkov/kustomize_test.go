@@ -0,0 +1,66 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checkov
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKustomizeResults(t *testing.T) {
+	assert := assert.New(t)
+	tool := &Tool{
+		Framework:     "kubernetes",
+		kustomization: "k8s/overlays/prod/kustomization.yaml",
+	}
+	results, err := jnode.FromJSON([]byte(`{
+		"check_type": "kubernetes",
+		"results": {
+			"failed_checks": [
+				{
+					"check_id": "CKV_K8S_8",
+					"check_name": "Liveness Probe Should be Configured",
+					"file_path": "/rendered.yaml",
+					"file_line_range": [20, 45],
+					"resource": "Deployment.default.web"
+				}
+			]
+		},
+		"summary": { "checkov_version": "2.0.1" }
+	}`))
+	assert.NoError(err)
+	result := tool.processResults(results)
+	if assert.Len(result.Findings, 1) {
+		f := result.Findings[0]
+		assert.Equal("k8s/overlays/prod/kustomization.yaml", f.FilePath)
+		assert.Equal(0, f.Line)
+		assert.Equal("Deployment.default.web", f.Tool["resource"])
+		assert.False(f.Pass)
+	}
+	assert.Equal("k8s/overlays/prod/kustomization.yaml",
+		result.Data.Path("results").Path("failed_checks").Get(0).Path("file_path").AsText())
+}
+
+func TestFindKustomizationFile(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	assert.Equal(filepath.Join(dir, "kustomization.yaml"), findKustomizationFile(dir))
+	assert.NoError(os.WriteFile(filepath.Join(dir, "Kustomization"), []byte("resources: []\n"), 0600))
+	assert.Equal(filepath.Join(dir, "Kustomization"), findKustomizationFile(dir))
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ciscan
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/ciscan"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/spf13/cobra"
+)
+
+type Tool struct {
+	tools.DirectoryBasedToolOpts
+}
+
+var _ tools.Single = (*Tool)(nil)
+
+func (t *Tool) Name() string {
+	return "ci-scan"
+}
+
+func (t *Tool) CommandTemplate() *cobra.Command {
+	return &cobra.Command{
+		Use:   "ci-scan",
+		Short: "Scan CI pipelines for security problems",
+	}
+}
+
+func (t *Tool) Run() (*tools.Result, error) {
+	dir := t.GetDirectory()
+	files := t.GetInventory().CIConfigFiles.Values()
+	if len(files) == 0 {
+		log.Infof("No CI configuration files found in {primary:%s}", dir)
+	}
+	data := jnode.NewObjectNode()
+	results := data.PutArray("results")
+	findings := assessments.Findings{}
+	for _, file := range files {
+		system := ciscan.GetSystem(file)
+		content, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			return nil, err
+		}
+		issues, err := ciscan.Check(system, content)
+		if err != nil {
+			log.Warnf("Could not check {info:%s} - {warning:%s}", file, err)
+			continue
+		}
+		for _, issue := range issues {
+			results.AppendObject().
+				Put("file", filepath.ToSlash(file)).
+				Put("system", system).
+				Put("rule_id", issue.ID).
+				Put("title", issue.Title).
+				Put("severity", issue.Severity).
+				Put("line", issue.Line).
+				Put("detail", issue.Detail)
+			findings = append(findings, &assessments.Finding{
+				FilePath: file,
+				Line:     issue.Line,
+				Title:    issue.Title,
+				Tool: map[string]string{
+					"rule_id":  issue.ID,
+					"severity": issue.Severity,
+					"system":   system,
+					"detail":   issue.Detail,
+				},
+			})
+		}
+	}
+	if len(files) > 0 {
+		log.Infof("Checked {primary:%d} CI configuration files", len(files))
+	}
+	return &tools.Result{
+		Directory: dir,
+		Data:      data,
+		Findings:  findings,
+	}, nil
+}
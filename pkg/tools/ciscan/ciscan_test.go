@@ -0,0 +1,54 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ciscan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	workflows := filepath.Join(dir, ".github", "workflows")
+	assert.NoError(os.MkdirAll(workflows, 0700))
+	assert.NoError(os.WriteFile(filepath.Join(workflows, "ci.yml"), []byte(`on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v3
+      - uses: example/action@v1
+`), 0600))
+	tool := &Tool{}
+	tool.Directory = dir
+	tool.Tool = tool
+	assert.NoError(tool.Validate())
+	result, err := tool.Run()
+	assert.NoError(err)
+	if assert.Len(result.Findings, 1) {
+		f := result.Findings[0]
+		assert.Equal(filepath.Join(".github", "workflows", "ci.yml"), f.FilePath)
+		assert.Equal(7, f.Line)
+		assert.Equal("CI_UNPINNED_DEPENDENCY", f.GetRuleID())
+		assert.Equal("github", f.Tool["system"])
+	}
+	r := result.Data.Path("results").Get(0)
+	assert.Equal(".github/workflows/ci.yml", r.Path("file").AsText())
+	assert.Equal("example/action@v1", r.Path("detail").AsText())
+}
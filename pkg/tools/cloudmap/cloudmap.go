@@ -66,6 +66,7 @@ func (t *Tool) Run() error {
 		args = append(args, "--state-file", t.StateFile)
 	}
 	args = append(args, t.extraArgs...)
+	args = append(args, t.ExtraToolArgs...)
 	// #nosec G204
 	c := exec.Command(d.GetExePath("tfscore"), args...)
 	c.Stderr = os.Stderr
@@ -17,10 +17,13 @@ package tools
 import (
 	"fmt"
 
+	"github.com/hashicorp/go-multierror"
 	"github.com/soluble-ai/go-jnode"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/print"
+	"github.com/soluble-ai/soluble-cli/pkg/repotree"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 type HasCommandTemplate interface {
@@ -42,12 +45,75 @@ func CreateCommand(tool Interface) *cobra.Command {
 		}
 	}
 	c.RunE = func(cmd *cobra.Command, args []string) error {
+		if sub := findDefaultToolCommand(cmd, tool); sub != nil {
+			return sub.RunE(sub, args)
+		}
 		return runTool(tool)
 	}
 	tool.Register(c)
 	return c
 }
 
+// If the command has sub-commands, returns the sub-command that the
+// config file chooses as the default tool for the command, or nil.
+// The flags given to the command are copied to the sub-command.
+func findDefaultToolCommand(cmd *cobra.Command, tool Interface) *cobra.Command {
+	if !cmd.HasSubCommands() {
+		return nil
+	}
+	dir := "."
+	if f := cmd.Flags().Lookup("directory"); f != nil && f.Value.String() != "" {
+		dir = f.Value.String()
+	}
+	root, err := repotree.FindRepoRoot(dir)
+	if err != nil {
+		return nil
+	}
+	config := tool.GetToolOptions().getConfig(root)
+	name := config.GetDefaultTool(cmd.Name())
+	if name == "" || name == tool.Name() {
+		return nil
+	}
+	var sub *cobra.Command
+	for _, c := range cmd.Commands() {
+		if c.Name() == name && c.RunE != nil {
+			sub = c
+			break
+		}
+	}
+	if sub == nil {
+		log.Warnf("The default tool {warning:%s} for {info:%s} in {info:%s} is not a sub-command of {info:%s}",
+			name, cmd.Name(), config.path, cmd.CommandPath())
+		return nil
+	}
+	var flagErr error
+	local := cmd.LocalFlags()
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		sf := sub.Flags().Lookup(f.Name)
+		if sf == nil || local.Lookup(f.Name) == nil {
+			return
+		}
+		var err error
+		sv, ok := f.Value.(pflag.SliceValue)
+		ssv, subOk := sf.Value.(pflag.SliceValue)
+		if ok && subOk {
+			err = ssv.Replace(sv.GetSlice())
+			sf.Changed = true
+		} else {
+			err = sub.Flags().Set(f.Name, f.Value.String())
+		}
+		if err != nil {
+			flagErr = multierror.Append(flagErr, err)
+		}
+	})
+	if flagErr != nil {
+		log.Warnf("Could not run the default tool {warning:%s} - {warning:%s}", name, flagErr)
+		return nil
+	}
+	log.Infof("Running {primary:%s}, the default tool for {info:%s} in {info:%s}", name, cmd.Name(), config.path)
+	return sub
+}
+
 func runTool(tool Interface) error {
 	opts := tool.GetToolOptions()
 	opts.Tool = tool
@@ -79,6 +145,24 @@ func runTool(tool Interface) error {
 			log.Infof("Asessment uploaded, see {primary:%s} for more information", result.Assessment.URL)
 		}
 	}
+	if ao, ok := tool.(interface{ GetAssessmentOptions() *AssessmentOpts }); ok && toolErr == nil {
+		if path := ao.GetAssessmentOptions().WriteBaselineFile; path != "" {
+			if err := results.writeBaseline(path); err != nil {
+				return err
+			}
+		}
+		if path := ao.GetAssessmentOptions().SaveJUnit; path != "" {
+			if err := results.saveJUnit(path); err != nil {
+				return err
+			}
+		}
+	}
+	if report := reportFormats[opts.OutputFormat]; report != nil {
+		if toolErr != nil {
+			return toolErr
+		}
+		return report(opts.GetOutputWriter(), results)
+	}
 	var (
 		n   *jnode.Node
 		err error
@@ -16,21 +16,98 @@ package tools
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
 
 	ignore "github.com/sabhiram/go-gitignore"
 	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"gopkg.in/yaml.v3"
 )
 
+// The config file is .lacework/config.yml in the root of the repository:
+//
+//	# Don't scan files that match these patterns
+//	ignore:
+//	  - vendor/**
+//	# The tool that a scan command runs when no sub-command is given
+//	default-tools:
+//	  terraform-scan: tfsec
+//	# Configuration for each tool
+//	tools:
+//	  checkov:
+//	    # Use this version of the tool, as with --tool-version
+//	    version: bridgecrew/checkov:2.0.1
+//	    # Pass these extra arguments to the tool
+//	    extra-args: ["--download-external-modules", "true"]
+//	    # Suppress findings for these rules
+//	    skip: [CKV_AWS_18]
+//	    # Suppress findings for rules in files that match paths.  If
+//	    # rules is empty, then all findings in the files are suppressed.
+//	    ignore-rules:
+//	      - paths: ["test/**"]
+//	        rules: [CKV_AWS_19, CKV_AWS_20]
+//	        reason: test fixtures
+//	    # Set the severity of findings for rules
+//	    severities:
+//	      CKV_AWS_21: high
+//	# How the infrastructure-as-code inventory is found
+//	inventory:
+//	  # Skip directories with these names, replacing the default list
+//	  # of vendored directories such as node_modules
+//	  skip-directories: [node_modules, third_party]
+//	  # Don't look inside files larger than this many bytes
+//	  max-file-size: 1048576
 type Config struct {
-	path   string
-	data   *jnode.Node
+	path         string
+	data         *jnode.Node
+	ignore       *ignore.GitIgnore
+	DefaultTools map[string]string      `yaml:"default-tools"`
+	Tools        map[string]*ToolConfig `yaml:"tools"`
+	Inventory    *InventoryConfig       `yaml:"inventory"`
+}
+
+type InventoryConfig struct {
+	SkipDirectories []string `yaml:"skip-directories"`
+	MaxFileSize     int64    `yaml:"max-file-size"`
+}
+
+// The configuration of a tool in the tools section of the config file
+type ToolConfig struct {
+	Version     string            `yaml:"version"`
+	ExtraArgs   []string          `yaml:"extra-args"`
+	Skip        []string          `yaml:"skip"`
+	IgnoreRules []*RuleIgnore     `yaml:"ignore-rules"`
+	Severities  map[string]string `yaml:"severities"`
+
+	configPath string
+}
+
+type RuleIgnore struct {
+	Paths  []string `yaml:"paths"`
+	Rules  []string `yaml:"rules"`
+	Reason string   `yaml:"reason"`
+
 	ignore *ignore.GitIgnore
 }
 
+// Returns the path of the config file in repoRoot, and true if the
+// path is the deprecated .soluble/config.yml.  The file may not exist.
+func FindConfigFile(repoRoot string) (string, bool) {
+	oldConfig := filepath.Join(repoRoot, ".soluble", "config.yml")
+	newConfig := filepath.Join(repoRoot, ".lacework", "config.yml")
+	if util.FileExists(oldConfig) && !util.FileExists(newConfig) {
+		return oldConfig, true
+	}
+	return newConfig, false
+}
+
 func (c *Config) IsIgnored(path string) bool {
 	if c.ignore == nil {
 		v := c.data.Path("ignore")
@@ -66,5 +143,115 @@ func ReadConfigFile(path string) *Config {
 	}
 	c.data = jnode.FromMap(m)
 	c.path = path
+	if err != nil {
+		return c
+	}
+	if err := yaml.Unmarshal(d, c); err != nil {
+		log.Warnf("Could not read the tool configuration in {warning:%s} - {warning:%s}", path, err)
+	}
+	for name, tc := range c.Tools {
+		if tc == nil {
+			c.Tools[name] = &ToolConfig{}
+			continue
+		}
+		tc.configPath = path
+		for id, severity := range tc.Severities {
+			s := assessments.NormalizeSeverity(severity)
+			if s == "" {
+				log.Warnf("Ignoring invalid severity {warning:%s} for {info:%s %s} in {info:%s}",
+					severity, name, id, path)
+				delete(tc.Severities, id)
+				continue
+			}
+			tc.Severities[id] = s
+		}
+	}
 	return c
 }
+
+// Returns the tool that a scan command should run when no sub-command is
+// given, or ""
+func (c *Config) GetDefaultTool(command string) string {
+	return c.DefaultTools[command]
+}
+
+// Returns the configuration for a tool, which is empty if the tool
+// isn't configured
+func (c *Config) GetToolConfig(name string) *ToolConfig {
+	if tc := c.Tools[name]; tc != nil {
+		return tc
+	}
+	return &ToolConfig{}
+}
+
+func matchesRuleID(patterns []string, id string) bool {
+	for _, p := range patterns {
+		if strings.EqualFold(p, id) {
+			return true
+		}
+		if ok, _ := path.Match(p, id); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (ri *RuleIgnore) matches(f *assessments.Finding) bool {
+	if ri.ignore == nil {
+		ri.ignore = ignore.CompileIgnoreLines(ri.Paths...)
+	}
+	file := f.RepoPath
+	if file == "" {
+		file = f.FilePath
+	}
+	if file == "" || !ri.ignore.MatchesPath(filepath.ToSlash(file)) {
+		return false
+	}
+	return len(ri.Rules) == 0 || matchesRuleID(ri.Rules, f.GetRuleID())
+}
+
+// Suppress the failed findings for rules that are skipped or ignored,
+// returning the number of findings suppressed
+func (tc *ToolConfig) applyRuleIgnores(findings assessments.Findings) int {
+	if len(tc.Skip) == 0 && len(tc.IgnoreRules) == 0 {
+		return 0
+	}
+	count := 0
+	for _, f := range findings {
+		if f.Pass || f.Suppressed {
+			continue
+		}
+		if matchesRuleID(tc.Skip, f.GetRuleID()) {
+			f.Suppress(assessments.SuppressedByConfig, fmt.Sprintf("skipped in %s", tc.configPath))
+			count++
+			continue
+		}
+		for _, ri := range tc.IgnoreRules {
+			if ri.matches(f) {
+				reason := ri.Reason
+				if reason == "" {
+					reason = fmt.Sprintf("ignored in %s", tc.configPath)
+				}
+				f.Suppress(assessments.SuppressedByConfig, reason)
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// Set the severity of findings for rules that have a severity in the config
+func (tc *ToolConfig) applySeverities(findings assessments.Findings) {
+	if len(tc.Severities) == 0 {
+		return
+	}
+	for _, f := range findings {
+		for id, severity := range tc.Severities {
+			if strings.EqualFold(id, f.GetRuleID()) {
+				f.Severity = severity
+				break
+			}
+		}
+	}
+}
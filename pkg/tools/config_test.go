@@ -15,13 +15,101 @@
 package tools
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 )
 
+const testToolConfig = `
+ignore:
+  - vendor/**
+default-tools:
+  terraform-scan: tfsec
+tools:
+  checkov:
+    version: bridgecrew/checkov:2.0.1
+    extra-args: [--quiet]
+    skip: [CKV_AWS_18, CKV_K8S_*]
+    ignore-rules:
+      - paths: ["test/**"]
+        rules: [ckv_aws_19]
+        reason: test fixtures
+      - paths: [examples/]
+    severities:
+      CKV_AWS_21: HIGH
+      CKV_AWS_22: bogus
+  tfsec:
+`
+
+func writeTestConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte(testToolConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
 func TestConfig(t *testing.T) {
 	assert := assert.New(t)
 	o := &ToolOpts{}
 	assert.NotNil(o.GetConfig())
 }
+
+func TestToolConfig(t *testing.T) {
+	assert := assert.New(t)
+	c := ReadConfigFile(writeTestConfig(t))
+	assert.True(c.IsIgnored("vendor/x/main.tf"))
+	assert.Equal("tfsec", c.GetDefaultTool("terraform-scan"))
+	assert.Equal("", c.GetDefaultTool("kubernetes-scan"))
+	tc := c.GetToolConfig("checkov")
+	assert.Equal("bridgecrew/checkov:2.0.1", tc.Version)
+	assert.Equal([]string{"--quiet"}, tc.ExtraArgs)
+	assert.Equal(map[string]string{"CKV_AWS_21": "high"}, tc.Severities)
+	assert.NotNil(c.GetToolConfig("tfsec"))
+	assert.NotNil(c.GetToolConfig("semgrep"))
+	finding := func(id, path string) *assessments.Finding {
+		return &assessments.Finding{RepoPath: path, Tool: map[string]string{"check_id": id}}
+	}
+	findings := assessments.Findings{
+		finding("CKV_AWS_18", "main.tf"),
+		finding("CKV_K8S_10", "k8s/pod.yaml"),
+		finding("CKV_AWS_19", "test/a/main.tf"),
+		finding("CKV_AWS_19", "main.tf"),
+		finding("CKV_AWS_20", "examples/s3/main.tf"),
+		finding("CKV_AWS_20", "test/main.tf"),
+		finding("CKV_AWS_21", "main.tf"),
+	}
+	assert.Equal(4, tc.applyRuleIgnores(findings))
+	var suppressed []bool
+	for _, f := range findings {
+		suppressed = append(suppressed, f.Suppressed)
+	}
+	assert.Equal([]bool{true, true, true, false, true, false, false}, suppressed)
+	assert.Equal(assessments.SuppressedByConfig, findings[0].SuppressedBy)
+	assert.Equal("test fixtures", findings[2].SuppressionReason)
+	tc.applySeverities(findings)
+	assert.Equal("high", findings[6].Severity)
+	assert.Equal("", findings[5].Severity)
+}
+
+func TestDefaultToolCommand(t *testing.T) {
+	assert := assert.New(t)
+	parent := &testTool{name: "checkov"}
+	c := CreateCommand(parent)
+	c.Use = "terraform-scan"
+	sub := &testTool{name: "tfsec"}
+	c.AddCommand(CreateCommand(sub))
+	other := &testTool{name: "terrascan"}
+	c.AddCommand(CreateCommand(other))
+	assert.NoError(c.ParseFlags([]string{"--config-file", writeTestConfig(t),
+		"--fail", "high", "--fail", "medium=2"}))
+	assert.Equal(c.Commands()[1], findDefaultToolCommand(c, parent))
+	assert.Equal([]string{"high", "medium=2"}, sub.FailThresholds)
+	assert.Nil(findDefaultToolCommand(c.Commands()[1], sub))
+	assert.Nil(findDefaultToolCommand(&cobra.Command{Use: "kubernetes-scan"}, parent))
+}
@@ -0,0 +1,382 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	ignore "github.com/sabhiram/go-gitignore"
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"gopkg.in/yaml.v3"
+)
+
+// A ConfigProblem is a problem found in a config file
+type ConfigProblem struct {
+	Line    int
+	Key     string
+	Message string
+}
+
+type ConfigProblems []*ConfigProblem
+
+func (problems ConfigProblems) ToJNode(path string) *jnode.Node {
+	n := jnode.NewObjectNode()
+	n.Put("path", path)
+	a := n.PutArray("problems")
+	for _, p := range problems {
+		a.AppendObject().
+			Put("line", p.Line).
+			Put("key", p.Key).
+			Put("message", p.Message)
+	}
+	return n
+}
+
+// The expected structure of a part of the config file
+type configSchema struct {
+	kind yaml.Kind
+	// for mappings with fixed keys
+	keys map[string]*configSchema
+	// for sequence elements, or the values of mappings with arbitrary keys
+	values *configSchema
+	// if true a sequence can also be given as a single scalar
+	scalarOK bool
+	// checks the value of a scalar, returning a message if it's invalid
+	check func(value string) string
+}
+
+var (
+	stringSchema        = &configSchema{kind: yaml.ScalarNode}
+	stringsSchema       = &configSchema{kind: yaml.SequenceNode, values: stringSchema}
+	ignorePatternSchema = &configSchema{
+		kind:     yaml.SequenceNode,
+		values:   &configSchema{kind: yaml.ScalarNode, check: checkIgnorePattern},
+		scalarOK: true,
+	}
+	configFileSchema = &configSchema{
+		kind: yaml.MappingNode,
+		keys: map[string]*configSchema{
+			"ignore":        ignorePatternSchema,
+			"default-tools": {kind: yaml.MappingNode, values: stringSchema},
+			"tools": {
+				kind: yaml.MappingNode,
+				values: &configSchema{
+					kind: yaml.MappingNode,
+					keys: map[string]*configSchema{
+						"version":    stringSchema,
+						"extra-args": stringsSchema,
+						"skip":       stringsSchema,
+						"ignore-rules": {
+							kind: yaml.SequenceNode,
+							values: &configSchema{
+								kind: yaml.MappingNode,
+								keys: map[string]*configSchema{
+									"paths":  ignorePatternSchema,
+									"rules":  stringsSchema,
+									"reason": stringSchema,
+								},
+							},
+						},
+						"severities": {
+							kind:   yaml.MappingNode,
+							values: &configSchema{kind: yaml.ScalarNode, check: checkSeverity},
+						},
+					},
+				},
+			},
+			"inventory": {
+				kind: yaml.MappingNode,
+				keys: map[string]*configSchema{
+					"skip-directories": stringsSchema,
+					"max-file-size":    {kind: yaml.ScalarNode, check: checkFileSize},
+				},
+			},
+		},
+	}
+)
+
+var kindNames = map[yaml.Kind]string{
+	yaml.ScalarNode:   "a string",
+	yaml.SequenceNode: "a list",
+	yaml.MappingNode:  "a mapping",
+}
+
+func checkSeverity(value string) string {
+	if assessments.NormalizeSeverity(value) == "" {
+		return fmt.Sprintf("invalid severity %q", value)
+	}
+	return ""
+}
+
+func checkFileSize(value string) string {
+	if n, err := strconv.ParseInt(value, 10, 64); err != nil || n <= 0 {
+		return fmt.Sprintf("invalid file size %q", value)
+	}
+	return ""
+}
+
+// The ignore matcher silently drops patterns it can't compile, so
+// look for the things that it can't handle.
+func checkIgnorePattern(value string) string {
+	p := strings.TrimSpace(value)
+	if p == "" || strings.HasPrefix(p, "#") {
+		return ""
+	}
+	p = strings.TrimPrefix(strings.TrimPrefix(p, "!"), `\`)
+	if _, err := path.Match(p, ""); err != nil {
+		return fmt.Sprintf("invalid ignore pattern %q", value)
+	}
+	depth := 0
+	for _, r := range p {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return fmt.Sprintf("invalid ignore pattern %q - unbalanced parentheses", value)
+			}
+		}
+	}
+	if depth != 0 {
+		return fmt.Sprintf("invalid ignore pattern %q - unbalanced parentheses", value)
+	}
+	if strings.HasPrefix(p, "../") {
+		return fmt.Sprintf("ignore pattern %q can't match files in the repository", value)
+	}
+	return ""
+}
+
+func joinKey(key, name string) string {
+	if key == "" {
+		return name
+	}
+	return key + "." + name
+}
+
+func (s *configSchema) validate(n *yaml.Node, key string, problems ConfigProblems) ConfigProblems {
+	add := func(n *yaml.Node, key, format string, args ...interface{}) {
+		problems = append(problems, &ConfigProblem{
+			Line:    n.Line,
+			Key:     key,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!null" {
+		// empty sections are allowed
+		return problems
+	}
+	if n.Kind == yaml.ScalarNode && s.scalarOK {
+		return s.values.validate(n, key, problems)
+	}
+	if n.Kind != s.kind {
+		add(n, key, "expected %s", kindNames[s.kind])
+		return problems
+	}
+	switch n.Kind {
+	case yaml.ScalarNode:
+		if s.check != nil {
+			if msg := s.check(n.Value); msg != "" {
+				add(n, key, "%s", msg)
+			}
+		}
+	case yaml.SequenceNode:
+		for i, e := range n.Content {
+			problems = s.values.validate(e, fmt.Sprintf("%s[%d]", key, i), problems)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			vs := s.values
+			if s.keys != nil {
+				vs = s.keys[k.Value]
+				if vs == nil {
+					add(k, key, "unknown key %q", k.Value)
+					continue
+				}
+			}
+			problems = vs.validate(v, joinKey(key, k.Value), problems)
+		}
+	}
+	return problems
+}
+
+func parseConfigNode(d []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(d, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		return doc.Content[0], nil
+	}
+	return nil, nil
+}
+
+// Check the config file at path, returning the problems found in it.  An
+// error is returned if the file can't be read.
+func CheckConfigFile(path string) (ConfigProblems, error) {
+	d, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	n, err := parseConfigNode(d)
+	if err != nil {
+		return ConfigProblems{{Message: err.Error()}}, nil
+	}
+	if n == nil {
+		return nil, nil
+	}
+	return configFileSchema.validate(n, "", nil), nil
+}
+
+// An IgnoreMatch is a pattern in the config file that matches a file
+type IgnoreMatch struct {
+	Line    int
+	Key     string
+	Pattern string
+	// "ignore" or "include" for the top-level ignore patterns, or
+	// "suppress" for the ignore-rules of a tool
+	Effect string
+	Rules  string
+}
+
+// The explanation of why a file is or isn't ignored by the config file
+type IgnoreExplanation struct {
+	Path    string
+	Ignored bool
+	Matches []*IgnoreMatch
+}
+
+func (e *IgnoreExplanation) ToJNode() *jnode.Node {
+	n := jnode.NewObjectNode()
+	n.Put("path", e.Path)
+	n.Put("ignored", e.Ignored)
+	a := n.PutArray("matches")
+	for _, m := range e.Matches {
+		a.AppendObject().
+			Put("line", m.Line).
+			Put("key", m.Key).
+			Put("pattern", m.Pattern).
+			Put("effect", m.Effect).
+			Put("rules", m.Rules)
+	}
+	return n
+}
+
+func scalarValues(n *yaml.Node) []*yaml.Node {
+	switch {
+	case n == nil:
+		return nil
+	case n.Kind == yaml.ScalarNode:
+		return []*yaml.Node{n}
+	case n.Kind == yaml.SequenceNode:
+		var values []*yaml.Node
+		for _, e := range n.Content {
+			if e.Kind == yaml.ScalarNode {
+				values = append(values, e)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// Explain which patterns in the config file at configPath match file,
+// which is relative to the repository root.  The top-level ignore
+// patterns are evaluated in order, so the last matching pattern
+// decides if the file is ignored.
+func ExplainIgnored(configPath, file string) (*IgnoreExplanation, error) {
+	file = filepath.ToSlash(file)
+	e := &IgnoreExplanation{Path: file}
+	d, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	root, err := parseConfigNode(d)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range scalarValues(mappingValue(root, "ignore")) {
+		if !ignore.CompileIgnoreLines(strings.TrimPrefix(p.Value, "!")).MatchesPath(file) {
+			continue
+		}
+		m := &IgnoreMatch{Line: p.Line, Key: "ignore", Pattern: p.Value, Effect: "ignore"}
+		if strings.HasPrefix(p.Value, "!") {
+			m.Effect = "include"
+			e.Ignored = false
+		} else {
+			e.Ignored = true
+		}
+		e.Matches = append(e.Matches, m)
+	}
+	tools := mappingValue(root, "tools")
+	if tools != nil && tools.Kind == yaml.MappingNode {
+		var names []string
+		for i := 0; i+1 < len(tools.Content); i += 2 {
+			names = append(names, tools.Content[i].Value)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			ir := mappingValue(mappingValue(tools, name), "ignore-rules")
+			if ir == nil || ir.Kind != yaml.SequenceNode {
+				continue
+			}
+			for i, ri := range ir.Content {
+				rules := "*"
+				if values := scalarValues(mappingValue(ri, "rules")); len(values) > 0 {
+					ids := make([]string, len(values))
+					for j, v := range values {
+						ids[j] = v.Value
+					}
+					rules = strings.Join(ids, ",")
+				}
+				for _, p := range scalarValues(mappingValue(ri, "paths")) {
+					if ignore.CompileIgnoreLines(p.Value).MatchesPath(file) {
+						e.Matches = append(e.Matches, &IgnoreMatch{
+							Line:    p.Line,
+							Key:     fmt.Sprintf("tools.%s.ignore-rules[%d]", name, i),
+							Pattern: p.Value,
+							Effect:  "suppress",
+							Rules:   rules,
+						})
+					}
+				}
+			}
+		}
+	}
+	return e, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckConfigFile(t *testing.T) {
+	assert := assert.New(t)
+	problems, err := CheckConfigFile(writeTestConfig(t))
+	assert.NoError(err)
+	if assert.Len(problems, 1) {
+		assert.Equal(&ConfigProblem{Line: 18, Key: "tools.checkov.severities.CKV_AWS_22",
+			Message: `invalid severity "bogus"`}, problems[0])
+	}
+	path := filepath.Join(t.TempDir(), "config.yml")
+	assert.NoError(os.WriteFile(path, []byte(`ignore: "[abc"
+colour: blue
+tools:
+  checkov:
+    skip: CKV_AWS_20
+    ignore-rules:
+      - paths: [ok/, "bad(/"]
+        rule: [x]
+inventory:
+  skip-directories: [vendor]
+  max-file-size: big
+`), 0600))
+	problems, err = CheckConfigFile(path)
+	assert.NoError(err)
+	assert.Equal(ConfigProblems{
+		{Line: 1, Key: "ignore", Message: `invalid ignore pattern "[abc"`},
+		{Line: 2, Message: `unknown key "colour"`},
+		{Line: 5, Key: "tools.checkov.skip", Message: "expected a list"},
+		{Line: 7, Key: "tools.checkov.ignore-rules[0].paths[1]",
+			Message: `invalid ignore pattern "bad(/" - unbalanced parentheses`},
+		{Line: 8, Key: "tools.checkov.ignore-rules[0]", Message: `unknown key "rule"`},
+		{Line: 11, Key: "inventory.max-file-size", Message: `invalid file size "big"`},
+	}, problems)
+	assert.NoError(os.WriteFile(path, []byte("ignore: [\n"), 0600))
+	problems, err = CheckConfigFile(path)
+	assert.NoError(err)
+	assert.Len(problems, 1)
+	_, err = CheckConfigFile(filepath.Join(t.TempDir(), "missing.yml"))
+	assert.Error(err)
+}
+
+func TestExplainIgnored(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "config.yml")
+	assert.NoError(os.WriteFile(path, []byte(`ignore:
+  - vendor/
+  - "!vendor/keep/"
+  - "*.bak"
+tools:
+  checkov:
+    ignore-rules:
+      - paths: [vendor/keep/**]
+        rules: [CKV_AWS_1, CKV_AWS_2]
+`), 0600))
+	e, err := ExplainIgnored(path, "vendor/x/main.tf")
+	assert.NoError(err)
+	assert.True(e.Ignored)
+	assert.Equal([]*IgnoreMatch{{Line: 2, Key: "ignore", Pattern: "vendor/", Effect: "ignore"}}, e.Matches)
+	e, err = ExplainIgnored(path, "vendor/keep/main.tf")
+	assert.NoError(err)
+	assert.False(e.Ignored)
+	assert.Len(e.Matches, 3)
+	assert.Equal("include", e.Matches[1].Effect)
+	assert.Equal(&IgnoreMatch{Line: 8, Key: "tools.checkov.ignore-rules[0]", Pattern: "vendor/keep/**",
+		Effect: "suppress", Rules: "CKV_AWS_1,CKV_AWS_2"}, e.Matches[2])
+	assert.Equal(ReadConfigFile(path).IsIgnored("vendor/keep/main.tf"), e.Ignored)
+	e, err = ExplainIgnored(path, "main.tf")
+	assert.NoError(err)
+	assert.False(e.Ignored)
+	assert.Empty(e.Matches)
+	assert.Equal(0, e.ToJNode().Path("matches").Size())
+}
@@ -21,7 +21,7 @@ import (
 
 	ignore "github.com/sabhiram/go-gitignore"
 	"github.com/soluble-ai/soluble-cli/pkg/inventory"
-	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/repotree"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/spf13/cobra"
 )
@@ -30,21 +30,93 @@ import (
 type DirectoryBasedToolOpts struct {
 	AssessmentOpts
 	DirectoryOpt
-	Exclude []string
-	ignore  *ignore.GitIgnore
+	Exclude         []string
+	ChangedSince    string
+	ScanChangedOnly bool
+	ignore          *ignore.GitIgnore
 }
 
 func (o *DirectoryBasedToolOpts) GetInventory() *inventory.Manifest {
-	m := inventory.Do(o.GetDirectory())
+	// the manifest is cached, so filter a copy of it
+	cm := *inventory.DoWithOptions(o.GetDirectory(), o.getInventoryOptions())
+	m := &cm
 	m.CloudformationFiles = o.removeExcludedStringSet(m.CloudformationFiles)
+	m.SAMTemplates = o.removeExcludedStringSet(m.SAMTemplates)
+	m.ServerlessProjects = o.removeExcludedStringSet(m.ServerlessProjects)
+	m.PulumiProjects = o.removeExcludedStringSet(m.PulumiProjects)
+	m.AnsiblePlaybooks = o.removeExcludedStringSet(m.AnsiblePlaybooks)
+	m.AnsibleRoles = o.removeExcludedStringSet(m.AnsibleRoles)
 	m.DockerDirectories = o.removeExcludedStringSet(m.DockerDirectories)
+	m.CIConfigFiles = o.removeExcludedStringSet(m.CIConfigFiles)
 	m.HelmCharts = o.removeExcludedStringSet(m.HelmCharts)
 	m.KubernetesManifestDirectories = o.removeExcludedStringSet(m.KubernetesManifestDirectories)
+	m.KustomizeBases = o.removeExcludedStringSet(m.KustomizeBases)
+	m.KustomizeOverlays = o.removeExcludedStringSet(m.KustomizeOverlays)
 	m.TerraformRootModules = o.removeExcludedStringSet(m.TerraformRootModules)
 	m.TerraformModules = o.removeExcludedStringSet(m.TerraformModules)
+	if o.changes != nil && o.ScanChangedOnly {
+		m.CloudformationFiles = o.removeUnchangedStringSet(m.CloudformationFiles)
+		m.SAMTemplates = o.removeUnchangedStringSet(m.SAMTemplates)
+		m.DockerDirectories = o.removeUnchangedStringSet(m.DockerDirectories)
+		m.CIConfigFiles = o.removeUnchangedStringSet(m.CIConfigFiles)
+		m.HelmCharts = o.removeUnchangedStringSet(m.HelmCharts)
+		m.KubernetesManifestDirectories = o.removeUnchangedStringSet(m.KubernetesManifestDirectories)
+		m.KustomizeBases = o.removeUnchangedStringSet(m.KustomizeBases)
+		m.KustomizeOverlays = o.removeUnchangedOverlays(m)
+		m.TerraformRootModules = o.removeUnchangedStringSet(m.TerraformRootModules)
+		m.TerraformModules = o.removeUnchangedStringSet(m.TerraformModules)
+	}
 	return m
 }
 
+// The inventory skips the files that are excluded or ignored by the
+// config file
+func (o *DirectoryBasedToolOpts) getInventoryOptions() *inventory.Options {
+	opts := o.GetInventoryOptions(o.GetDirectory())
+	opts.Ignore = func(path string) bool {
+		return o.IsExcluded(filepath.Join(o.GetDirectory(), path))
+	}
+	opts.IgnoreID = strings.Join(append([]string{o.RepoRoot}, o.Exclude...), "\x00")
+	return opts
+}
+
+// Remove the files or directories that have no changes since --changed-since
+func (o *DirectoryBasedToolOpts) removeUnchangedStringSet(ss util.StringSet) util.StringSet {
+	var r util.StringSet
+	for _, v := range ss.Values() {
+		if o.hasChangesUnder(v) {
+			r.Add(v)
+		}
+	}
+	return r
+}
+
+// Remove the kustomize overlays that have no changes, either in the
+// overlay or in the local resources it uses
+func (o *DirectoryBasedToolOpts) removeUnchangedOverlays(m *inventory.Manifest) util.StringSet {
+	var r util.StringSet
+	for _, overlay := range m.KustomizeOverlays.Values() {
+		if o.hasChangesUnder(overlay) {
+			r.Add(overlay)
+			continue
+		}
+		for _, resource := range m.GetKustomizeResources(overlay) {
+			if o.hasChangesUnder(resource) {
+				r.Add(overlay)
+				break
+			}
+		}
+	}
+	return r
+}
+
+func (o *DirectoryBasedToolOpts) hasChangesUnder(path string) bool {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(o.GetDirectory(), path)
+	}
+	return o.changes.HasChangesUnder(MustRel(o.RepoRoot, path))
+}
+
 func (o *DirectoryBasedToolOpts) GetFilesInDirectory(files []string) ([]string, error) {
 	var result []string
 	for _, f := range files {
@@ -108,6 +180,8 @@ func (o *DirectoryBasedToolOpts) Register(cmd *cobra.Command) {
 	o.DirectoryOpt.Register(cmd)
 	flags := cmd.Flags()
 	flags.StringSliceVar(&o.Exclude, "exclude", nil, "Exclude results from file that match this glob `pattern` (path/**/foo.txt syntax supported.)  May be repeated.")
+	flags.StringVar(&o.ChangedSince, "changed-since", "", "Only report findings on lines that have changed since the git `ref`, e.g. origin/main")
+	flags.BoolVar(&o.ScanChangedOnly, "scan-changed-only", false, "With --changed-since, skip infrastructure-as-code directories that have no changes")
 }
 
 func (o *DirectoryBasedToolOpts) Validate() error {
@@ -117,10 +191,24 @@ func (o *DirectoryBasedToolOpts) Validate() error {
 	if err := o.AssessmentOpts.Validate(); err != nil {
 		return err
 	}
+	if o.ScanChangedOnly && o.ChangedSince == "" {
+		return fmt.Errorf("--scan-changed-only requires --changed-since")
+	}
+	if o.ChangedSince != "" {
+		if o.RepoRoot == "" {
+			return fmt.Errorf("--changed-since can only be used in a git repository")
+		}
+		changes, err := repotree.GetChanges(o.RepoRoot, o.ChangedSince)
+		if err != nil {
+			return err
+		}
+		o.Logger.Infof("{primary:%d} files have changed since {info:%s}", len(changes.Files), o.ChangedSince)
+		o.changes = changes
+	}
 	if len(o.Exclude) > 0 {
 		o.ignore = ignore.CompileIgnoreLines(o.Exclude...)
 		if o.ignore == nil {
-			log.Warnf("Invalid exclude pattern {warning:%s}", strings.Join(o.Exclude, ","))
+			o.Logger.Warnf("Invalid exclude pattern {warning:%s}", strings.Join(o.Exclude, ","))
 		}
 	}
 	return nil
@@ -18,8 +18,13 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/repotree"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -68,3 +73,123 @@ func TestGetInventory(t *testing.T) {
 	m := o.GetInventory()
 	assert.NotNil(m)
 }
+
+func TestGetInventoryOptions(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "config.yml")
+	assert.NoError(os.WriteFile(path, []byte(`ignore: ["*.bak"]
+inventory:
+  skip-directories: [third_party]
+  max-file-size: 1000
+`), 0600))
+	o := &DirectoryBasedToolOpts{
+		Exclude: []string{"testdata/"},
+	}
+	o.ConfigFile = path
+	assert.NoError(o.Validate())
+	opts := o.getInventoryOptions()
+	assert.Equal([]string{"third_party"}, opts.SkipDirectories)
+	assert.Equal(int64(1000), opts.MaxFileSize)
+	assert.True(opts.Ignore(filepath.Join("testdata", "results.json")))
+	assert.True(opts.Ignore("x.bak"))
+	assert.False(opts.Ignore("diropts.go"))
+	o.Exclude = nil
+	assert.NotEqual(opts.IgnoreID, o.getInventoryOptions().IgnoreID)
+}
+
+func TestChangedSince(t *testing.T) {
+	assert := assert.New(t)
+	o := &DirectoryBasedToolOpts{
+		ScanChangedOnly: true,
+	}
+	assert.Error(o.Validate())
+	o.ChangedSince = "HEAD"
+	assert.NoError(o.Validate())
+	assert.NotNil(o.changes)
+	changes, err := repotree.ParseDiff(strings.NewReader("+++ b/pkg/tools/main.tf\n@@ -10 +10,2 @@\n"))
+	assert.NoError(err)
+	o.changes = changes
+	ss := o.removeUnchangedStringSet(*util.NewStringSetWithValues([]string{"main.tf", "checkov", "."}))
+	assert.Equal([]string{"main.tf", "."}, ss.Values())
+	findings := removeUnchangedFindings(assessments.Findings{
+		{FilePath: "main.tf", RepoPath: "pkg/tools/main.tf", Line: 11},
+		{FilePath: "main.tf", RepoPath: "pkg/tools/main.tf", Line: 12},
+		{FilePath: "main.tf", RepoPath: "pkg/tools/main.tf", Line: 5,
+			Tool: map[string]string{"end_line": "15"}},
+		{FilePath: "main.tf", RepoPath: "pkg/tools/main.tf", Line: 1,
+			Tool: map[string]string{"end_line": "9"}},
+		{FilePath: "other.tf", RepoPath: "pkg/tools/other.tf", Line: 1},
+		{Title: "not in a file"},
+	}, changes)
+	if assert.Len(findings, 3) {
+		assert.Equal(11, findings[0].Line)
+		assert.Equal(5, findings[1].Line)
+		assert.Equal("not in a file", findings[2].Title)
+	}
+}
+
+func TestChangedSinceUploadsAllFindings(t *testing.T) {
+	assert := assert.New(t)
+	changes, err := repotree.ParseDiff(strings.NewReader("+++ b/main.tf\n@@ -1 +1 @@\n"))
+	assert.NoError(err)
+	tool := &testTool{name: "test", result: &Result{
+		Directory: t.TempDir(),
+		Data:      jnode.NewObjectNode(),
+		Findings: assessments.Findings{
+			{FilePath: "main.tf", RepoPath: "main.tf", Line: 1},
+			{FilePath: "main.tf", RepoPath: "main.tf", Line: 2},
+		},
+	}}
+	tool.Tool = tool
+	tool.changes = changes
+	r, err := RunSingleAssessment(tool)
+	assert.NoError(err)
+	// only the findings that are printed are limited to the changes
+	assert.Len(r.FileFingerprints, 2)
+	if assert.Len(r.Findings, 1) {
+		assert.Equal(1, r.Findings[0].Line)
+	}
+}
+
+func TestChangedSinceKustomizeOverlays(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := filepath.Abs(filepath.Join("..", "inventory", "testdata", "kustomize"))
+	assert.NoError(err)
+	o := &DirectoryBasedToolOpts{ScanChangedOnly: true}
+	o.Directory = dir
+	o.RepoRoot = dir
+	// the overlay uses the changed base
+	o.changes, err = repotree.ParseDiff(strings.NewReader("+++ b/base/deployment.yaml\n@@ -1 +1 @@\n"))
+	assert.NoError(err)
+	m := o.GetInventory()
+	assert.Equal([]string{"base"}, m.KustomizeBases.Values())
+	assert.Equal([]string{filepath.FromSlash("overlays/prod")}, m.KustomizeOverlays.Values())
+	o.changes, err = repotree.ParseDiff(strings.NewReader("+++ b/standalone/service.yaml\n@@ -1 +1 @@\n"))
+	assert.NoError(err)
+	m = o.GetInventory()
+	assert.Equal([]string{"standalone"}, m.KustomizeBases.Values())
+	assert.Equal(0, m.KustomizeOverlays.Len())
+}
+
+func TestChangedSinceWritesCompleteBaseline(t *testing.T) {
+	assert := assert.New(t)
+	changes, err := repotree.ParseDiff(strings.NewReader("+++ b/main.tf\n@@ -1 +1 @@\n"))
+	assert.NoError(err)
+	tool := &testTool{name: "test", result: &Result{
+		Directory: t.TempDir(),
+		Data:      jnode.NewObjectNode(),
+		Findings: assessments.Findings{
+			{FilePath: "main.tf", RepoPath: "main.tf", Line: 1, PartialFingerprint: "a"},
+			{FilePath: "main.tf", RepoPath: "main.tf", Line: 2, PartialFingerprint: "b"},
+		},
+	}}
+	tool.Tool = tool
+	tool.changes = changes
+	r, err := RunSingleAssessment(tool)
+	assert.NoError(err)
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	assert.NoError(Results{r}.writeBaseline(path))
+	b, err := assessments.ReadBaseline(path)
+	assert.NoError(err)
+	assert.Len(b.Findings, 2)
+}
@@ -28,16 +28,21 @@ import (
 type DockerError string
 
 type DockerTool struct {
-	Name                     string
-	Image                    string
-	DockerArgs               []string
-	Args                     []string
+	Name       string
+	Image      string
+	DockerArgs []string
+	Args       []string
+	// Arguments that must come after the options, such as the files to
+	// scan.  The extra args for the tool from the config file are put
+	// between Args and PositionalArgs.
+	PositionalArgs           []string
 	DefaultNoDockerName      string
 	ExtraMounts              map[string]string
 	Stdout                   io.Writer
 	Stderr                   io.Writer
 	Directory                string
 	Quiet                    bool
+	Logger                   *log.Logger
 	PropagateEnvironmentVars []string
 }
 
@@ -87,20 +92,17 @@ func (t *DockerTool) run(skipPull bool) ([]byte, error) {
 		pull := exec.Command("docker", "pull", t.Image)
 		out, err := pull.Output()
 		if err != nil {
-			os.Stderr.Write(out)
-			log.Warnf("docker pull {primary:%s} failed: {warning:%s}", t.Image, err)
+			_, _ = t.getStderr().Write(out)
+			t.Logger.Warnf("docker pull {primary:%s} failed: {warning:%s}", t.Image, err)
 		}
 	}
 	args := t.getArgs(os.Getenv)
 	run := exec.Command("docker", args...)
 	if !t.Quiet {
-		log.Infof("Running {primary:%s}", strings.Join(run.Args, " "))
+		logCommand(t.Logger, run)
 	}
 	run.Stdin = os.Stdin
-	run.Stderr = os.Stderr
-	if t.Stderr != nil {
-		run.Stderr = t.Stderr
-	}
+	run.Stderr = t.getStderr()
 	if t.Stdout != nil {
 		run.Stdout = t.Stdout
 		return nil, run.Run()
@@ -108,6 +110,13 @@ func (t *DockerTool) run(skipPull bool) ([]byte, error) {
 	return run.Output()
 }
 
+func (t *DockerTool) getStderr() io.Writer {
+	if t.Stderr != nil {
+		return t.Stderr
+	}
+	return os.Stderr
+}
+
 func (t *DockerTool) getArgs(getenv func(string) string) []string {
 	args := []string{"run", "--rm"}
 	if t.Directory != "" {
@@ -94,3 +94,17 @@ func TestDockerGetArgs(t *testing.T) {
 	assert.True(mem)
 	assert.True(dir)
 }
+
+func TestRunDockerExtraArgs(t *testing.T) {
+	assert := assert.New(t)
+	o := &RunOpts{
+		ToolPath:      "echo",
+		ExtraToolArgs: []string{"--extra"},
+	}
+	d, err := o.RunDocker(&DockerTool{
+		Args:           []string{"-f", "json"},
+		PositionalArgs: []string{"a.yaml", "b.yaml"},
+	})
+	assert.NoError(err)
+	assert.Equal("-f json --extra a.yaml b.yaml\n", string(d))
+}
@@ -0,0 +1,283 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/print"
+	"github.com/soluble-ai/soluble-cli/pkg/version"
+)
+
+// See https://docs.gitlab.com/ee/user/application_security/sast/#reports-json-format
+// and https://docs.gitlab.com/ee/ci/testing/code_quality.html#implement-a-custom-tool
+
+const gitlabSASTVersion = "15.0.0"
+
+type gitlabSASTReport struct {
+	Version         string                     `json:"version"`
+	Vulnerabilities []*gitlabSASTVulnerability `json:"vulnerabilities"`
+	Scan            gitlabSASTScan             `json:"scan"`
+}
+
+type gitlabSASTVulnerability struct {
+	ID          string                  `json:"id"`
+	Name        string                  `json:"name,omitempty"`
+	Description string                  `json:"description,omitempty"`
+	Severity    string                  `json:"severity"`
+	Location    gitlabSASTLocation      `json:"location"`
+	Identifiers []*gitlabSASTIdentifier `json:"identifiers"`
+}
+
+type gitlabSASTScanner struct {
+	ID      string            `json:"id"`
+	Name    string            `json:"name"`
+	Version string            `json:"version,omitempty"`
+	Vendor  *gitlabSASTVendor `json:"vendor,omitempty"`
+}
+
+type gitlabSASTVendor struct {
+	Name string `json:"name"`
+}
+
+type gitlabSASTLocation struct {
+	File      string `json:"file,omitempty"`
+	StartLine int    `json:"start_line,omitempty"`
+}
+
+type gitlabSASTIdentifier struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type gitlabSASTScan struct {
+	Analyzer  gitlabSASTScanner `json:"analyzer"`
+	Scanner   gitlabSASTScanner `json:"scanner"`
+	Type      string            `json:"type"`
+	StartTime string            `json:"start_time"`
+	EndTime   string            `json:"end_time"`
+	Status    string            `json:"status"`
+}
+
+type gitlabCodeQualityIssue struct {
+	Description string                    `json:"description"`
+	CheckName   string                    `json:"check_name"`
+	Fingerprint string                    `json:"fingerprint"`
+	Severity    string                    `json:"severity"`
+	Location    gitlabCodeQualityLocation `json:"location"`
+}
+
+type gitlabCodeQualityLocation struct {
+	Path  string                 `json:"path"`
+	Lines gitlabCodeQualityLines `json:"lines"`
+}
+
+type gitlabCodeQualityLines struct {
+	Begin int `json:"begin"`
+}
+
+var gitlabVendor = &gitlabSASTVendor{Name: "Lacework"}
+
+func writeGitlabSAST(w io.Writer, results Results) error {
+	return writeJSONReport(w, results.getGitlabSASTReport(time.Now()))
+}
+
+func writeGitlabCodeQuality(w io.Writer, results Results) error {
+	return writeJSONReport(w, results.getGitlabCodeQualityIssues())
+}
+
+func writeJSONReport(w io.Writer, report interface{}) error {
+	d, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	n, err := jnode.FromJSON(d)
+	if err != nil {
+		return err
+	}
+	p := &print.JSONPrinter{}
+	p.PrintResult(w, n)
+	return nil
+}
+
+// Returns the failed findings that haven't been suppressed.  GitLab
+// reports only contain problems, and have no notion of a suppression.
+func (r *Result) getGitlabFindings() []*assessments.Finding {
+	var findings []*assessments.Finding
+	for _, f := range r.getFindings() {
+		if !f.Pass && !f.Suppressed {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// Build a GitLab SAST report.  GitLab expects a report to come from a
+// single analyzer, so the CLI is the analyzer, and the scanner is the
+// tool if there's only one.  The identifiers of each vulnerability
+// are prefixed with the tool that found it.
+func (results Results) getGitlabSASTReport(now time.Time) *gitlabSASTReport {
+	cli := gitlabSASTScanner{
+		ID:      "soluble",
+		Name:    "soluble",
+		Version: version.Version,
+		Vendor:  gitlabVendor,
+	}
+	ts := now.UTC().Format("2006-01-02T15:04:05")
+	report := &gitlabSASTReport{
+		Version:         gitlabSASTVersion,
+		Vulnerabilities: []*gitlabSASTVulnerability{},
+		Scan: gitlabSASTScan{
+			Analyzer:  cli,
+			Scanner:   cli,
+			Type:      "sast",
+			StartTime: ts,
+			EndTime:   ts,
+			Status:    "success",
+		},
+	}
+	if len(results) == 1 {
+		report.Scan.Scanner = results[0].getGitlabScanner()
+	}
+	for _, result := range results {
+		scanner := result.getGitlabScanner()
+		for _, f := range result.getGitlabFindings() {
+			v := &gitlabSASTVulnerability{
+				ID:          getGitlabUUID(getGitlabFingerprint(scanner.ID, f)),
+				Name:        getFindingMessage(f),
+				Description: f.Description,
+				Severity:    getGitlabSASTSeverity(f.GetSeverity()),
+				Location: gitlabSASTLocation{
+					File:      getFindingURI(f),
+					StartLine: f.Line,
+				},
+				Identifiers: []*gitlabSASTIdentifier{getGitlabIdentifier(scanner.ID, f)},
+			}
+			report.Vulnerabilities = append(report.Vulnerabilities, v)
+		}
+	}
+	return report
+}
+
+// The schema requires at least one identifier for each vulnerability,
+// so findings without a rule id are identified by their title
+func getGitlabIdentifier(scannerID string, f *assessments.Finding) *gitlabSASTIdentifier {
+	prefix := strings.ReplaceAll(scannerID, "-", "_")
+	if id := f.GetRuleID(); id != "" {
+		return &gitlabSASTIdentifier{Type: prefix + "_rule_id", Name: id, Value: id}
+	}
+	title := getFindingMessage(f)
+	return &gitlabSASTIdentifier{Type: prefix + "_title", Name: title, Value: title}
+}
+
+func (r *Result) getGitlabScanner() gitlabSASTScanner {
+	name := r.getToolName()
+	return gitlabSASTScanner{
+		ID:      name,
+		Name:    name,
+		Version: r.getToolVersion(),
+		Vendor:  gitlabVendor,
+	}
+}
+
+func (results Results) getGitlabCodeQualityIssues() []*gitlabCodeQualityIssue {
+	issues := []*gitlabCodeQualityIssue{}
+	for _, result := range results {
+		tool := result.getToolName()
+		for _, f := range result.getGitlabFindings() {
+			checkName := f.GetRuleID()
+			if checkName == "" {
+				checkName = tool
+			}
+			line := f.Line
+			if line <= 0 {
+				// code quality requires a line number
+				line = 1
+			}
+			issues = append(issues, &gitlabCodeQualityIssue{
+				Description: getFindingMessage(f),
+				CheckName:   checkName,
+				Fingerprint: getGitlabFingerprint(tool, f),
+				Severity:    getGitlabCodeQualitySeverity(f.GetSeverity()),
+				Location: gitlabCodeQualityLocation{
+					Path:  getFindingURI(f),
+					Lines: gitlabCodeQualityLines{Begin: line},
+				},
+			})
+		}
+	}
+	return issues
+}
+
+// GitLab tracks findings across pipelines by fingerprint, so the
+// fingerprint should be stable as lines move around.  The partial
+// fingerprint is used for that if we have it.
+func getGitlabFingerprint(tool string, f *assessments.Finding) string {
+	loc := f.PartialFingerprint
+	if loc == "" {
+		loc = fmt.Sprint(f.Line)
+	}
+	h := sha256.Sum256([]byte(strings.Join([]string{
+		tool, f.GetRuleID(), getFindingURI(f), loc, f.GetTitle(),
+	}, "\x00")))
+	return hex.EncodeToString(h[:16])
+}
+
+// Format a hex fingerprint as a UUID
+func getGitlabUUID(fingerprint string) string {
+	return fmt.Sprintf("%s-%s-%s-%s-%s", fingerprint[0:8], fingerprint[8:12],
+		fingerprint[12:16], fingerprint[16:20], fingerprint[20:32])
+}
+
+func getGitlabSASTSeverity(severity string) string {
+	switch assessments.NormalizeSeverity(severity) {
+	case "critical":
+		return "Critical"
+	case "high":
+		return "High"
+	case "medium":
+		return "Medium"
+	case "low":
+		return "Low"
+	case "info":
+		return "Info"
+	default:
+		return "Unknown"
+	}
+}
+
+func getGitlabCodeQualitySeverity(severity string) string {
+	switch assessments.NormalizeSeverity(severity) {
+	case "critical":
+		return "blocker"
+	case "high":
+		return "critical"
+	case "medium":
+		return "major"
+	case "low":
+		return "minor"
+	default:
+		return "info"
+	}
+}
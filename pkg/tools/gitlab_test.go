@@ -0,0 +1,168 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/stretchr/testify/assert"
+)
+
+func getGitlabTestResults() Results {
+	return Results{
+		{
+			Tool:   &testTool{name: "checkov"},
+			Values: map[string]string{"CHECKOV_VERSION": "2.0.1"},
+			Findings: assessments.Findings{
+				{FilePath: "main.tf", RepoPath: "tf/main.tf", Line: 10, Severity: "high",
+					Title: "Ensure bucket is encrypted", PartialFingerprint: "1234:1",
+					Tool: map[string]string{"check_id": "CKV_AWS_19"}},
+				{FilePath: "main.tf", Line: 20, Pass: true,
+					Tool: map[string]string{"check_id": "CKV_AWS_20"}},
+				{FilePath: "other.tf", Line: 5, Suppressed: true,
+					Tool: map[string]string{"check_id": "CKV_AWS_19"}},
+			},
+		},
+		{
+			Tool: &testTool{name: "tfsec"},
+			Findings: assessments.Findings{
+				{FilePath: "main.tf", Description: "Bucket is public",
+					Tool: map[string]string{"rule_id": "AWS001", "severity": "LOW"}},
+			},
+		},
+	}
+}
+
+func TestGitlabSAST(t *testing.T) {
+	assert := assert.New(t)
+	results := getGitlabTestResults()
+	report := results.getGitlabSASTReport(time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC))
+	assert.Equal("2021-10-01T12:00:00", report.Scan.StartTime)
+	assert.Equal("soluble", report.Scan.Scanner.ID)
+	if assert.Len(report.Vulnerabilities, 2) {
+		v := report.Vulnerabilities[0]
+		assert.Len(v.ID, 36)
+		assert.Equal("High", v.Severity)
+		assert.Equal("tf/main.tf", v.Location.File)
+		assert.Equal(10, v.Location.StartLine)
+		assert.Equal("CKV_AWS_19", v.Identifiers[0].Value)
+		assert.Equal("checkov_rule_id", v.Identifiers[0].Type)
+		v = report.Vulnerabilities[1]
+		assert.Equal("Low", v.Severity)
+		assert.Equal("Bucket is public", v.Name)
+		assert.Equal("Bucket is public", v.Description)
+	}
+	// the id is stable across runs
+	assert.Equal(report.Vulnerabilities[0].ID,
+		results.getGitlabSASTReport(time.Now()).Vulnerabilities[0].ID)
+	w := &bytes.Buffer{}
+	assert.NoError(writeGitlabSAST(w, results))
+	n, err := jnode.FromJSON(w.Bytes())
+	assert.NoError(err)
+	assert.Equal(2, n.Path("vulnerabilities").Size())
+	assert.True(n.Path("vulnerabilities").Get(0).Path("category").IsMissing())
+	report = Results{results[0]}.getGitlabSASTReport(time.Now())
+	assert.Equal("checkov", report.Scan.Scanner.ID)
+	assert.Equal("2.0.1", report.Scan.Scanner.Version)
+	report = Results{{
+		Tool:     &testTool{name: "cfn-python-lint"},
+		Findings: assessments.Findings{{FilePath: "template.yaml", Title: "Bad template"}},
+	}}.getGitlabSASTReport(time.Now())
+	if assert.Len(report.Vulnerabilities, 1) && assert.Len(report.Vulnerabilities[0].Identifiers, 1) {
+		id := report.Vulnerabilities[0].Identifiers[0]
+		assert.Equal("cfn_python_lint_title", id.Type)
+		assert.Equal("Bad template", id.Value)
+	}
+}
+
+// The properties that the 15.0.0 SAST report schema allows and
+// requires, by path.  The schema doesn't allow additional properties.
+var gitlabSASTSchemaKeys = map[string][]string{
+	"":                            {"schema", "version", "vulnerabilities", "remediations", "scan"},
+	"vulnerabilities":             {"id", "name", "description", "severity", "solution", "identifiers", "links", "details", "tracking", "flags", "location"},
+	"vulnerabilities.location":    {"file", "start_line", "end_line", "class", "method"},
+	"vulnerabilities.identifiers": {"type", "name", "url", "value"},
+	"scan":                        {"analyzer", "scanner", "start_time", "end_time", "messages", "options", "status", "type", "primary_identifiers"},
+	"scan.analyzer":               {"id", "name", "url", "version", "vendor"},
+	"scan.scanner":                {"id", "name", "url", "version", "vendor"},
+	"scan.analyzer.vendor":        {"name"},
+	"scan.scanner.vendor":         {"name"},
+}
+
+var gitlabSASTSchemaRequired = map[string][]string{
+	"":                            {"version", "vulnerabilities", "scan"},
+	"vulnerabilities":             {"id", "identifiers", "location"},
+	"vulnerabilities.identifiers": {"type", "name", "value"},
+	"scan":                        {"analyzer", "scanner", "start_time", "end_time", "status", "type"},
+}
+
+func checkGitlabSASTSchema(t *testing.T, path string, n *jnode.Node) {
+	t.Helper()
+	if n.IsArray() {
+		for _, e := range n.Elements() {
+			checkGitlabSASTSchema(t, path, e)
+		}
+		return
+	}
+	if !n.IsObject() {
+		return
+	}
+	for _, key := range gitlabSASTSchemaRequired[path] {
+		assert.False(t, n.Path(key).IsMissing(), "%s must have %s", path, key)
+	}
+	allowed := gitlabSASTSchemaKeys[path]
+	for key, v := range n.Entries() {
+		assert.Contains(t, allowed, key, "%s can't have %s", path, key)
+		p := key
+		if path != "" {
+			p = path + "." + key
+		}
+		checkGitlabSASTSchema(t, p, v)
+	}
+}
+
+func TestGitlabSASTSchema(t *testing.T) {
+	w := &bytes.Buffer{}
+	assert.NoError(t, writeGitlabSAST(w, getGitlabTestResults()))
+	n, err := jnode.FromJSON(w.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, "15.0.0", n.Path("version").AsText())
+	checkGitlabSASTSchema(t, "", n)
+}
+
+func TestGitlabCodeQuality(t *testing.T) {
+	assert := assert.New(t)
+	results := getGitlabTestResults()
+	issues := results.getGitlabCodeQualityIssues()
+	if assert.Len(issues, 2) {
+		assert.Equal("CKV_AWS_19", issues[0].CheckName)
+		assert.Equal("critical", issues[0].Severity)
+		assert.Equal("tf/main.tf", issues[0].Location.Path)
+		assert.Equal(10, issues[0].Location.Lines.Begin)
+		assert.Equal("minor", issues[1].Severity)
+		assert.Equal(1, issues[1].Location.Lines.Begin)
+		assert.NotEqual(issues[0].Fingerprint, issues[1].Fingerprint)
+	}
+	w := &bytes.Buffer{}
+	assert.NoError(writeGitlabCodeQuality(w, Results{}))
+	n, err := jnode.FromJSON(w.Bytes())
+	assert.NoError(err)
+	assert.True(n.IsArray())
+	assert.Equal(0, n.Size())
+}
@@ -43,7 +43,9 @@ func (t *Tool) Run() (*tools.Result, error) {
 	if err != nil {
 		return nil, err
 	}
-	args := []string{"-fmt=json", "./..."}
+	args := []string{"-fmt=json"}
+	args = append(args, t.ExtraToolArgs...)
+	args = append(args, "./...")
 	// #nosec G204
 	c := exec.Command(d.GetExePath("gosec"), args...)
 	c.Stderr = os.Stderr
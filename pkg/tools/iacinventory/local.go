@@ -15,7 +15,6 @@
 package iacinventory
 
 import (
-	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/print"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	"github.com/spf13/cobra"
@@ -46,7 +45,7 @@ func (t *Local) CommandTemplate() *cobra.Command {
 }
 
 func (t *Local) Run() (*tools.Result, error) {
-	log.Infof("Finding local infrastructure-as-code inventory under {primary:%s}", t.GetDirectory())
+	t.Logger.Infof("Finding local infrastructure-as-code inventory under {primary:%s}", t.GetDirectory())
 	m := t.GetInventory()
 	n, _ := print.ToResult(m)
 	r := &tools.Result{
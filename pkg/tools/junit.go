@@ -0,0 +1,134 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/junit"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+func writeJUnit(w io.Writer, results Results) error {
+	return results.getJUnitTestSuites().Write(w)
+}
+
+// Write the results as a JUnit XML report to a file
+func (results Results) saveJUnit(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	ts := results.getJUnitTestSuites()
+	if err := ts.Write(f); err != nil {
+		return err
+	}
+	log.Infof("Wrote {primary:%d} test cases to {info:%s}", ts.Tests, path)
+	return f.Close()
+}
+
+// Build a JUnit report from the results.  Each tool is a test suite
+// and each check in a file is a test case, which fails if any finding
+// of that check in that file failed.
+func (results Results) getJUnitTestSuites() *junit.TestSuites {
+	ts := &junit.TestSuites{Name: "soluble"}
+	for _, result := range results {
+		ts.AddSuite(result.getJUnitTestSuite())
+	}
+	return ts
+}
+
+type junitCheckKey struct {
+	id   string
+	path string
+}
+
+func (r *Result) getJUnitTestSuite() *junit.TestSuite {
+	name := r.getToolName()
+	suite := &junit.TestSuite{
+		Name: name,
+	}
+	suite.AddProperty("version", r.getToolVersion())
+	var keys []junitCheckKey
+	checks := map[junitCheckKey][]*assessments.Finding{}
+	for _, f := range r.getFindings() {
+		key := junitCheckKey{id: f.GetRuleID(), path: getFindingURI(f)}
+		if key.id == "" {
+			key.id = getFindingMessage(f)
+		}
+		if _, ok := checks[key]; !ok {
+			keys = append(keys, key)
+		}
+		checks[key] = append(checks[key], f)
+	}
+	for _, key := range keys {
+		suite.AddTestCase(getJUnitTestCase(name, key, checks[key]))
+	}
+	return suite
+}
+
+func getJUnitTestCase(tool string, key junitCheckKey, findings []*assessments.Finding) *junit.TestCase {
+	tc := &junit.TestCase{
+		Name:      key.id,
+		ClassName: tool,
+		File:      key.path,
+	}
+	if key.path != "" {
+		tc.Name = fmt.Sprintf("%s %s", key.id, key.path)
+	}
+	var failed, suppressed []*assessments.Finding
+	for _, f := range findings {
+		switch {
+		case f.Pass:
+		case f.Suppressed:
+			suppressed = append(suppressed, f)
+		default:
+			failed = append(failed, f)
+		}
+	}
+	switch {
+	case len(failed) > 0:
+		f := failed[0]
+		tc.Line = f.Line
+		tc.Failure = &junit.Result{
+			Message: getFindingMessage(f),
+			Type:    f.GetSeverity(),
+			Text:    getJUnitFailureText(failed),
+		}
+	case len(suppressed) > 0:
+		tc.Skipped = &junit.Skipped{Message: suppressed[0].SuppressionReason}
+	}
+	return tc
+}
+
+func getJUnitFailureText(findings []*assessments.Finding) string {
+	b := &strings.Builder{}
+	for _, f := range findings {
+		loc := getFindingURI(f)
+		if f.Line > 0 {
+			loc = fmt.Sprintf("%s:%d", loc, f.Line)
+		}
+		fmt.Fprintf(b, "%s: %s\n", loc, getFindingMessage(f))
+		if f.Description != "" && f.Description != f.GetTitle() {
+			fmt.Fprintf(b, "  %s\n", f.Description)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/junit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJUnit(t *testing.T) {
+	assert := assert.New(t)
+	results := Results{
+		{
+			Tool: &testTool{name: "checkov"},
+			Findings: assessments.Findings{
+				{FilePath: "main.tf", Line: 10, Title: "Ensure bucket is encrypted",
+					Tool: map[string]string{"check_id": "CKV_AWS_19"}},
+				{FilePath: "main.tf", Line: 30, Title: "Ensure bucket is encrypted",
+					Tool: map[string]string{"check_id": "CKV_AWS_19"}},
+				{FilePath: "main.tf", Line: 20, Pass: true,
+					Tool: map[string]string{"check_id": "CKV_AWS_20"}},
+				{FilePath: "other.tf", Line: 5, Suppressed: true, SuppressionReason: "in baseline",
+					Tool: map[string]string{"check_id": "CKV_AWS_19"}},
+			},
+		},
+		{
+			Tool: &testTool{name: "tfsec"},
+		},
+	}
+	ts := results.getJUnitTestSuites()
+	assert.Equal(3, ts.Tests)
+	assert.Equal(1, ts.Failures)
+	assert.Equal(1, ts.Skipped)
+	if assert.Len(ts.Suites, 2) {
+		checkov := ts.Suites[0]
+		assert.Equal("checkov", checkov.Name)
+		if assert.Len(checkov.TestCases, 3) {
+			tc := checkov.TestCases[0]
+			assert.Equal("CKV_AWS_19 main.tf", tc.Name)
+			assert.Equal("checkov", tc.ClassName)
+			assert.Equal(10, tc.Line)
+			if assert.NotNil(tc.Failure) {
+				assert.Equal("Ensure bucket is encrypted", tc.Failure.Message)
+				assert.Contains(tc.Failure.Text, "main.tf:30")
+			}
+			assert.Nil(checkov.TestCases[1].Failure)
+			assert.Nil(checkov.TestCases[1].Skipped)
+			if assert.NotNil(checkov.TestCases[2].Skipped) {
+				assert.Equal("in baseline", checkov.TestCases[2].Skipped.Message)
+			}
+		}
+		assert.Equal(0, ts.Suites[1].Tests)
+	}
+	w := &bytes.Buffer{}
+	assert.NoError(writeJUnit(w, results))
+	var rt junit.TestSuites
+	assert.NoError(xml.Unmarshal(w.Bytes(), &rt))
+	assert.Equal(3, rt.Tests)
+	assert.Equal("CKV_AWS_19 main.tf", rt.Suites[0].TestCases[0].Name)
+}
@@ -0,0 +1,431 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opa
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+	"gopkg.in/yaml.v3"
+)
+
+// A document is the input that policies are evaluated against
+type document struct {
+	// the path of the file relative to the scanned directory
+	path  string
+	line  int
+	value interface{}
+	// the lines that resources start on, keyed by the resource address
+	// i.e. aws_s3_bucket.logs for terraform or the logical id for
+	// cloudformation
+	resourceLines map[string]int
+	// the files that resources are in, if the document was read from
+	// more than one file
+	resourcePaths map[string]string
+}
+
+func (d *document) getLine(resource string) int {
+	if line, ok := d.resourceLines[resource]; ok {
+		return line
+	}
+	return d.line
+}
+
+func (d *document) getPath(resource string) string {
+	if path, ok := d.resourcePaths[resource]; ok {
+		return path
+	}
+	return d.path
+}
+
+var skipDirs = map[string]bool{
+	".terraform":   true,
+	"node_modules": true,
+}
+
+// Read the documents for framework from the files in dir
+func loadDocuments(dir, framework string) ([]*document, error) {
+	var load func(path, rpath string) ([]*document, error)
+	switch framework {
+	case "terraform":
+		load = loadTerraform
+	case "kubernetes":
+		load = loadKubernetes
+	case "cloudformation":
+		load = loadCloudformation
+	default:
+		return nil, fmt.Errorf("opa does not support the %s framework", framework)
+	}
+	var docs []*document
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name := info.Name()
+		if info.IsDir() {
+			if path != dir && (name[0] == '.' || skipDirs[name]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rpath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		d, err := load(path, filepath.ToSlash(rpath))
+		if err != nil {
+			log.Warnf("Could not read {info:%s} - {warning:%s}", rpath, err)
+			return nil
+		}
+		docs = append(docs, d...)
+		return nil
+	})
+	if framework == "terraform" {
+		docs = mergeTerraformModules(docs)
+	}
+	return docs, err
+}
+
+// Terraform files are converted to an object similar to the terraform JSON
+// syntax, i.e. resource.aws_s3_bucket.logs.tags.  Expressions that can't be
+// evaluated statically are represented by their source text in "${...}".
+func loadTerraform(path, rpath string) ([]*document, error) {
+	if !strings.HasSuffix(path, ".tf") {
+		return nil, nil
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	file, diags := hclparse.NewParser().ParseHCL(src, path)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("unexpected body type %T", file.Body)
+	}
+	doc := &document{
+		path:          rpath,
+		resourceLines: map[string]int{},
+	}
+	for _, block := range body.Blocks {
+		address := strings.Join(block.Labels, ".")
+		if block.Type != "resource" {
+			address = fmt.Sprintf("%s.%s", block.Type, address)
+		}
+		doc.resourceLines[address] = block.TypeRange.Start.Line
+	}
+	doc.value = hclBodyValue(body, src)
+	return []*document{doc}, nil
+}
+
+// A terraform module is all of the .tf files in a directory, so the
+// documents of the files in a directory are merged into one document
+// for the module.  The path of the module's document is the path of its
+// first file.
+func mergeTerraformModules(docs []*document) []*document {
+	var modules []*document
+	moduleMap := map[string]*document{}
+	for _, doc := range docs {
+		dir := path.Dir(doc.path)
+		module := moduleMap[dir]
+		if module == nil {
+			module = &document{
+				path:          doc.path,
+				value:         map[string]interface{}{},
+				resourceLines: map[string]int{},
+				resourcePaths: map[string]string{},
+			}
+			moduleMap[dir] = module
+			modules = append(modules, module)
+		}
+		for address, line := range doc.resourceLines {
+			module.resourceLines[address] = line
+			module.resourcePaths[address] = doc.path
+		}
+		mergeValue(module.value.(map[string]interface{}), doc.value.(map[string]interface{}))
+	}
+	return modules
+}
+
+// Merge the values of src into dst, e.g. the resources of one file into
+// the resources of a module.  Other values with the same key are put in
+// an array, as putBlockValue does within a file.
+func mergeValue(dst, src map[string]interface{}) {
+	for key, value := range src {
+		if _, ok := dst[key]; !ok {
+			dst[key] = value
+			continue
+		}
+		em, eok := dst[key].(map[string]interface{})
+		vm, vok := value.(map[string]interface{})
+		switch a, aok := value.([]interface{}); {
+		case eok && vok:
+			mergeValue(em, vm)
+		case aok:
+			for _, v := range a {
+				putBlockValue(dst, []string{key}, v)
+			}
+		default:
+			putBlockValue(dst, []string{key}, value)
+		}
+	}
+}
+
+func hclBodyValue(body *hclsyntax.Body, src []byte) map[string]interface{} {
+	m := map[string]interface{}{}
+	for name, attr := range body.Attributes {
+		m[name] = hclExpressionValue(attr.Expr, src)
+	}
+	for _, block := range body.Blocks {
+		putBlockValue(m, append([]string{block.Type}, block.Labels...), hclBodyValue(block.Body, src))
+	}
+	return m
+}
+
+// Put a block's value at the path of its type and labels.  If there are
+// several blocks with the same path their values are put in an array.
+func putBlockValue(m map[string]interface{}, keys []string, value interface{}) {
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := m[key].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			m[key] = child
+		}
+		m = child
+	}
+	key := keys[len(keys)-1]
+	switch existing := m[key].(type) {
+	case nil:
+		m[key] = value
+	case []interface{}:
+		m[key] = append(existing, value)
+	default:
+		m[key] = []interface{}{existing, value}
+	}
+}
+
+func hclExpressionValue(expr hclsyntax.Expression, src []byte) interface{} {
+	v, diags := expr.Value(nil)
+	if !diags.HasErrors() && v.IsWhollyKnown() {
+		if v.IsNull() {
+			return nil
+		}
+		if d, err := ctyjson.Marshal(v, v.Type()); err == nil {
+			var value interface{}
+			if err := json.Unmarshal(d, &value); err == nil {
+				return value
+			}
+		}
+	}
+	r := expr.Range()
+	text := strings.TrimSpace(string(src[r.Start.Byte:r.End.Byte]))
+	if _, ok := expr.(*hclsyntax.TemplateExpr); ok && len(text) > 1 && text[0] == '"' {
+		// string templates are already in the "${...}" form
+		return text[1 : len(text)-1]
+	}
+	return fmt.Sprintf("${%s}", text)
+}
+
+func isYAMLFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json", ".template":
+		return true
+	default:
+		return false
+	}
+}
+
+func readYAMLDocuments(path string) ([]*yaml.Node, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dec := yaml.NewDecoder(f)
+	var nodes []*yaml.Node
+	for {
+		n := &yaml.Node{}
+		if err := dec.Decode(n); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nodes, nil
+			}
+			return nil, err
+		}
+		if n.Kind == yaml.DocumentNode && len(n.Content) == 1 {
+			n = n.Content[0]
+		}
+		nodes = append(nodes, n)
+	}
+}
+
+// Each kubernetes manifest in a file is a separate document
+func loadKubernetes(path, rpath string) ([]*document, error) {
+	if !isYAMLFile(path) {
+		return nil, nil
+	}
+	nodes, err := readYAMLDocuments(path)
+	if err != nil {
+		return nil, err
+	}
+	var docs []*document
+	for _, n := range nodes {
+		if yamlMappingValue(n, "apiVersion") == nil || yamlMappingValue(n, "kind") == nil {
+			continue
+		}
+		value, err := yamlValue(n)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, &document{
+			path:  rpath,
+			line:  n.Line,
+			value: value,
+		})
+	}
+	return docs, nil
+}
+
+// Cloudformation templates are evaluated as they're written, except
+// that short form intrinsic functions are converted to their long
+// form i.e. !Ref x becomes {"Ref": "x"}
+func loadCloudformation(path, rpath string) ([]*document, error) {
+	if !isYAMLFile(path) {
+		return nil, nil
+	}
+	nodes, err := readYAMLDocuments(path)
+	if err != nil || len(nodes) != 1 {
+		return nil, err
+	}
+	n := nodes[0]
+	resources := yamlMappingValue(n, "Resources")
+	if resources == nil || resources.Kind != yaml.MappingNode ||
+		(yamlMappingValue(n, "AWSTemplateFormatVersion") == nil && !hasCloudformationResource(resources)) {
+		return nil, nil
+	}
+	value, err := yamlValue(n)
+	if err != nil {
+		return nil, err
+	}
+	doc := &document{
+		path:          rpath,
+		line:          n.Line,
+		value:         value,
+		resourceLines: map[string]int{},
+	}
+	for i := 0; i+1 < len(resources.Content); i += 2 {
+		doc.resourceLines[resources.Content[i].Value] = resources.Content[i].Line
+	}
+	return []*document{doc}, nil
+}
+
+func hasCloudformationResource(resources *yaml.Node) bool {
+	for i := 1; i < len(resources.Content); i += 2 {
+		t := yamlMappingValue(resources.Content[i], "Type")
+		if t != nil && strings.HasPrefix(t.Value, "AWS::") {
+			return true
+		}
+	}
+	return false
+}
+
+func yamlMappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func yamlValue(n *yaml.Node) (interface{}, error) {
+	var value interface{}
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil, nil
+		}
+		return yamlValue(n.Content[0])
+	case yaml.AliasNode:
+		return yamlValue(n.Alias)
+	case yaml.MappingNode:
+		m := make(map[string]interface{}, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			v, err := yamlValue(n.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			m[n.Content[i].Value] = v
+		}
+		value = m
+	case yaml.SequenceNode:
+		a := make([]interface{}, 0, len(n.Content))
+		for _, c := range n.Content {
+			v, err := yamlValue(c)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+		}
+		value = a
+	default:
+		s := *n
+		if isLocalTag(s.Tag) {
+			s.Tag = ""
+		}
+		if err := s.Decode(&value); err != nil {
+			return nil, err
+		}
+	}
+	if isLocalTag(n.Tag) {
+		return intrinsicFunctionValue(n.Tag[1:], value), nil
+	}
+	return value, nil
+}
+
+func isLocalTag(tag string) bool {
+	return strings.HasPrefix(tag, "!") && !strings.HasPrefix(tag, "!!")
+}
+
+func intrinsicFunctionValue(name string, value interface{}) interface{} {
+	switch name {
+	case "Ref", "Condition":
+		return map[string]interface{}{name: value}
+	case "GetAtt":
+		if s, ok := value.(string); ok {
+			parts := strings.SplitN(s, ".", 2)
+			a := make([]interface{}, len(parts))
+			for i := range parts {
+				a[i] = parts[i]
+			}
+			value = a
+		}
+	}
+	return map[string]interface{}{fmt.Sprintf("Fn::%s", name): value}
+}
@@ -0,0 +1,332 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opa
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	opaversion "github.com/open-policy-agent/opa/version"
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/spf13/cobra"
+)
+
+// The rules in a policy that produce violations.  Each rule should be a
+// set of messages, or of objects with a msg attribute.  An object can
+// also have a resource attribute with the address of the resource that
+// violates the policy, to report the line of the resource, and a
+// severity attribute.
+var violationRules = []string{"deny", "violation"}
+
+// The frameworks that policies can be written for.  Policy files that are
+// named with a framework prefix, e.g. terraform-my-policy.rego, are only
+// evaluated against that framework.
+var frameworks = []string{"terraform", "kubernetes", "cloudformation"}
+
+type Tool struct {
+	tools.DirectoryBasedToolOpts
+	Framework string
+}
+
+var _ tools.Single = &Tool{}
+
+type policy struct {
+	id    string
+	rule  string
+	query rego.PreparedEvalQuery
+}
+
+type violation struct {
+	msg      string
+	resource string
+	severity string
+}
+
+func (t *Tool) Name() string {
+	return "opa"
+}
+
+func (t *Tool) CommandTemplate() *cobra.Command {
+	return &cobra.Command{
+		Use:   "opa",
+		Short: fmt.Sprintf("Scan %s with custom rego policies", t.Framework),
+		Long: fmt.Sprintf(`Scan %s with custom rego policies, evaluated by an embedded OPA engine.
+
+Policies produce violations with a deny or violation rule.  The input of a
+terraform policy is a module, i.e. all of the .tf files in a directory.`, t.Framework),
+	}
+}
+
+func (t *Tool) Run() (*tools.Result, error) {
+	policiesDir, err := t.GetCustomPoliciesDir()
+	if err != nil {
+		return nil, err
+	}
+	data := jnode.NewObjectNode()
+	results := data.PutArray("results")
+	result := &tools.Result{
+		Directory: t.GetDirectory(),
+		Data:      data,
+	}
+	result.AddValue("OPA_VERSION", opaversion.Version)
+	if policiesDir == "" {
+		log.Warnf("There are no custom rego policies for {info:opa}")
+		return result, nil
+	}
+	ctx := context.Background()
+	policies, err := loadPolicies(ctx, policiesDir, t.Framework)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := loadDocuments(t.GetDirectory(), t.Framework)
+	if err != nil {
+		return nil, err
+	}
+	// a policy passes if it has no violations in any document, so pass
+	// findings are per policy rather than per document
+	failed := map[string]bool{}
+	for _, doc := range docs {
+		for _, p := range policies {
+			violations, err := p.evaluate(ctx, doc)
+			if err != nil {
+				return nil, fmt.Errorf("could not evaluate %s on %s - %w", p.id, doc.path, err)
+			}
+			for _, v := range violations {
+				failed[p.id] = true
+				f := &assessments.Finding{
+					FilePath: doc.getPath(v.resource),
+					Line:     doc.getLine(v.resource),
+					Title:    v.msg,
+					Tool:     map[string]string{"rule_id": p.id},
+				}
+				if v.resource != "" {
+					f.Tool["resource"] = v.resource
+				}
+				if v.severity != "" {
+					f.Tool["severity"] = v.severity
+				}
+				result.Findings = append(result.Findings, f)
+			}
+		}
+	}
+	if len(docs) > 0 {
+		for i, p := range policies {
+			if failed[p.id] || (i > 0 && policies[i-1].id == p.id) {
+				continue
+			}
+			result.Findings = append(result.Findings, &assessments.Finding{
+				Pass:  true,
+				Title: p.id,
+				Tool:  map[string]string{"rule_id": p.id},
+			})
+		}
+	}
+	for _, f := range result.Findings {
+		results.AppendObject().Put("rule_id", f.Tool["rule_id"]).Put("file", f.FilePath).
+			Put("line", f.Line).Put("pass", f.Pass).Put("message", f.Title).
+			Put("resource", f.Tool["resource"])
+	}
+	return result, nil
+}
+
+// Returns true if policies can be written for framework
+func IsSupportedFramework(framework string) bool {
+	for _, fw := range frameworks {
+		if fw == framework {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if the policy file should be evaluated for framework
+func isPolicyFor(name, framework string) bool {
+	for _, fw := range frameworks {
+		if strings.HasPrefix(name, fw+"-") {
+			return fw == framework
+		}
+	}
+	return true
+}
+
+func loadPolicies(ctx context.Context, dir, framework string) ([]*policy, error) {
+	modules := map[string]string{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name := info.Name()
+		if info.IsDir() || filepath.Ext(name) != ".rego" || strings.HasSuffix(name, "_test.rego") ||
+			!isPolicyFor(name, framework) {
+			return nil
+		}
+		d, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		modules[path] = string(d)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(modules) == 0 {
+		return nil, nil
+	}
+	compiler, err := ast.CompileModules(modules)
+	if err != nil {
+		return nil, err
+	}
+	var policies []*policy
+	for _, module := range compiler.Modules {
+		for _, rule := range violationRules {
+			if !hasRule(module, rule) {
+				continue
+			}
+			query := fmt.Sprintf("%s.%s", module.Package.Path, rule)
+			pq, err := rego.New(rego.Query(query), rego.Compiler(compiler)).PrepareForEval(ctx)
+			if err != nil {
+				return nil, err
+			}
+			policies = append(policies, &policy{
+				id:    GetPolicyID(module),
+				rule:  rule,
+				query: pq,
+			})
+		}
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		if policies[i].id == policies[j].id {
+			return policies[i].rule < policies[j].rule
+		}
+		return policies[i].id < policies[j].id
+	})
+	return policies, nil
+}
+
+// Returns true if the module has a rule that produces violations
+func HasViolationRule(module *ast.Module) bool {
+	for _, rule := range violationRules {
+		if hasRule(module, rule) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasRule(module *ast.Module, name string) bool {
+	for _, rule := range module.Rules {
+		if rule.Head.Name.String() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// The package of a policy that's been prepared for upload.  The id of
+// the policy is the last element of the package.
+var PolicyPackage = ast.Ref{ast.DefaultRootDocument, ast.StringTerm("lacework")}
+
+// Returns the id of a policy module, which is the last element of the
+// package path for prepared policies or the package path otherwise
+func GetPolicyID(module *ast.Module) string {
+	path := module.Package.Path
+	if len(path) == len(PolicyPackage)+1 && path.HasPrefix(PolicyPackage) {
+		if s, ok := path[len(path)-1].Value.(ast.String); ok {
+			return string(s)
+		}
+	}
+	return strings.TrimPrefix(path.String(), "data.")
+}
+
+func (p *policy) evaluate(ctx context.Context, doc *document) ([]violation, error) {
+	rs, err := p.query.Eval(ctx, rego.EvalInput(doc.value))
+	if err != nil {
+		return nil, err
+	}
+	var violations []violation
+	for _, r := range rs {
+		for _, e := range r.Expressions {
+			violations = append(violations, getViolations(p.id, e.Value)...)
+		}
+	}
+	return violations, nil
+}
+
+func getViolations(id string, value interface{}) []violation {
+	switch v := value.(type) {
+	case []interface{}:
+		var violations []violation
+		for _, e := range v {
+			violations = append(violations, getViolation(id, e))
+		}
+		return violations
+	case map[string]interface{}:
+		// a partial object rule
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		violations := make([]violation, 0, len(keys))
+		for _, k := range keys {
+			violations = append(violations, getViolation(k, v[k]))
+		}
+		return violations
+	case bool:
+		if v {
+			return []violation{{msg: id}}
+		}
+		return nil
+	case nil:
+		return nil
+	default:
+		return []violation{getViolation(id, v)}
+	}
+}
+
+func getViolation(id string, value interface{}) violation {
+	switch v := value.(type) {
+	case string:
+		return violation{msg: v}
+	case map[string]interface{}:
+		var vi violation
+		for _, key := range []string{"msg", "message"} {
+			if s, ok := v[key].(string); ok {
+				vi.msg = s
+				break
+			}
+		}
+		vi.resource, _ = v["resource"].(string)
+		vi.severity, _ = v["severity"].(string)
+		if vi.msg == "" {
+			vi.msg = id
+		}
+		return vi
+	default:
+		d, _ := json.Marshal(v)
+		return violation{msg: string(d)}
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opa
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadTerraform(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	assert.NoError(os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`
+resource "aws_s3_bucket" "logs" {
+  bucket = "${var.prefix}-logs"
+  acl    = "private"
+  policy = aws_iam_policy.p.json
+  versioning {
+    enabled = true
+  }
+  rule { id = "a" }
+  rule { id = "b" }
+}
+`), 0600))
+	docs, err := loadDocuments(dir, "terraform")
+	assert.NoError(err)
+	if assert.Len(docs, 1) {
+		doc := docs[0]
+		assert.Equal("main.tf", doc.path)
+		assert.Equal(2, doc.getLine("aws_s3_bucket.logs"))
+		bucket := doc.value.(map[string]interface{})["resource"].(map[string]interface{})["aws_s3_bucket"].(map[string]interface{})["logs"].(map[string]interface{})
+		assert.Equal("${var.prefix}-logs", bucket["bucket"])
+		assert.Equal("private", bucket["acl"])
+		assert.Equal("${aws_iam_policy.p.json}", bucket["policy"])
+		assert.Equal(map[string]interface{}{"enabled": true}, bucket["versioning"])
+		assert.Len(bucket["rule"], 2)
+	}
+}
+
+func TestLoadTerraformModules(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	assert.NoError(os.Mkdir(filepath.Join(dir, "network"), 0700))
+	assert.NoError(os.WriteFile(filepath.Join(dir, "logs.tf"), []byte(`
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+}
+`), 0600))
+	assert.NoError(os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`resource "aws_s3_bucket" "data" {
+  bucket = "data"
+}
+resource "aws_s3_bucket_logging" "data" {
+  bucket        = aws_s3_bucket.data.id
+  target_bucket = aws_s3_bucket.logs.id
+}
+`), 0600))
+	assert.NoError(os.WriteFile(filepath.Join(dir, "network", "main.tf"), []byte(`resource "aws_vpc" "main" {
+  cidr_block = "10.0.0.0/16"
+}
+`), 0600))
+	docs, err := loadDocuments(dir, "terraform")
+	assert.NoError(err)
+	if assert.Len(docs, 2) {
+		doc := docs[0]
+		assert.Equal("logs.tf", doc.path)
+		assert.Equal("logs.tf", doc.getPath("aws_s3_bucket.logs"))
+		assert.Equal(2, doc.getLine("aws_s3_bucket.logs"))
+		assert.Equal("main.tf", doc.getPath("aws_s3_bucket_logging.data"))
+		assert.Equal(4, doc.getLine("aws_s3_bucket_logging.data"))
+		buckets := doc.value.(map[string]interface{})["resource"].(map[string]interface{})["aws_s3_bucket"].(map[string]interface{})
+		assert.Len(buckets, 2)
+		assert.Equal("network/main.tf", docs[1].path)
+	}
+}
+
+func TestLoadKubernetes(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	assert.NoError(os.WriteFile(filepath.Join(dir, "app.yaml"), []byte(`apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+not: kubernetes
+`), 0600))
+	docs, err := loadDocuments(dir, "kubernetes")
+	assert.NoError(err)
+	if assert.Len(docs, 2) {
+		assert.Equal(1, docs[0].line)
+		assert.Equal(6, docs[1].line)
+		assert.Equal("Deployment", docs[1].value.(map[string]interface{})["kind"])
+	}
+}
+
+func TestLoadCloudformation(t *testing.T) {
+	assert := assert.New(t)
+	docs, err := loadDocuments("testdata/cloudformation", "cloudformation")
+	assert.NoError(err)
+	if assert.Len(docs, 1) {
+		doc := docs[0]
+		assert.Equal(3, doc.getLine("Logs"))
+		assert.Equal(7, doc.getLine("Data"))
+		props := doc.value.(map[string]interface{})["Resources"].(map[string]interface{})["Data"].(map[string]interface{})["Properties"].(map[string]interface{})
+		assert.Equal(map[string]interface{}{"Ref": "Logs"},
+			props["LoggingConfiguration"].(map[string]interface{})["DestinationBucketName"])
+		tag := props["Tags"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(map[string]interface{}{"Fn::GetAtt": []interface{}{"Logs", "Arn"}}, tag["Value"])
+	}
+}
+
+func TestRun(t *testing.T) {
+	assert := assert.New(t)
+	tool := &Tool{Framework: "cloudformation"}
+	tool.Directory = "testdata/cloudformation"
+	tool.CustomPoliciesDir = "testdata/policies"
+	tool.UploadEnabled = false
+	tool.Quiet = true
+	tool.Tool = tool
+	result, err := tools.RunSingleAssessment(tool)
+	assert.NoError(err)
+	if assert.Len(result.Findings, 2) {
+		f := result.Findings[0]
+		assert.False(f.Pass)
+		assert.Equal("c-opa-bucket-logging", f.GetRuleID())
+		assert.Equal("Logs must have logging enabled", f.Title)
+		assert.Equal("template.yaml", f.FilePath)
+		assert.Equal(3, f.Line)
+		assert.Equal("high", f.Severity)
+		// a policy that passes has one finding for all the documents
+		f = result.Findings[1]
+		assert.True(f.Pass)
+		assert.Equal("c-opa-bucket-versioning", f.GetRuleID())
+		assert.Empty(f.FilePath)
+	}
+	assert.Equal(2, result.Data.Path("results").Size())
+}
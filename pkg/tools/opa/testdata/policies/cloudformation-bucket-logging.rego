@@ -0,0 +1,8 @@
+package lacework["c-opa-bucket-logging"]
+
+deny[{"msg": msg, "resource": name, "severity": "high"}] {
+	bucket := input.Resources[name]
+	bucket.Type == "AWS::S3::Bucket"
+	not bucket.Properties.LoggingConfiguration
+	msg := sprintf("%s must have logging enabled", [name])
+}
@@ -0,0 +1,8 @@
+package lacework["c-opa-bucket-versioning"]
+
+deny[{"msg": msg, "resource": name}] {
+	bucket := input.Resources[name]
+	bucket.Type == "AWS::S3::Bucket"
+	bucket.Properties.VersioningConfiguration.Status == "Suspended"
+	msg := sprintf("%s must not suspend versioning", [name])
+}
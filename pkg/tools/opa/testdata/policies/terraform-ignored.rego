@@ -0,0 +1,5 @@
+package terraform.ignored
+
+deny["always"] {
+	true
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// Serializes writes from all PrefixWriters so that lines from
+// tools running concurrently don't get mixed up
+var prefixWriterLock sync.Mutex
+
+// A PrefixWriter buffers output and writes it a line at a time,
+// with each line prefixed.  It's used to keep the output of tools
+// that run at the same time separate.
+type PrefixWriter struct {
+	w      io.Writer
+	prefix []byte
+	buf    bytes.Buffer
+	lock   sync.Mutex
+}
+
+var _ io.Writer = &PrefixWriter{}
+
+func NewPrefixWriter(w io.Writer, prefix string) *PrefixWriter {
+	return &PrefixWriter{
+		w:      w,
+		prefix: []byte(prefix),
+	}
+}
+
+func (p *PrefixWriter) Write(d []byte) (int, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.buf.Write(d)
+	for {
+		nl := bytes.IndexByte(p.buf.Bytes(), '\n')
+		if nl < 0 {
+			break
+		}
+		if err := p.writeLine(p.buf.Next(nl + 1)); err != nil {
+			return len(d), err
+		}
+	}
+	return len(d), nil
+}
+
+// Write any partial line that's been buffered
+func (p *PrefixWriter) Flush() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.buf.Len() == 0 {
+		return nil
+	}
+	line := append(p.buf.Next(p.buf.Len()), '\n')
+	return p.writeLine(line)
+}
+
+func (p *PrefixWriter) writeLine(line []byte) error {
+	prefixWriterLock.Lock()
+	defer prefixWriterLock.Unlock()
+	if _, err := p.w.Write(p.prefix); err != nil {
+		return err
+	}
+	_, err := p.w.Write(line)
+	return err
+}
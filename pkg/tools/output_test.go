@@ -0,0 +1,37 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	assert := assert.New(t)
+	buf := &bytes.Buffer{}
+	w := NewPrefixWriter(buf, "[x] ")
+	fmt.Fprint(w, "hello ")
+	assert.Equal(0, buf.Len())
+	fmt.Fprint(w, "world\nsecond line\nthird")
+	assert.Equal("[x] hello world\n[x] second line\n", buf.String())
+	assert.NoError(w.Flush())
+	assert.Equal("[x] hello world\n[x] second line\n[x] third\n", buf.String())
+	assert.NoError(w.Flush())
+	assert.Equal("[x] hello world\n[x] second line\n[x] third\n", buf.String())
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+)
+
+// Report formats render all the results of a tool run as a single
+// document instead of printing the findings as rows
+var reportFormats = map[string]func(w io.Writer, results Results) error{
+	"sarif":              writeSARIF,
+	"junit":              writeJUnit,
+	"gitlab-sast":        writeGitlabSAST,
+	"gitlab-codequality": writeGitlabCodeQuality,
+}
+
+func getReportFormatNames() []string {
+	names := make([]string, 0, len(reportFormats))
+	for name := range reportFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Returns the message of a finding for a report, which is its title or
+// rule ID
+func getFindingMessage(f *assessments.Finding) string {
+	if m := f.GetTitle(); m != "" {
+		return m
+	}
+	if id := f.GetRuleID(); id != "" {
+		return id
+	}
+	return "Finding"
+}
+
+// Returns the path of a finding's file for a report, relative to the
+// repository if possible
+func getFindingURI(f *assessments.Finding) string {
+	path := f.RepoPath
+	if path == "" {
+		path = f.FilePath
+	}
+	return filepath.ToSlash(path)
+}
@@ -15,10 +15,8 @@
 package tools
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -28,8 +26,8 @@ import (
 	"github.com/soluble-ai/soluble-cli/pkg/assessments"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/repotree"
+	"github.com/soluble-ai/soluble-cli/pkg/spool"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
-	"github.com/soluble-ai/soluble-cli/pkg/xcp"
 )
 
 type Result struct {
@@ -43,6 +41,10 @@ type Result struct {
 
 	Assessment    *assessments.Assessment
 	AssessmentRaw *jnode.Node
+
+	// the findings before they're limited to the changes since
+	// --changed-since, which are the findings a baseline records
+	allFindings assessments.Findings
 }
 
 type Results []*Result
@@ -83,13 +85,59 @@ func (r *Result) AddUploadOption(options ...api.Option) {
 }
 
 func (r *Result) Upload(client *api.Client, org, name string) error {
-	rr := bytes.NewReader([]byte(r.Data.String()))
-	log.Infof("Uploading results of {primary:%s}", name)
-	options := r.UploadOptions
-	options = append(options,
-		xcp.WithCIEnv(r.Directory),
-		xcp.WithFileFromReader("results_json", "results.json", rr),
-	)
+	logger := r.getLogger()
+	logger.Infof("Uploading results of {primary:%s}", name)
+	u := r.getUpload(org, name)
+	n, err := u.Post(client, r.UploadOptions...)
+	if err != nil {
+		if !api.IsTemporaryError(err) {
+			return err
+		}
+		if len(r.UploadOptions) > 0 {
+			// the options can add files or values that the spool
+			// can't capture, so a saved upload would be incomplete
+			logger.Warnf("The results of {warning:%s} can't be saved to upload later because they have extra upload options", name)
+			return err
+		}
+		// save the upload so it can be sent when the server is reachable
+		// again, instead of losing the results of this run
+		if serr := spool.NewSpool().Save(u); serr != nil {
+			logger.Warnf("Could not save upload for later - {warning:%s}", serr)
+			return err
+		}
+		logger.Warnf("Could not upload results of {warning:%s}, saved as {primary:%s} to upload later with {primary:soluble upload replay}",
+			name, u.ID)
+		return nil
+	}
+	if n.Path("assessment").IsObject() {
+		r.AssessmentRaw = n.Path("assessment")
+		r.Assessment = &assessments.Assessment{}
+		if err := json.Unmarshal([]byte(n.Path("assessment").String()), r.Assessment); err != nil {
+			logger.Warnf("The server returned a garbled assessment: {warning:%s}", err)
+			r.Assessment = nil
+		}
+	}
+	if r.Assessment == nil {
+		logger.Infof("No assessment for {warning:%s} was returned", name)
+	}
+	return nil
+}
+
+// Returns the logger of the tool that generated the result
+func (r *Result) getLogger() *log.Logger {
+	if r.Tool == nil {
+		return nil
+	}
+	return r.Tool.GetAssessmentOptions().Logger
+}
+
+// Returns the complete payload of the upload.  Everything that depends
+// on the environment of this run is captured, so that the upload can be
+// saved and sent later.  The UploadOptions of the result can't be
+// saved, so results with UploadOptions are never spooled.
+func (r *Result) getUpload(org, name string) *spool.Upload {
+	u := spool.NewUpload(org, name, r.Directory, r.Values)
+	u.AddFile("results_json", "results.json", []byte(r.Data.String()))
 	dir, _ := repotree.FindRepoRoot(r.Directory)
 	if dir != "" {
 		// include various repo files if they exist
@@ -101,40 +149,24 @@ func (r *Result) Upload(client *api.Client, org, name string) error {
 				// don't include 0 length files
 				continue
 			}
-			if f, err := os.Open(p); err == nil {
-				defer f.Close()
+			if d, err := os.ReadFile(p); err == nil {
 				name := filepath.Base(path)
 				if names.Add(name) {
 					// only include one
-					options = append(options, xcp.WithFileFromReader(name, name, f))
+					u.AddFile(name, name, d)
 				}
 			}
 		}
 	}
 	if r.Findings != nil {
-		if rf := r.attachFindings(); rf != nil {
-			options = append(options, xcp.WithFileFromReader("findings_json", "findings.json", rf))
-		}
-		if rf := r.attachFingerprints(); rf != nil {
-			options = append(options, xcp.WithFileFromReader("fingerprints_json", "fingerprints.json", rf))
+		if d := r.attachFindings(); d != nil {
+			u.AddFile("findings_json", "findings.json", d)
 		}
-	}
-	n, err := client.XCPPost(org, name, nil, r.Values, options...)
-	if err != nil {
-		return err
-	}
-	if n.Path("assessment").IsObject() {
-		r.AssessmentRaw = n.Path("assessment")
-		r.Assessment = &assessments.Assessment{}
-		if err := json.Unmarshal([]byte(n.Path("assessment").String()), r.Assessment); err != nil {
-			log.Warnf("The server returned a garbled assessment: {warning:%s}", err)
-			r.Assessment = nil
+		if d := r.attachFingerprints(); d != nil {
+			u.AddFile("fingerprints_json", "fingerprints.json", d)
 		}
 	}
-	if r.Assessment == nil {
-		log.Infof("No assessment for {warning:%s} was returned", name)
-	}
-	return nil
+	return u
 }
 
 func (r *Result) UpdateFileFingerprints() {
@@ -170,6 +202,21 @@ func (r *Result) UpdateFileFingerprints() {
 	}
 }
 
+// Remove the findings that aren't on lines that have changed.  Findings
+// that aren't in a file are kept.
+func removeUnchangedFindings(findings assessments.Findings, changes *repotree.Changes) assessments.Findings {
+	var result assessments.Findings
+	for _, f := range findings {
+		switch {
+		case f.FilePath == "":
+			result = append(result, f)
+		case f.RepoPath != "" && changes.IsRangeChanged(f.RepoPath, f.Line, f.GetEndLine()):
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
 func (r *Result) isMultiDocument(path string) bool {
 	if !filepath.IsAbs(path) {
 		path = filepath.Join(r.Directory, path)
@@ -195,31 +242,54 @@ func (r *Result) isMultiDocument(path string) bool {
 	return false
 }
 
-func (r *Result) attachFindings() io.Reader {
+func (r *Result) attachFindings() []byte {
 	fd, err := json.Marshal(r.Findings)
 	if err != nil {
 		log.Warnf("Could not marshal findings: {warning:%s}", err)
 		return nil
 	}
-	return bytes.NewReader(fd)
+	return fd
 }
 
-func (r *Result) attachFingerprints() io.Reader {
+func (r *Result) attachFingerprints() []byte {
 	d, err := json.Marshal(r.FileFingerprints)
 	if err != nil {
 		log.Warnf("Could not marshal fingerprints: {warning:%s}", err)
+		return nil
 	}
-	return bytes.NewReader(d)
+	return d
+}
+
+// Returns the findings from the assessment if the results were
+// uploaded, otherwise the findings generated locally
+func (r *Result) getFindings() assessments.Findings {
+	if r.Assessment != nil {
+		return r.Assessment.Findings
+	}
+	return r.Findings
+}
+
+// Write the failed findings from all of the results to a baseline file
+func (results Results) writeBaseline(path string) error {
+	b := assessments.NewBaseline()
+	for _, result := range results {
+		findings := result.allFindings
+		if findings == nil {
+			findings = result.Findings
+		}
+		b.Add(result.Tool.Name(), findings)
+	}
+	if err := b.Write(path); err != nil {
+		return err
+	}
+	log.Infof("Wrote {primary:%d} findings to baseline {info:%s}", len(b.Findings), path)
+	return nil
 }
 
 func (results Results) getFindingsJNode() (*jnode.Node, error) {
 	var findings []*assessments.Finding
 	for _, result := range results {
-		if result.Assessment != nil {
-			findings = append(findings, result.Assessment.Findings...)
-		} else {
-			findings = append(findings, result.Findings...)
-		}
+		findings = append(findings, result.getFindings()...)
 	}
 	d, err := json.Marshal(findings)
 	if err != nil {
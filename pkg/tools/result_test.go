@@ -20,11 +20,14 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/jarcoal/httpmock"
 	"github.com/soluble-ai/go-jnode"
 	"github.com/soluble-ai/soluble-cli/pkg/api"
+	"github.com/soluble-ai/soluble-cli/pkg/config"
+	"github.com/soluble-ai/soluble-cli/pkg/spool"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/soluble-ai/soluble-cli/pkg/xcp"
 	"github.com/stretchr/testify/assert"
@@ -109,3 +112,40 @@ func TestIsMultiDocument(t *testing.T) {
 	assert.True(r.isMultiDocument("testdata/multi_document2.yaml"))
 	assert.False(r.isMultiDocument("testdata/single_document.yaml"))
 }
+
+func TestUploadSpooled(t *testing.T) {
+	assert := assert.New(t)
+	saveDir := config.ConfigDir
+	config.ConfigDir = t.TempDir()
+	defer func() { config.ConfigDir = saveDir }()
+	result := &Result{
+		Data:      jnode.NewObjectNode(),
+		Directory: t.TempDir(),
+	}
+	opts := &ToolOpts{}
+	opts.APIServer = "https://api.example.com"
+	opts.APIToken = "xxx"
+	opts.Organization = "9999"
+	httpmock.ActivateNonDefault(opts.GetAPIClient().GetClient().GetClient())
+	httpmock.RegisterResponder("POST", "https://api.example.com/api/v1/xcp/test/data",
+		httpmock.NewStringResponder(http.StatusServiceUnavailable, "unavailable"))
+	assert.Nil(result.Upload(opts.GetAPIClient(), "9999", "test"))
+	assert.Nil(result.Assessment)
+	uploads, err := spool.NewSpool().List()
+	assert.NoError(err)
+	if assert.Len(uploads, 1) {
+		assert.Equal("9999", uploads[0].Organization)
+		assert.Equal("results_json", uploads[0].Files[0].Param)
+	}
+	httpmock.RegisterResponder("POST", "https://api.example.com/api/v1/xcp/test/data",
+		httpmock.NewStringResponder(http.StatusForbidden, "forbidden"))
+	assert.Error(result.Upload(opts.GetAPIClient(), "9999", "test"))
+	// results with extra upload options are incomplete without them
+	httpmock.RegisterResponder("POST", "https://api.example.com/api/v1/xcp/test/data",
+		httpmock.NewStringResponder(http.StatusServiceUnavailable, "unavailable"))
+	result.AddUploadOption(xcp.WithFileFromReader("extra", "extra.txt", strings.NewReader("extra")))
+	assert.Error(result.Upload(opts.GetAPIClient(), "9999", "test"))
+	uploads, err = spool.NewSpool().List()
+	assert.NoError(err)
+	assert.Len(uploads, 1)
+}
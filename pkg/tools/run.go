@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
 	"github.com/soluble-ai/soluble-cli/pkg/exit"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/print"
@@ -54,6 +55,9 @@ func processResult(result *Result) error {
 	result.AddValues(result.Tool.GetToolOptions().GetStandardXCPValues())
 	if result.Directory != "" {
 		result.UpdateFileFingerprints()
+		if count := result.Findings.ApplyInlineSuppressions(result.Directory); count > 0 {
+			o.Logger.Infof("{primary:%d} {info:%s} findings are suppressed by comments", count, result.Tool.Name())
+		}
 		if o.RepoRoot != "" {
 			reldir, err := filepath.Rel(o.RepoRoot, result.Directory)
 			if err == nil && !strings.HasPrefix(reldir, "..") {
@@ -61,6 +65,10 @@ func processResult(result *Result) error {
 			}
 		}
 	}
+	toolConfig := o.GetConfig().GetToolConfig(result.Tool.Name())
+	if count := toolConfig.applyRuleIgnores(result.Findings); count > 0 {
+		o.Logger.Infof("{primary:%d} {info:%s} findings are suppressed by the config file", count, result.Tool.Name())
+	}
 	if o.PrintFingerprints || o.SaveFingerprints != "" {
 		d, err := json.Marshal(result.FileFingerprints)
 		util.Must(err)
@@ -68,13 +76,13 @@ func processResult(result *Result) error {
 		util.Must(err)
 		if o.PrintFingerprints {
 			p := &print.JSONPrinter{}
-			p.PrintResult(os.Stderr, n)
+			p.PrintResult(o.GetStderr(), n)
 		}
 		if o.SaveFingerprints != "" {
 			p := &print.JSONPrinter{}
 			f, err := os.Create(o.SaveFingerprints)
 			if err != nil {
-				log.Warnf("Could not save fingerprints: {warning:%s}", err)
+				o.Logger.Warnf("Could not save fingerprints: {warning:%s}", err)
 			} else {
 				p.PrintResult(f, n)
 				_ = f.Close()
@@ -83,7 +91,7 @@ func processResult(result *Result) error {
 	}
 	if o.PrintResultOpt {
 		p := &print.JSONPrinter{}
-		p.PrintResult(os.Stderr, result.Data)
+		p.PrintResult(o.GetStderr(), result.Data)
 	}
 	if o.SaveResult != "" {
 		f, err := os.Create(o.SaveResult)
@@ -95,7 +103,7 @@ func processResult(result *Result) error {
 		_ = f.Close()
 	}
 	if o.PrintResultValues {
-		writeResultValues(os.Stderr, result)
+		writeResultValues(o.GetStderr(), result)
 	}
 	if o.SaveResultValues != "" {
 		f, err := os.Create(o.SaveResultValues)
@@ -109,17 +117,59 @@ func processResult(result *Result) error {
 		if err := result.Upload(o.GetAPIClient(), o.GetOrganization(), o.Tool.Name()); err != nil {
 			return err
 		}
-		if result.Assessment != nil && len(o.parsedFailThresholds) > 0 {
-			result.Assessment.EvaluateFailures(o.parsedFailThresholds)
-			if result.Assessment.Failed {
-				exit.Code = 2
-				a := result.Assessment
-				exit.AddFunc(func() {
-					log.Errorf("{warning:%s} has {danger:%d %s findings}",
-						a.Title, a.FailedCount, a.FailedSeverity)
-				})
+	}
+	if o.changes != nil && result.Directory != "" {
+		// The complete result is uploaded, and only the findings that
+		// are printed and checked against --fail are limited to the
+		// changes
+		count := len(result.Findings)
+		result.allFindings = result.Findings
+		result.Findings = removeUnchangedFindings(result.Findings, o.changes)
+		if count -= len(result.Findings); count > 0 {
+			o.Logger.Infof("Ignoring {primary:%d} {info:%s} findings that have not changed since {info:%s}",
+				count, result.Tool.Name(), o.changes.Ref)
+		}
+	}
+	if result.Assessment != nil && result.Directory != "" {
+		if o.changes != nil {
+			result.Assessment.Findings = removeUnchangedFindings(result.Assessment.Findings, o.changes)
+		}
+		result.Assessment.Findings.ApplyInlineSuppressions(result.Directory)
+	}
+	if result.Assessment == nil {
+		// Without an assessment from the server the findings are
+		// evaluated locally
+		result.Findings.ApplySeverities(result.Tool.Name(), o.GetSeverityCatalog())
+	} else {
+		toolConfig.applyRuleIgnores(result.Assessment.Findings)
+		toolConfig.applySeverities(result.Assessment.Findings)
+	}
+	toolConfig.applySeverities(result.Findings)
+	if o.baseline != nil {
+		name := result.Tool.Name()
+		count := result.Findings.ApplyBaseline(name, o.baseline)
+		if result.Assessment != nil {
+			result.Assessment.Findings.ApplyBaseline(name, o.baseline)
+		}
+		if count > 0 {
+			o.Logger.Infof("{primary:%d} {info:%s} findings are in the baseline", count, name)
+		}
+	}
+	if len(o.parsedFailThresholds) > 0 {
+		a := result.Assessment
+		if a == nil {
+			a = &assessments.Assessment{
+				Title:    result.Tool.Name(),
+				Findings: result.Findings,
 			}
 		}
+		a.EvaluateFailures(o.parsedFailThresholds)
+		if a.Failed {
+			exit.Fail(2, func() {
+				log.Errorf("{warning:%s} has {danger:%d %s findings}",
+					a.Title, a.FailedCount, a.FailedSeverity)
+			})
+		}
 	}
 	return nil
 }
@@ -0,0 +1,115 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/exit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalFailThresholds(t *testing.T) {
+	assert := assert.New(t)
+	defer func() {
+		exit.Code = 0
+		exit.Func = nil
+	}()
+	tool := &testTool{
+		name: "tfsec",
+		result: &Result{
+			Data: jnode.NewObjectNode(),
+			Findings: assessments.Findings{
+				{Tool: map[string]string{"rule_id": "AWS001", "severity": "MEDIUM"}},
+				{Tool: map[string]string{"rule_id": "AWS002", "severity": "LOW"}},
+			},
+		},
+	}
+	tool.Tool = tool
+	tool.FailThresholds = []string{"high"}
+	tool.repoRootSet = true
+	_, err := RunSingleAssessment(tool)
+	assert.NoError(err)
+	assert.Equal(0, exit.Code)
+	assert.Equal("medium", tool.result.Findings[0].Severity)
+	tool.FailThresholds = []string{"medium=1"}
+	_, err = RunSingleAssessment(tool)
+	assert.NoError(err)
+	assert.Equal(2, exit.Code)
+}
+
+func TestBaseline(t *testing.T) {
+	assert := assert.New(t)
+	defer func() {
+		exit.Code = 0
+		exit.Func = nil
+	}()
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	newTool := func() *testTool {
+		tool := &testTool{
+			name: "tfsec",
+			result: &Result{
+				Data: jnode.NewObjectNode(),
+				Findings: assessments.Findings{
+					{RepoPath: "main.tf", Tool: map[string]string{"rule_id": "AWS001", "severity": "HIGH"}},
+				},
+			},
+		}
+		tool.Tool = tool
+		tool.repoRootSet = true
+		return tool
+	}
+	tool := newTool()
+	r, err := RunSingleAssessment(tool)
+	assert.NoError(err)
+	assert.NoError(Results{r}.writeBaseline(path))
+	tool = newTool()
+	tool.BaselineFile = path
+	tool.FailThresholds = []string{"high"}
+	tool.result.Findings = append(tool.result.Findings, &assessments.Finding{
+		RepoPath: "main.tf", Tool: map[string]string{"rule_id": "AWS002", "severity": "LOW"},
+	})
+	_, err = RunSingleAssessment(tool)
+	assert.NoError(err)
+	assert.True(tool.result.Findings[0].Suppressed)
+	assert.False(tool.result.Findings[1].Suppressed)
+	assert.Equal(0, exit.Code)
+}
+
+func TestToolConfigApplied(t *testing.T) {
+	assert := assert.New(t)
+	tool := &testTool{
+		name: "checkov",
+		result: &Result{
+			Data: jnode.NewObjectNode(),
+			Findings: assessments.Findings{
+				{RepoPath: "main.tf", Tool: map[string]string{"check_id": "CKV_AWS_18", "severity": "LOW"}},
+				{RepoPath: "main.tf", Tool: map[string]string{"check_id": "CKV_AWS_21", "severity": "LOW"}},
+			},
+		},
+	}
+	tool.Tool = tool
+	tool.repoRootSet = true
+	tool.ConfigFile = writeTestConfig(t)
+	_, err := RunSingleAssessment(tool)
+	assert.NoError(err)
+	assert.Equal("bridgecrew/checkov:2.0.1", tool.ToolVersion)
+	assert.Equal([]string{"--quiet"}, tool.ExtraToolArgs)
+	assert.True(tool.result.Findings[0].Suppressed)
+	assert.Equal("high", tool.result.Findings[1].Severity)
+}
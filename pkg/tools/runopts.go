@@ -16,6 +16,7 @@ package tools
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -38,6 +39,15 @@ type RunOpts struct {
 	NoDocker        bool
 	Internal        bool
 	Quiet           bool
+	// If set, tools write their output to these instead of stdout
+	// and stderr
+	Stdout io.Writer
+	Stderr io.Writer
+	// If set, log messages about running tools are written to this
+	// instead of the shared log
+	Logger *log.Logger
+	// Extra arguments for the tool from the config file
+	ExtraToolArgs []string
 }
 
 var _ options.Interface = &RunOpts{}
@@ -64,6 +74,11 @@ func (o *RunOpts) Register(cmd *cobra.Command) {
 }
 
 func (o *RunOpts) RunDocker(d *DockerTool) ([]byte, error) {
+	args := make([]string, 0, len(d.Args)+len(o.ExtraToolArgs)+len(d.PositionalArgs))
+	args = append(args, d.Args...)
+	args = append(args, o.ExtraToolArgs...)
+	d.Args = append(args, d.PositionalArgs...)
+	d.PositionalArgs = nil
 	if o.ToolPath != "" || o.NoDocker {
 		path := o.ToolPath
 		if path == "" {
@@ -79,7 +94,7 @@ func (o *RunOpts) RunDocker(d *DockerTool) ([]byte, error) {
 		// #nosec G204
 		c := exec.Command(path, d.Args...)
 		c.Dir = d.Directory
-		c.Stderr = os.Stderr
+		c.Stderr = o.GetStderr()
 		o.LogCommand(c)
 		return c.Output()
 	}
@@ -89,6 +104,10 @@ func (o *RunOpts) RunDocker(d *DockerTool) ([]byte, error) {
 	}
 	d.DockerArgs = append(d.DockerArgs, o.ExtraDockerArgs...)
 	d.Quiet = o.Quiet
+	d.Logger = o.Logger
+	if d.Stderr == nil {
+		d.Stderr = o.Stderr
+	}
 	return d.run(o.SkipDockerPull)
 }
 
@@ -135,13 +154,31 @@ func (o *RunOpts) InstallAPIServerArtifact(name, urlPath string) (*download.Down
 	})
 }
 
+func (o *RunOpts) GetStdout() io.Writer {
+	if o.Stdout != nil {
+		return o.Stdout
+	}
+	return os.Stdout
+}
+
+func (o *RunOpts) GetStderr() io.Writer {
+	if o.Stderr != nil {
+		return o.Stderr
+	}
+	return os.Stderr
+}
+
 func (o *RunOpts) LogCommand(c *exec.Cmd) {
 	if o.Quiet {
 		return
 	}
+	logCommand(o.Logger, c)
+}
+
+func logCommand(logger *log.Logger, c *exec.Cmd) {
 	if c.Dir != "" {
-		log.Infof("Running {primary:%s} {secondary:(in %s)}", strings.Join(c.Args, " "), c.Dir)
+		logger.Infof("Running {primary:%s} {secondary:(in %s)}", strings.Join(c.Args, " "), c.Dir)
 		return
 	}
-	log.Infof("Running {primary:%s}", strings.Join(c.Args, " "))
+	logger.Infof("Running {primary:%s}", strings.Join(c.Args, " "))
 }
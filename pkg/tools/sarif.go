@@ -0,0 +1,243 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/print"
+	"github.com/soluble-ai/soluble-cli/pkg/version"
+)
+
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+type sarifLog struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool      `json:"tool"`
+	Results []*sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string       `json:"name"`
+	Version        string       `json:"version,omitempty"`
+	InformationURI string       `json:"informationUri,omitempty"`
+	Rules          []*sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string                  `json:"id"`
+	Name                 string                  `json:"name,omitempty"`
+	ShortDescription     *sarifMessage           `json:"shortDescription,omitempty"`
+	FullDescription      *sarifMessage           `json:"fullDescription,omitempty"`
+	DefaultConfiguration *sarifRuleConfiguration `json:"defaultConfiguration,omitempty"`
+	Properties           map[string]interface{}  `json:"properties,omitempty"`
+	index                int
+}
+
+type sarifRuleConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string              `json:"ruleId,omitempty"`
+	RuleIndex           *int                `json:"ruleIndex,omitempty"`
+	Level               string              `json:"level"`
+	Message             sarifMessage        `json:"message"`
+	Locations           []*sarifLocation    `json:"locations,omitempty"`
+	PartialFingerprints map[string]string   `json:"partialFingerprints,omitempty"`
+	Suppressions        []*sarifSuppression `json:"suppressions,omitempty"`
+	Properties          map[string]string   `json:"properties,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+func writeSARIF(w io.Writer, results Results) error {
+	n, err := results.getSARIFJNode()
+	if err != nil {
+		return err
+	}
+	p := &print.JSONPrinter{}
+	p.PrintResult(w, n)
+	return nil
+}
+
+// Build a SARIF log from the results, with one run per tool
+func (results Results) getSARIFJNode() (*jnode.Node, error) {
+	sl := &sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    make([]*sarifRun, 0, len(results)),
+	}
+	for _, result := range results {
+		sl.Runs = append(sl.Runs, result.getSARIFRun())
+	}
+	d, err := json.Marshal(sl)
+	if err != nil {
+		return nil, err
+	}
+	return jnode.FromJSON(d)
+}
+
+func (r *Result) getSARIFRun() *sarifRun {
+	run := &sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           r.getToolName(),
+				Version:        r.getToolVersion(),
+				InformationURI: "https://github.com/soluble-ai/soluble-cli",
+				Rules:          []*sarifRule{},
+			},
+		},
+		Results: []*sarifResult{},
+	}
+	rules := map[string]*sarifRule{}
+	for _, f := range r.getFindings() {
+		if f.Pass {
+			// SARIF results are for problems, so passed checks are left out
+			continue
+		}
+		level := getSARIFLevel(f.GetSeverity())
+		sr := &sarifResult{
+			RuleID:  f.GetRuleID(),
+			Level:   level,
+			Message: sarifMessage{Text: getFindingMessage(f)},
+		}
+		if sr.RuleID != "" {
+			rule := rules[sr.RuleID]
+			if rule == nil {
+				rule = &sarifRule{
+					ID:                   sr.RuleID,
+					DefaultConfiguration: &sarifRuleConfiguration{Level: level},
+					index:                len(run.Tool.Driver.Rules),
+				}
+				if title := f.GetTitle(); title != "" {
+					rule.Name = title
+					rule.ShortDescription = &sarifMessage{Text: title}
+				}
+				if f.Description != "" {
+					rule.FullDescription = &sarifMessage{Text: f.Description}
+				}
+				if sev := f.GetSeverity(); sev != "" {
+					rule.Properties = map[string]interface{}{"severity": sev}
+				}
+				rules[sr.RuleID] = rule
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+			}
+			index := rule.index
+			sr.RuleIndex = &index
+		}
+		if uri := getFindingURI(f); uri != "" {
+			loc := &sarifLocation{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: uri},
+				},
+			}
+			if f.Line > 0 {
+				loc.PhysicalLocation.Region = &sarifRegion{StartLine: f.Line}
+			}
+			sr.Locations = []*sarifLocation{loc}
+		}
+		if f.PartialFingerprint != "" {
+			// The partial fingerprint is computed with the same algorithm
+			// that github uses for primaryLocationLineHash
+			sr.PartialFingerprints = map[string]string{
+				"primaryLocationLineHash": f.PartialFingerprint,
+			}
+		}
+		if f.Suppressed {
+			kind := "external"
+			if f.SuppressedBy == assessments.SuppressedByComment {
+				kind = "inSource"
+			}
+			sr.Suppressions = []*sarifSuppression{
+				{Kind: kind, Justification: f.SuppressionReason},
+			}
+		}
+		if f.SID != "" {
+			sr.Properties = map[string]string{"sid": f.SID}
+		}
+		run.Results = append(run.Results, sr)
+	}
+	return run
+}
+
+func (r *Result) getToolName() string {
+	if r.Tool != nil {
+		return r.Tool.Name()
+	}
+	return "soluble"
+}
+
+func (r *Result) getToolVersion() string {
+	name := strings.ToUpper(strings.ReplaceAll(r.getToolName(), "-", "_"))
+	if v := r.Values[fmt.Sprintf("%s_VERSION", name)]; v != "" {
+		return v
+	}
+	return version.Version
+}
+
+func getSARIFLevel(severity string) string {
+	switch strings.ToLower(severity) {
+	case "critical", "high", "error":
+		return "error"
+	case "low", "info", "note", "style":
+		return "note"
+	default:
+		return "warning"
+	}
+}
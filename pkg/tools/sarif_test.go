@@ -0,0 +1,113 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/stretchr/testify/assert"
+)
+
+type testTool struct {
+	AssessmentOpts
+	name   string
+	result *Result
+}
+
+var _ Single = &testTool{}
+
+func (t *testTool) Name() string {
+	return t.name
+}
+
+func (t *testTool) Run() (*Result, error) {
+	return t.result, nil
+}
+
+func TestSARIF(t *testing.T) {
+	assert := assert.New(t)
+	results := Results{
+		{
+			Tool:   &testTool{name: "checkov"},
+			Values: map[string]string{"CHECKOV_VERSION": "2.0.1"},
+			Findings: assessments.Findings{
+				{
+					FilePath:           "main.tf",
+					RepoPath:           "tf/main.tf",
+					Line:               10,
+					Title:              "Ensure bucket is encrypted",
+					PartialFingerprint: "1234:1",
+					Tool:               map[string]string{"check_id": "CKV_AWS_19"},
+				},
+				{
+					FilePath: "main.tf",
+					Line:     20,
+					Pass:     true,
+					Tool:     map[string]string{"check_id": "CKV_AWS_20"},
+				},
+				{
+					FilePath: "other.tf",
+					Line:     5,
+					Title:    "Ensure bucket is encrypted",
+					Tool:     map[string]string{"check_id": "CKV_AWS_19"},
+				},
+			},
+		},
+		{
+			Tool: &testTool{name: "tfsec"},
+			Findings: assessments.Findings{
+				{
+					FilePath:    "main.tf",
+					Line:        3,
+					Description: "Bucket is public",
+					Tool:        map[string]string{"rule_id": "AWS001", "severity": "HIGH"},
+				},
+			},
+		},
+	}
+	n, err := results.getSARIFJNode()
+	assert.NoError(err)
+	assert.Equal("2.1.0", n.Path("version").AsText())
+	runs := n.Path("runs")
+	assert.Equal(2, runs.Size())
+	checkov := runs.Get(0)
+	assert.Equal("checkov", checkov.Path("tool").Path("driver").Path("name").AsText())
+	assert.Equal("2.0.1", checkov.Path("tool").Path("driver").Path("version").AsText())
+	assert.Equal(1, checkov.Path("tool").Path("driver").Path("rules").Size())
+	assert.Equal(2, checkov.Path("results").Size())
+	r := checkov.Path("results").Get(0)
+	assert.Equal("CKV_AWS_19", r.Path("ruleId").AsText())
+	assert.Equal(0, r.Path("ruleIndex").AsInt())
+	assert.Equal("warning", r.Path("level").AsText())
+	loc := r.Path("locations").Get(0).Path("physicalLocation")
+	assert.Equal("tf/main.tf", loc.Path("artifactLocation").Path("uri").AsText())
+	assert.Equal(10, loc.Path("region").Path("startLine").AsInt())
+	assert.Equal("1234:1", r.Path("partialFingerprints").Path("primaryLocationLineHash").AsText())
+	assert.Equal("other.tf", checkov.Path("results").Get(1).Path("locations").Get(0).
+		Path("physicalLocation").Path("artifactLocation").Path("uri").AsText())
+	tfsec := runs.Get(1)
+	r = tfsec.Path("results").Get(0)
+	assert.Equal("AWS001", r.Path("ruleId").AsText())
+	assert.Equal("error", r.Path("level").AsText())
+	assert.Equal("Bucket is public", r.Path("message").Path("text").AsText())
+	w := &bytes.Buffer{}
+	assert.NoError(writeSARIF(w, results))
+	m, err := jnode.FromJSON(w.Bytes())
+	assert.NoError(err)
+	assert.Equal(n, m)
+}
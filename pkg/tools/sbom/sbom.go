@@ -0,0 +1,138 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/soluble-ai/soluble-cli/pkg/api"
+	"github.com/soluble-ai/soluble-cli/pkg/inventory"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/sbom"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/xcp"
+	"github.com/spf13/cobra"
+)
+
+type Tool struct {
+	tools.ToolOpts
+	tools.DirectoryOpt
+	tools.UploadOpt
+	SBOMName   string
+	OutputFile string
+}
+
+var _ tools.Simple = (*Tool)(nil)
+
+func (*Tool) Name() string {
+	return "sbom"
+}
+
+func (*Tool) CommandTemplate() *cobra.Command {
+	return &cobra.Command{
+		Use:   "sbom",
+		Short: "Generate a software bill of materials",
+		Long: `Generate a software bill of materials (SBOM) from the lockfiles in a directory
+
+The SBOM lists each package once, with its package URL, version, and the
+manifest files it was found in.  These files are read:
+
+  go          go.mod
+  python      requirements.txt, Pipfile.lock
+  node        package-lock.json, yarn.lock
+  java        pom.xml (and its modules), gradle.lockfile or build.gradle
+  ruby        Gemfile.lock
+
+The SBOM is written in CycloneDX or SPDX JSON format.`,
+		Example: `# Save a CycloneDX SBOM
+soluble sbom -d . --output-file bom.cdx.json
+# Print an SPDX SBOM and upload it
+soluble sbom --format spdx --upload`,
+	}
+}
+
+func (t *Tool) Register(cmd *cobra.Command) {
+	t.ToolOpts.Register(cmd)
+	t.DirectoryOpt.Register(cmd)
+	t.UploadOpt.Register(cmd)
+	flags := cmd.Flags()
+	flags.StringVar(&t.SBOMName, "name", "", "The `name` of the SBOM.  The default is the name of the directory.")
+	flags.StringVar(&t.OutputFile, "output-file", "", "Write the SBOM to `file` instead of stdout")
+	// --format selects the SBOM format instead of how results are printed
+	formatFlag := flags.Lookup("format")
+	formatFlag.Hidden = false
+	formatFlag.Usage = fmt.Sprintf("Write the SBOM in this `format`, one of: %s",
+		strings.Join(sbom.GetFormatNames(), ", "))
+	formatFlag.DefValue = "cyclonedx"
+	_ = formatFlag.Value.Set("cyclonedx")
+}
+
+func (t *Tool) Validate() error {
+	if err := t.DirectoryOpt.Validate(&t.ToolOpts); err != nil {
+		return err
+	}
+	if err := t.ToolOpts.Validate(); err != nil {
+		return err
+	}
+	if sbom.Formats[t.OutputFormat] == nil {
+		return fmt.Errorf("the SBOM format must be one of: %s", strings.Join(sbom.GetFormatNames(), ", "))
+	}
+	if t.UploadEnabled {
+		if err := t.RequireAPIToken(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Tool) Run() error {
+	dir := t.GetDirectory()
+	m := inventory.DoWithOptions(dir, t.GetInventoryOptions(dir))
+	s := sbom.Generate(dir, m)
+	log.Infof("Found {primary:%d} components in {info:%s}", len(s.Components), dir)
+	name := t.SBOMName
+	if name == "" {
+		name = filepath.Base(dir)
+	}
+	format := sbom.Formats[t.OutputFormat]
+	buf := &bytes.Buffer{}
+	if err := format.Write(s, buf, name); err != nil {
+		return err
+	}
+	if t.OutputFile != "" {
+		if err := os.WriteFile(t.OutputFile, buf.Bytes(), 0600); err != nil {
+			return err
+		}
+		log.Infof("Wrote {info:%s} SBOM to {primary:%s}", t.OutputFormat, t.OutputFile)
+	} else if _, err := t.GetOutputWriter().Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if t.UploadEnabled {
+		values := t.GetStandardXCPValues()
+		values["SBOM_FORMAT"] = t.OutputFormat
+		options := []api.Option{
+			xcp.WithCIEnv(dir),
+			xcp.WithFileFromReader("sbom", format.FileName, bytes.NewReader(buf.Bytes())),
+		}
+		if _, err := t.GetAPIClient().XCPPost(t.GetOrganization(), "sbom", nil, values, options...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/secrets"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+	"github.com/soluble-ai/soluble-cli/pkg/version"
+	"github.com/spf13/cobra"
+)
+
+// Options for scanning the git history for secrets
+type HistoryOpts struct {
+	History bool
+	Since   string
+	Commits string
+}
+
+func (h *HistoryOpts) Register(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.BoolVar(&h.History, "history", false, "Scan the files in every commit of the git history instead of the working tree")
+	flags.StringVar(&h.Since, "since", "", "With --history, only scan the commits since the git `ref`")
+	flags.StringVar(&h.Commits, "commits", "", "With --history, only scan the commits in the `range`, e.g. v1.0..v2.0")
+}
+
+func (h *HistoryOpts) Validate(o *tools.DirectoryBasedToolOpts) error {
+	if !h.History {
+		if h.Since != "" || h.Commits != "" {
+			return fmt.Errorf("--since and --commits can only be used with --history")
+		}
+		return nil
+	}
+	if h.Since != "" && h.Commits != "" {
+		return fmt.Errorf("only one of --since or --commits can be given")
+	}
+	if o.RepoRoot == "" {
+		return fmt.Errorf("--history can only be used in a git repository")
+	}
+	if !util.StringSliceContains(o.Columns, "tool.commit") {
+		o.Columns = append(o.Columns, "tool.commit", "tool.author")
+	}
+	return nil
+}
+
+func (h *HistoryOpts) getRevisions() []string {
+	switch {
+	case h.Commits != "":
+		return []string{h.Commits}
+	case h.Since != "":
+		return []string{h.Since + "..HEAD"}
+	default:
+		return []string{"HEAD"}
+	}
+}
+
+// Scan the git history of the directory with the native detectors.  The
+// result has the layout of the results of detect-secrets, with the
+// commit, author, date and number of occurrences added to each secret.
+func (h *HistoryOpts) scanHistory(o *tools.DirectoryBasedToolOpts, concurrency int) (*tools.Result, error) {
+	dir := o.GetDirectory()
+	scanner := secrets.NewScanner()
+	hs := &secrets.HistoryScan{
+		Scanner:   scanner,
+		Dir:       dir,
+		Revisions: h.getRevisions(),
+		Include: func(path string) bool {
+			return !o.IsExcluded(filepath.Join(dir, path))
+		},
+		Concurrency: concurrency,
+	}
+	found, err := hs.Run()
+	if err != nil {
+		return nil, err
+	}
+	o.Logger.Infof("Scanned {primary:%d} versions of files in {primary:%d} commits for secrets",
+		hs.BlobCount, hs.CommitCount)
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].Path < found[j].Path
+	})
+	n := jnode.NewObjectNode()
+	n.PutObject("history").
+		Put("revisions", hs.Revisions[0]).
+		Put("commits", hs.CommitCount).
+		Put("blobs", hs.BlobCount)
+	plugins := n.PutArray("plugins_used")
+	for _, p := range scanner.GetPluginsUsed() {
+		plugins.Append(jnode.FromMap(p))
+	}
+	results := n.PutObject("results")
+	for _, s := range found {
+		a := results.Path(s.Path)
+		if a.IsMissing() {
+			a = results.PutArray(s.Path)
+		}
+		a.AppendObject().
+			Put("hashed_secret", s.HashedSecret).
+			Put("is_verified", false).
+			Put("line_number", s.Line).
+			Put("type", s.Type).
+			Put("commit", s.Commit).
+			Put("author", s.Author).
+			Put("date", s.Date).
+			Put("occurrences", s.Occurrences)
+	}
+	n.Put("version", version.Version)
+	result := parseResults(o, n)
+	// The files and lines are in old commits, so they can't be
+	// fingerprinted or checked for suppressions in the working tree
+	result.Directory = ""
+	for _, f := range result.Findings {
+		if rel, err := filepath.Rel(o.RepoRoot, filepath.Join(dir, f.FilePath)); err == nil {
+			f.RepoPath = filepath.ToSlash(rel)
+		}
+	}
+	return result, nil
+}
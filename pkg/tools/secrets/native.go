@@ -0,0 +1,231 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	ignore "github.com/sabhiram/go-gitignore"
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/secrets"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/version"
+	"github.com/spf13/cobra"
+)
+
+// NativeTool scans for secrets with the built-in detectors instead of
+// running detect-secrets.  The result has the same layout as the
+// results of detect-secrets.
+type NativeTool struct {
+	tools.DirectoryBasedToolOpts
+	HistoryOpts
+	Concurrency int
+}
+
+var _ tools.Single = &NativeTool{}
+
+func (t *NativeTool) Name() string {
+	return "secrets"
+}
+
+func (t *NativeTool) CommandTemplate() *cobra.Command {
+	return &cobra.Command{
+		Use:   "native",
+		Short: "Scan for secrets with the built-in detectors",
+		Long: `Scan for secrets with the built-in detectors, without docker or detect-secrets.
+
+Files that are ignored by .gitignore, excluded, or binary are not scanned.
+Add a "pragma: allowlist secret" comment to a line to skip it.`,
+	}
+}
+
+func (t *NativeTool) Register(cmd *cobra.Command) {
+	t.DirectoryBasedToolOpts.Register(cmd)
+	t.HistoryOpts.Register(cmd)
+	cmd.Flags().IntVar(&t.Concurrency, "concurrency", runtime.NumCPU(), "Scan up to `n` files at once")
+}
+
+func (t *NativeTool) Validate() error {
+	if err := t.DirectoryBasedToolOpts.Validate(); err != nil {
+		return err
+	}
+	return t.HistoryOpts.Validate(&t.DirectoryBasedToolOpts)
+}
+
+func (t *NativeTool) Run() (*tools.Result, error) {
+	if t.History {
+		return t.scanHistory(&t.DirectoryBasedToolOpts, t.Concurrency)
+	}
+	dir := t.GetDirectory()
+	files, err := t.listFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	scanner := secrets.NewScanner()
+	found := make([][]*secrets.Secret, len(files))
+	concurrency := t.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, file := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, file string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			found[i] = scanFile(t.Logger, scanner, filepath.Join(dir, file))
+		}(i, file)
+	}
+	wg.Wait()
+	n := jnode.NewObjectNode()
+	n.Put("generated_at", time.Now().UTC().Format("2006-01-02T15:04:05Z"))
+	plugins := n.PutArray("plugins_used")
+	for _, p := range scanner.GetPluginsUsed() {
+		plugins.Append(jnode.FromMap(p))
+	}
+	results := n.PutObject("results")
+	for i, file := range files {
+		if len(found[i]) == 0 {
+			continue
+		}
+		a := results.PutArray(filepath.ToSlash(file))
+		for _, s := range found[i] {
+			a.AppendObject().
+				Put("hashed_secret", s.HashedSecret).
+				Put("is_verified", false).
+				Put("line_number", s.Line).
+				Put("type", s.Type)
+		}
+	}
+	n.Put("version", version.Version)
+	t.Logger.Infof("Scanned {primary:%d} files for secrets", len(files))
+	return parseResults(&t.DirectoryBasedToolOpts, n), nil
+}
+
+func scanFile(logger *log.Logger, scanner *secrets.Scanner, path string) []*secrets.Secret {
+	f, err := os.Open(path)
+	if err != nil {
+		logger.Warnf("Could not read {info:%s} - {warning:%s}", path, err)
+		return nil
+	}
+	defer f.Close()
+	r := bufio.NewReaderSize(f, 8000)
+	head, err := r.Peek(8000)
+	if err != nil && err != io.EOF {
+		logger.Warnf("Could not read {info:%s} - {warning:%s}", path, err)
+		return nil
+	}
+	if secrets.IsBinary(head) {
+		return nil
+	}
+	found, err := scanner.Scan(r)
+	if err != nil {
+		logger.Warnf("Could not scan {info:%s} - {warning:%s}", path, err)
+	}
+	return found
+}
+
+// Returns the regular files to scan in dir, relative to dir.  In a git
+// repository the files are the tracked and untracked files that git
+// doesn't ignore.
+func (t *NativeTool) listFiles(dir string) ([]string, error) {
+	var (
+		files []string
+		err   error
+	)
+	if t.RepoRoot != "" {
+		files, err = gitListFiles(dir)
+	} else {
+		files, err = walkFiles(t.Logger, dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		if t.IsExcluded(path) {
+			continue
+		}
+		if info, err := os.Lstat(path); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		result = append(result, file)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func gitListFiles(dir string) ([]string, error) {
+	c := exec.Command("git", "ls-files", "-z", "--cached", "--others", "--exclude-standard")
+	c.Dir = dir
+	c.Stderr = os.Stderr
+	out, err := c.Output()
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	seen := map[string]bool{}
+	for _, file := range bytes.Split(out, []byte{0}) {
+		if len(file) > 0 && !seen[string(file)] {
+			seen[string(file)] = true
+			files = append(files, filepath.FromSlash(string(file)))
+		}
+	}
+	return files, nil
+}
+
+// Walk dir for files, skipping .git and the files that match the
+// .gitignore in dir
+func walkFiles(logger *log.Logger, dir string) ([]string, error) {
+	gitignore, _ := ignore.CompileIgnoreFile(filepath.Join(dir, ".gitignore"))
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			logger.Warnf("Could not scan {info:%s} - {warning:%s}", path, err)
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return nil
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" || (gitignore != nil && gitignore.MatchesPath(rel+"/")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if gitignore == nil || !gitignore.MatchesPath(rel) {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	return files, err
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestNativeScan(t *testing.T) {
+	awsKey := "AKIA" + "IOSFODNN7EXAMPLE"
+	for _, git := range []bool{false, true} {
+		assert := assert.New(t)
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{
+			".gitignore":       "ignored/\n*.log\n",
+			"main.tf":          "# aws\naccess_key = \"" + awsKey + "\"\n",
+			"ignored/key.txt":  awsKey,
+			"app.log":          awsKey,
+			"bin/data":         "\x00\x01" + awsKey,
+			"excluded/key.txt": awsKey,
+			"clean.txt":        "nothing to see here",
+		})
+		if git {
+			c := exec.Command("git", "init", "-q")
+			c.Dir = dir
+			if err := c.Run(); err != nil {
+				t.Skip("git is not available")
+			}
+		}
+		tool := &NativeTool{}
+		tool.Directory = dir
+		tool.Exclude = []string{"excluded/**"}
+		tool.Concurrency = 2
+		tool.Tool = tool
+		assert.NoError(tool.Validate())
+		if git {
+			assert.NotEmpty(tool.RepoRoot)
+		} else {
+			assert.Empty(tool.RepoRoot)
+		}
+		result, err := tool.Run()
+		assert.NoError(err)
+		if assert.Len(result.Findings, 1, "git=%v", git) {
+			f := result.Findings[0]
+			assert.Equal("main.tf", f.FilePath)
+			assert.Equal(2, f.Line)
+			assert.Equal("AWS Access Key", f.Title)
+		}
+		r := result.Data.Path("results").Path("main.tf").Get(0)
+		assert.Equal("AWS Access Key", r.Path("type").AsText())
+		assert.False(r.Path("is_verified").AsBool())
+		assert.Equal(40, len(r.Path("hashed_secret").AsText()))
+		assert.NotZero(result.Data.Path("plugins_used").Size())
+	}
+}
@@ -15,7 +15,7 @@
 package secrets
 
 import (
-	"os"
+	"runtime"
 
 	"github.com/soluble-ai/go-jnode"
 	"github.com/soluble-ai/soluble-cli/pkg/assessments"
@@ -26,6 +26,7 @@ import (
 
 type Tool struct {
 	tools.DirectoryBasedToolOpts
+	HistoryOpts
 
 	args []string
 }
@@ -45,7 +46,23 @@ func (t *Tool) CommandTemplate() *cobra.Command {
 	}
 }
 
+func (t *Tool) Register(cmd *cobra.Command) {
+	t.DirectoryBasedToolOpts.Register(cmd)
+	t.HistoryOpts.Register(cmd)
+}
+
+func (t *Tool) Validate() error {
+	if err := t.DirectoryBasedToolOpts.Validate(); err != nil {
+		return err
+	}
+	return t.HistoryOpts.Validate(&t.DirectoryBasedToolOpts)
+}
+
 func (t *Tool) Run() (*tools.Result, error) {
+	if t.History {
+		// detect-secrets can't scan history, so use the native detectors
+		return t.scanHistory(&t.DirectoryBasedToolOpts, runtime.NumCPU())
+	}
 	// --all-files includes files not checked into git
 	// --no-verify avoids making network calls to check credentials
 	dt := &tools.DockerTool{
@@ -75,7 +92,7 @@ func (t *Tool) Run() (*tools.Result, error) {
 	results, err := jnode.FromJSON(d)
 	if err != nil {
 		if d != nil {
-			os.Stderr.Write(d)
+			_, _ = t.GetStderr().Write(d)
 		}
 		return nil, err
 	}
@@ -85,32 +102,44 @@ func (t *Tool) Run() (*tools.Result, error) {
 	return result, nil
 }
 
-func (t *Tool) excludeResults(results *jnode.Node) {
+func (t *Tool) parseResults(results *jnode.Node) *tools.Result {
+	return parseResults(&t.DirectoryBasedToolOpts, results)
+}
+
+func excludeResults(o *tools.DirectoryBasedToolOpts, results *jnode.Node) {
 	rs := results.Path("results")
 	if rs.Size() > 0 {
 		results.Put("results", util.RemoveJNodeEntriesIf(rs, func(k string, v *jnode.Node) bool {
-			return t.IsExcluded(k)
+			return o.IsExcluded(k)
 		}))
 	}
 }
 
-func (t *Tool) parseResults(results *jnode.Node) *tools.Result {
-	t.excludeResults(results)
+// Convert the results of detect-secrets to a result
+func parseResults(o *tools.DirectoryBasedToolOpts, results *jnode.Node) *tools.Result {
+	excludeResults(o, results)
 	findings := []*assessments.Finding{}
 	for k, v := range results.Path("results").Entries() {
 		if v.IsArray() {
 			for _, p := range v.Elements() {
 				p.Put("file_name", k)
-				findings = append(findings, &assessments.Finding{
+				f := &assessments.Finding{
 					FilePath: k,
 					Line:     p.Path("line_number").AsInt(),
 					Title:    p.Path("type").AsText(),
-				})
+				}
+				// secrets found in the git history have these too
+				for _, name := range []string{"commit", "author", "date"} {
+					if v := p.Path(name); !v.IsMissing() {
+						f.SetAttribute(name, v.AsText())
+					}
+				}
+				findings = append(findings, f)
 			}
 		}
 	}
 	result := &tools.Result{
-		Directory: t.GetDirectory(),
+		Directory: o.GetDirectory(),
 		Data:      results,
 		Findings:  findings,
 	}
@@ -89,7 +89,7 @@ func (t *Tool) Run() (*tools.Result, error) {
 		dt.Mount(customPoliciesDir, "/policy")
 	}
 	dt.AppendArgs(t.extraArgs...)
-	dt.AppendArgs(".")
+	dt.PositionalArgs = []string{"."}
 	d, err := t.RunDocker(dt)
 	if err != nil && (tools.IsDockerError(err) || util.ExitCode(err) != 1) {
 		// semgrep exits 1 if it finds issues
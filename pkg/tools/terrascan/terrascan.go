@@ -60,6 +60,7 @@ func (t *Tool) Run() (*tools.Result, error) {
 		}
 		args = append(args, "-t", t.PolicyType)
 	}
+	args = append(args, t.ExtraToolArgs...)
 	d, err := t.InstallTool(&download.Spec{
 		URL: "github.com/accurics/terrascan",
 	})
@@ -55,6 +55,7 @@ func (t *PlanTool) Run() (*tools.Result, error) {
 		args = append(args, "--save-tfplan", t.TerraformPlan)
 	}
 	args = append(args, t.extraArgs...)
+	args = append(args, t.ExtraToolArgs...)
 	// #nosec G204
 	c := exec.Command(d.GetExePath("tfscore"), args...)
 	c.Stderr = os.Stderr
@@ -75,6 +75,7 @@ func (t *Tool) Run() (*tools.Result, error) {
 		}
 	}
 	args = append(args, t.extraArgs...)
+	args = append(args, t.ExtraToolArgs...)
 	// #nosec G204
 	c := exec.Command(d.GetExePath("tfscore"), args...)
 	c.Stderr = os.Stderr
@@ -7,7 +7,6 @@ import (
 	"strings"
 
 	"github.com/soluble-ai/soluble-cli/pkg/download"
-	"github.com/soluble-ai/soluble-cli/pkg/inventory"
 	"github.com/soluble-ai/soluble-cli/pkg/inventory/terraformsettings"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
@@ -55,7 +54,7 @@ type terraformInit struct {
 
 func (t *Tool) runTerraformInit() (*terraformInit, error) {
 	tfi := &terraformInit{}
-	inv := inventory.Do(t.GetDirectory())
+	inv := t.GetInventory()
 	for _, rootModule := range inv.TerraformRootModules.Values() {
 		dir := filepath.Join(t.GetDirectory(), rootModule)
 		var terraformArgs []string
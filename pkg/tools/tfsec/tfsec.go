@@ -76,6 +76,7 @@ func (t *Tool) Run() (*tools.Result, error) {
 	args = t.addTfVarsFileArg(args, "terraform.tfvars")
 	args = t.addTfVarsFileArg(args, "terraform.tfvars.json")
 	args = t.addAutoTfVarsFiles(args)
+	args = append(args, t.ExtraToolArgs...)
 	args = append(args, ".")
 	// #nosec G204
 	c := exec.Command(d.GetExePath("tfsec-tfsec"), args...)
@@ -167,6 +168,7 @@ func (t *Tool) parseResults(n *jnode.Node) *tools.Result {
 				Tool: map[string]string{
 					"severity": r.Path("severity").AsText(),
 					"rule_id":  r.Path("rule_id").AsText(),
+					"end_line": r.Path("location").Path("end_line").AsText(),
 				},
 			})
 		}
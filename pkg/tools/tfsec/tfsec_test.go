@@ -36,8 +36,10 @@ func TestParseResults(t *testing.T) {
 	tool.RepoRoot = "/x/work/solublegoat"
 	result := tool.parseResults(results)
 	assert.Equal(9, len(result.Findings))
+	assert.Equal(65, result.Findings[6].GetEndLine())
 	f := result.Findings[8]
 	assert.Equal(16, f.Line)
+	assert.Equal(16, f.GetEndLine())
 	assert.Equal("variables.tf", f.FilePath)
 	// verify filepath was rewritten within results.Data
 	assert.Equal("variables.tf", result.Data.Path("results").Get(8).Path("location").Path("filename").AsText())
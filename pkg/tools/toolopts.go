@@ -19,10 +19,10 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/soluble-ai/soluble-cli/pkg/inventory"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/options"
 	"github.com/soluble-ai/soluble-cli/pkg/repotree"
-	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/soluble-ai/soluble-cli/pkg/version"
 	"github.com/spf13/cobra"
 )
@@ -52,15 +52,12 @@ func (o *ToolOpts) getConfig(repoRoot string) *Config {
 		if o.ConfigFile != "" {
 			o.config = ReadConfigFile(o.ConfigFile)
 		} else {
-			oldConfig := filepath.Join(repoRoot, ".soluble", "config.yml")
-			newConfig := filepath.Join(repoRoot, ".lacework", "config.yml")
-			if util.FileExists(oldConfig) && !util.FileExists(newConfig) {
+			path, deprecated := FindConfigFile(repoRoot)
+			if deprecated {
 				log.Warnf("{info:%s} is {warning:deprecated}.  Use {info:%s} instead.",
-					oldConfig, newConfig)
-				o.config = ReadConfigFile(oldConfig)
-			} else {
-				o.config = ReadConfigFile(newConfig)
+					path, filepath.Join(repoRoot, ".lacework", "config.yml"))
 			}
+			o.config = ReadConfigFile(path)
 		}
 	}
 	return o.config
@@ -81,9 +78,35 @@ func (o *ToolOpts) Validate() error {
 		}
 		o.RepoRoot = r
 	}
+	if o.Tool != nil {
+		toolConfig := o.GetConfig().GetToolConfig(o.Tool.Name())
+		if o.ToolVersion == "" {
+			o.ToolVersion = toolConfig.Version
+		}
+		o.ExtraToolArgs = toolConfig.ExtraArgs
+	}
 	return nil
 }
 
+// Returns the options for an inventory of dir, which skips the files
+// that are ignored by the config file
+func (o *ToolOpts) GetInventoryOptions(dir string) *inventory.Options {
+	opts := &inventory.Options{}
+	if o.RepoRoot != "" {
+		config := o.GetConfig()
+		opts.Ignore = func(path string) bool {
+			return config.IsIgnored(MustRel(o.RepoRoot, filepath.Join(dir, path)))
+		}
+		// the config is loaded from the repo root
+		opts.IgnoreID = o.RepoRoot
+		if ic := config.Inventory; ic != nil {
+			opts.SkipDirectories = ic.SkipDirectories
+			opts.MaxFileSize = ic.MaxFileSize
+		}
+	}
+	return opts
+}
+
 func (o *ToolOpts) GetStandardXCPValues() map[string]string {
 	return map[string]string{
 		"CLI_VERSION":          version.Version,
@@ -77,6 +77,7 @@ func (t *Tool) Run() (*tools.Result, error) {
 
 	// Generate params for the scanner
 	args := []string{"image", "--format", "json", "--output", outfile}
+	args = append(args, t.ExtraToolArgs...)
 	// specify the image to scan at the end of params
 	args = append(args, t.Image)
 
@@ -123,8 +124,8 @@ func getData(ver string, n *jnode.Node) *jnode.Node {
 func (t *Tool) runCommand(program string, args ...string) error {
 	scan := exec.Command(program, args...)
 	t.LogCommand(scan)
-	scan.Stderr = os.Stderr
-	scan.Stdout = os.Stdout
+	scan.Stderr = t.GetStderr()
+	scan.Stdout = t.GetStdout()
 	err := scan.Run()
 	if err != nil {
 		return err
@@ -64,7 +64,9 @@ func (t *Tool) Run() (*tools.Result, error) {
 	}
 	defer os.Remove(outfile)
 	program := d.GetExePath("trivy")
-	args := []string{"fs", "--format", "json", "--output", outfile, t.GetDirectory()}
+	args := []string{"fs", "--format", "json", "--output", outfile}
+	args = append(args, t.ExtraToolArgs...)
+	args = append(args, t.GetDirectory())
 	c := exec.Command(program, args...)
 	c.Stderr = os.Stderr
 	c.Stdout = os.Stderr